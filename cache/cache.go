@@ -8,17 +8,77 @@ import (
 )
 
 type (
+	// Pipe queues commands and sends them together on Exec, each command returns a result that is set
+	// by Exec. Values are encoded by the codec of the cache.
 	Pipe interface {
-		Set(key string, value interface{}) error
-		SetWithExpiration(key string, value interface{}, expired time.Duration) error
-		Get(key string, object interface{}) error
+		Set(key string, value interface{}) *StatusResult
+		SetWithExpiration(key string, value interface{}, expired time.Duration) *StatusResult
+		Get(key string) *ValueResult
+		GetSet(key string, value interface{}) *ValueResult
+		MGet(keys ...string) *ValuesResult
+		Remove(key string) *IntResult
+
+		Incr(key string) *IntResult
+		Decr(key string) *IntResult
+		IncrBy(key string, value int64) *IntResult
+		SetNX(key string, value interface{}, ttl time.Duration) *BoolResult
+		Expire(key string, ttl time.Duration) *BoolResult
+		TTL(key string) *DurationResult
+		Exists(keys ...string) *IntResult
+
+		HSet(key, field string, value interface{}) *StatusResult
+		HMSet(key string, value map[string]interface{}) *StatusResult
+		HGet(key, field string) *ValueResult
+		HMGet(key string, fields ...string) *ValuesResult
+		HGetAll(key string) *StringMapResult
+
+		LPush(key string, values ...interface{}) *IntResult
+		RPush(key string, values ...interface{}) *IntResult
+		LPop(key string) *ValueResult
+		RPop(key string) *ValueResult
+		LRange(key string, start, stop int64) *ValuesResult
+
+		SAdd(key string, members ...interface{}) *IntResult
+		SRem(key string, members ...interface{}) *IntResult
+		SMembers(key string) *ValuesResult
+		SIsMember(key string, member interface{}) *BoolResult
+
+		ZAdd(key string, members ...redis.Z) *IntResult
+		ZRem(key string, members ...interface{}) *IntResult
+		ZIncrBy(key string, increment float64, member string) *FloatResult
+		ZRangeByScore(key string, by redis.ZRangeBy) *ZResult
+		ZRevRange(key string, start, stop int64) *ZResult
+
+		// Exec sends the queued commands and sets their results, it returns the first error but the
+		// misses of missing keys, which are only returned by their results.
 		Exec() error
 	}
 
+	// Tx is an optimistic transaction started by Watch. Its reads see the watched keys, the writes
+	// queued on its Pipeline are applied atomically and only if no watched key changed since Watch,
+	// otherwise Exec returns ErrTxFailed.
+	Tx interface {
+		Get(key string, object interface{}) error
+		HGet(key, field string, object interface{}) error
+		HGetAll(key string) (map[string]string, error)
+		Exists(keys ...string) (int64, error)
+		TTL(key string) (time.Duration, error)
+		Pipeline() Pipe
+	}
+
+	// PubSub is a subscription to channels and patterns, messages of every one of them are received
+	// on Channel. The redis caches subscribe again after a connection loss, see Handle for a handler
+	// callback instead of Channel.
 	PubSub interface {
 		Receive() error
+		// Publish sends message to the first channel subscribed.
 		Publish(message string) error
 		Channel() <-chan *redis.Message
+		Subscribe(channels ...string) error
+		PSubscribe(patterns ...string) error
+		// Unsubscribe and PUnsubscribe without arguments unsubscribe from every channel or pattern.
+		Unsubscribe(channels ...string) error
+		PUnsubscribe(patterns ...string) error
 		Close() error
 	}
 
@@ -28,6 +88,20 @@ type (
 		Set(string, interface{}) error
 		Get(string, interface{}) error
 
+		Incr(key string) (int64, error)
+		Decr(key string) (int64, error)
+		IncrBy(key string, value int64) (int64, error)
+		// SetNX sets key only when it doesn't exist, it reports whether it was set. A ttl of 0 never expires.
+		SetNX(key string, value interface{}, ttl time.Duration) (bool, error)
+		// Expire sets the ttl of key, a ttl that isn't positive removes it. It reports whether key exists.
+		Expire(key string, ttl time.Duration) (bool, error)
+		// TTL returns the time to live of key, NoExpiration or NotExists.
+		TTL(key string) (time.Duration, error)
+		// Exists returns how many of keys exist.
+		Exists(keys ...string) (int64, error)
+		// GetSet sets key and decodes its previous value into object, it returns redis.Nil when key was missing.
+		GetSet(key string, value interface{}, object interface{}) error
+
 		SetZSetWithExpiration(string, time.Duration, ...redis.Z) error
 		SetZSet(string, ...redis.Z) error
 		GetZSet(string) ([]redis.Z, error)
@@ -41,18 +115,81 @@ type (
 		HGet(key, field string, response interface{}) error
 
 		MGet(key []string) ([]interface{}, error)
+		// MGetInto decodes the values of keys into the slice results points to, missing keys are left as zero values.
+		MGetInto(keys []string, results interface{}) error
 
+		// Values of lists and sets are encoded by the codec, LRange and SMembers decode them into the
+		// slice results points to. Pops of empty lists return redis.Nil, BLPop returns the key it popped
+		// from and, on redis-cluster, its keys must hash to the same slot.
+		LPush(key string, values ...interface{}) (int64, error)
+		RPush(key string, values ...interface{}) (int64, error)
+		LPop(key string, object interface{}) error
+		RPop(key string, object interface{}) error
+		BLPop(timeout time.Duration, object interface{}, keys ...string) (string, error)
+		LRange(key string, start, stop int64, results interface{}) error
+
+		SAdd(key string, members ...interface{}) (int64, error)
+		SRem(key string, members ...interface{}) (int64, error)
+		SMembers(key string, results interface{}) error
+		SIsMember(key string, member interface{}) (bool, error)
+
+		// Sorted set members are stored as is like SetZSet, ZRangeByScore pages with Offset and Count.
+		ZAdd(key string, members ...redis.Z) (int64, error)
+		ZRem(key string, members ...interface{}) (int64, error)
+		ZRangeByScore(key string, by redis.ZRangeBy) ([]redis.Z, error)
+		ZRevRange(key string, start, stop int64) ([]redis.Z, error)
+		ZIncrBy(key string, increment float64, member string) (float64, error)
+
+		// Geo members are stored in sorted sets scored by geohash. GeoRadius and GeoSearch return the
+		// distance, coordinates and geohash asked by the query, distances are in its unit, km by
+		// default. Store and StoreDist of redis.GeoRadiusQuery aren't supported. GeoDist returns
+		// redis.Nil when a member is missing.
+		GeoAdd(key string, locations ...*redis.GeoLocation) (int64, error)
+		GeoRadius(key string, longitude, latitude float64, query redis.GeoRadiusQuery) ([]redis.GeoLocation, error)
+		GeoSearch(key string, query GeoSearchQuery) ([]redis.GeoLocation, error)
+		GeoDist(key, member1, member2, unit string) (float64, error)
+
+		// HyperLogLog elements are encoded by the codec. PFCount counts the union of keys, on
+		// redis-cluster the keys of PFCount and PFMerge must hash to the same slot.
+		PFAdd(key string, elements ...interface{}) (int64, error)
+		PFCount(keys ...string) (int64, error)
+		PFMerge(dest string, keys ...string) error
+
+		// Keys lists the keys matching a pattern with Scan.
 		Keys(string) ([]string, error)
+		// Scan iterates the keys matching pattern, count is the hint of keys per SCAN call. Redis-cluster
+		// and redis-universal iterate every master node.
+		Scan(pattern string, count int64) Iterator
 
 		Remove(string) error
+		// RemoveByPattern unlinks the keys matching a pattern with Scan, in batches of the given count.
 		RemoveByPattern(string, int64) error
 		FlushDatabase() error
 		FlushAll() error
 		Close() error
 
 		Pipeline() Pipe
+		// TxPipeline is a Pipe wrapped in MULTI/EXEC, on redis-cluster its keys must hash to the same slot.
+		TxPipeline() Pipe
+		// Watch runs fn in a transaction watching keys, on redis-cluster keys must hash to the same slot.
+		Watch(fn func(tx Tx) error, keys ...string) error
 		Client() Cache
-		Subscribe(channel string) (PubSub, error)
+		Subscribe(channels ...string) (PubSub, error)
+		// PSubscribe subscribes to the channels matching patterns, like "hotel:*".
+		PSubscribe(patterns ...string) (PubSub, error)
+		Publish(channel, message string) error
+
+		// RegisterScript adds the Lua script src to the registry of the cache as name. RunScript runs
+		// it with EVALSHA, sending it once with EVAL to a node that doesn't have it loaded. Scripts see
+		// raw arguments, not the encoded values of Set, and on redis-cluster their keys must hash to
+		// the same slot.
+		RegisterScript(name, src string)
+		RunScript(name string, keys []string, args ...interface{}) *ScriptResult
+	}
+
+	// Commander is implemented by the caches backed by a go-redis client.
+	Commander interface {
+		Cmdable() redis.Cmdable
 	}
 
 	PoolCallback func(client Cache)
@@ -63,3 +200,20 @@ type (
 		Close() error
 	}
 )
+
+const (
+	// NoExpiration is the TTL of a key that never expires.
+	NoExpiration time.Duration = -1
+	// NotExists is the TTL of a missing key.
+	NotExists time.Duration = -2
+)
+
+// Cmdable returns the go-redis client behind c, false when c isn't backed by one.
+func Cmdable(c Cache) (redis.Cmdable, bool) {
+	commander, ok := c.(Commander)
+	if !ok {
+		return nil, false
+	}
+
+	return commander.Cmdable(), true
+}
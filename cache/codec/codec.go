@@ -0,0 +1,205 @@
+// Package codec converts cached values to and from the bytes stored in the cache.
+package codec
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	Codec interface {
+		Marshal(value interface{}) ([]byte, error)
+		Unmarshal(data []byte, value interface{}) error
+	}
+
+	binaryCodec struct{}
+	jsonCodec   struct{}
+	gobCodec    struct{}
+)
+
+var (
+	// Binary stores strings, byte slices, numbers and booleans as is and anything else through
+	// encoding.BinaryMarshaler, the way go-redis writes values. It is the default codec.
+	Binary Codec = binaryCodec{}
+
+	JSON Codec = jsonCodec{}
+	Gob  Codec = gobCodec{}
+)
+
+// Default returns c, or Binary when c is nil.
+func Default(c Codec) Codec {
+	if c == nil {
+		return Binary
+	}
+
+	return c
+}
+
+func (binaryCodec) Marshal(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return []byte{}, nil
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case int:
+		return strconv.AppendInt(nil, int64(v), 10), nil
+	case int8:
+		return strconv.AppendInt(nil, int64(v), 10), nil
+	case int16:
+		return strconv.AppendInt(nil, int64(v), 10), nil
+	case int32:
+		return strconv.AppendInt(nil, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(nil, v, 10), nil
+	case uint:
+		return strconv.AppendUint(nil, uint64(v), 10), nil
+	case uint8:
+		return strconv.AppendUint(nil, uint64(v), 10), nil
+	case uint16:
+		return strconv.AppendUint(nil, uint64(v), 10), nil
+	case uint32:
+		return strconv.AppendUint(nil, uint64(v), 10), nil
+	case uint64:
+		return strconv.AppendUint(nil, v, 10), nil
+	case float32:
+		return strconv.AppendFloat(nil, float64(v), 'f', -1, 64), nil
+	case float64:
+		return strconv.AppendFloat(nil, v, 'f', -1, 64), nil
+	case bool:
+		if v {
+			return []byte("1"), nil
+		}
+		return []byte("0"), nil
+	case encoding.BinaryMarshaler:
+		return v.MarshalBinary()
+	default:
+		return nil, errors.Errorf("can't marshal %T (implement encoding.BinaryMarshaler)", value)
+	}
+}
+
+func (binaryCodec) Unmarshal(data []byte, value interface{}) (err error) {
+	switch v := value.(type) {
+	case nil:
+		return errors.New("can't unmarshal into nil")
+	case *string:
+		*v = string(data)
+	case *[]byte:
+		*v = append([]byte(nil), data...)
+	case *int:
+		var n int64
+		n, err = strconv.ParseInt(string(data), 10, 0)
+		*v = int(n)
+	case *int8:
+		var n int64
+		n, err = strconv.ParseInt(string(data), 10, 8)
+		*v = int8(n)
+	case *int16:
+		var n int64
+		n, err = strconv.ParseInt(string(data), 10, 16)
+		*v = int16(n)
+	case *int32:
+		var n int64
+		n, err = strconv.ParseInt(string(data), 10, 32)
+		*v = int32(n)
+	case *int64:
+		*v, err = strconv.ParseInt(string(data), 10, 64)
+	case *uint:
+		var n uint64
+		n, err = strconv.ParseUint(string(data), 10, 0)
+		*v = uint(n)
+	case *uint8:
+		var n uint64
+		n, err = strconv.ParseUint(string(data), 10, 8)
+		*v = uint8(n)
+	case *uint16:
+		var n uint64
+		n, err = strconv.ParseUint(string(data), 10, 16)
+		*v = uint16(n)
+	case *uint32:
+		var n uint64
+		n, err = strconv.ParseUint(string(data), 10, 32)
+		*v = uint32(n)
+	case *uint64:
+		*v, err = strconv.ParseUint(string(data), 10, 64)
+	case *float32:
+		var n float64
+		n, err = strconv.ParseFloat(string(data), 32)
+		*v = float32(n)
+	case *float64:
+		*v, err = strconv.ParseFloat(string(data), 64)
+	case *bool:
+		*v = len(data) == 1 && data[0] == '1'
+	case encoding.BinaryUnmarshaler:
+		err = v.UnmarshalBinary(data)
+	default:
+		err = errors.Errorf("can't unmarshal %T (implement encoding.BinaryUnmarshaler)", value)
+	}
+
+	return err
+}
+
+func (jsonCodec) Marshal(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (jsonCodec) Unmarshal(data []byte, value interface{}) error {
+	return json.Unmarshal(data, value)
+}
+
+func (gobCodec) Marshal(value interface{}) ([]byte, error) {
+	buffer := bytes.Buffer{}
+	if err := gob.NewEncoder(&buffer).Encode(value); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, value interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+// UnmarshalAll decodes values, as returned by MGet, into the slice results points to. Nil values,
+// missing keys, are left as the zero value of the element type.
+func UnmarshalAll(c Codec, values []interface{}, results interface{}) error {
+	slice := reflect.ValueOf(results)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("results must be a pointer to a slice, got %T", results)
+	}
+
+	slice = slice.Elem()
+	elements := reflect.MakeSlice(slice.Type(), len(values), len(values))
+
+	for n, value := range values {
+		if value == nil {
+			continue
+		}
+
+		data, ok := value.(string)
+		if !ok {
+			return errors.Errorf("unexpected value type %T at index %d", value, n)
+		}
+
+		element := elements.Index(n)
+		if element.Kind() == reflect.Ptr {
+			element.Set(reflect.New(element.Type().Elem()))
+		} else {
+			element = element.Addr()
+		}
+
+		if err := c.Unmarshal([]byte(data), element.Interface()); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal value at index %d", n)
+		}
+	}
+
+	slice.Set(elements)
+	return nil
+}
@@ -0,0 +1,79 @@
+package codec
+
+import (
+	"strings"
+	"testing"
+)
+
+type (
+	hotel struct {
+		Name  string
+		Stars int
+	}
+)
+
+func Test_codecs_round_trip_structs(t *testing.T) {
+	for name, c := range map[string]Codec{"json": JSON, "gob": Gob, "msgpack": Msgpack, "compressed": Compress(JSON, 8)} {
+		data, err := c.Marshal(hotel{Name: "Ayana", Stars: 5})
+		if err != nil {
+			t.Fatalf("%s should not error %s", name, err)
+		}
+
+		out := hotel{}
+		if err := c.Unmarshal(data, &out); err != nil {
+			t.Fatalf("%s should not error %s", name, err)
+		}
+
+		if out.Name != "Ayana" || out.Stars != 5 {
+			t.Errorf("%s unexpected hotel %+v", name, out)
+		}
+	}
+}
+
+func Test_Binary_round_trips_scalars(t *testing.T) {
+	data, err := Binary.Marshal(42)
+	if err != nil || string(data) != "42" {
+		t.Fatalf("should marshal like go-redis, got %q %v", data, err)
+	}
+
+	n := 0
+	if err := Binary.Unmarshal(data, &n); err != nil || n != 42 {
+		t.Errorf("should unmarshal 42, got %d %v", n, err)
+	}
+
+	if _, err := Binary.Marshal(hotel{}); err == nil {
+		t.Error("should require encoding.BinaryMarshaler for structs")
+	}
+}
+
+func Test_Compress_only_compresses_above_threshold(t *testing.T) {
+	c := Compress(Binary, 16)
+
+	small, _ := c.Marshal("tiket")
+	if small[0] != uncompressed {
+		t.Error("small value should not be compressed")
+	}
+
+	large, _ := c.Marshal(strings.Repeat("tiket", 100))
+	if large[0] != compressed || len(large) >= 500 {
+		t.Errorf("large value should be compressed, got %d bytes", len(large))
+	}
+
+	out := ""
+	if err := c.Unmarshal(large, &out); err != nil || out != strings.Repeat("tiket", 100) {
+		t.Errorf("should decompress value, got %v", err)
+	}
+}
+
+func Test_UnmarshalAll_leaves_missing_keys_empty(t *testing.T) {
+	values := []interface{}{`{"Name":"Ayana","Stars":5}`, nil}
+
+	results := make([]*hotel, 0)
+	if err := UnmarshalAll(JSON, values, &results); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if len(results) != 2 || results[0].Name != "Ayana" || results[1] != nil {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
@@ -0,0 +1,66 @@
+package codec
+
+import (
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultCompressThreshold = 1024
+
+	uncompressed byte = 0
+	compressed   byte = 1
+)
+
+type (
+	compressCodec struct {
+		codec     Codec
+		threshold int
+	}
+)
+
+// Compress wraps c so encoded values larger than threshold bytes are snappy compressed, a threshold
+// of 0 or less uses DefaultCompressThreshold. Every value is prefixed with a flag byte, so values
+// written without compression can't be read back through the wrapped codec.
+func Compress(c Codec, threshold int) Codec {
+	if threshold <= 0 {
+		threshold = DefaultCompressThreshold
+	}
+
+	return &compressCodec{codec: Default(c), threshold: threshold}
+}
+
+func (c *compressCodec) Marshal(value interface{}) ([]byte, error) {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) <= c.threshold {
+		return append([]byte{uncompressed}, data...), nil
+	}
+
+	encoded := make([]byte, 1+snappy.MaxEncodedLen(len(data)))
+	encoded[0] = compressed
+
+	return encoded[:1+len(snappy.Encode(encoded[1:], data))], nil
+}
+
+func (c *compressCodec) Unmarshal(data []byte, value interface{}) error {
+	if len(data) == 0 {
+		return errors.New("compressed value is missing its flag")
+	}
+
+	switch data[0] {
+	case uncompressed:
+		return c.codec.Unmarshal(data[1:], value)
+	case compressed:
+		decoded, err := snappy.Decode(nil, data[1:])
+		if err != nil {
+			return errors.Wrap(err, "failed to decompress value")
+		}
+		return c.codec.Unmarshal(decoded, value)
+	default:
+		return errors.Errorf("unknown compression flag %d", data[0])
+	}
+}
@@ -0,0 +1,21 @@
+package codec
+
+import (
+	"github.com/vmihailenco/msgpack"
+)
+
+type (
+	msgpackCodec struct{}
+)
+
+var (
+	Msgpack Codec = msgpackCodec{}
+)
+
+func (msgpackCodec) Marshal(value interface{}) ([]byte, error) {
+	return msgpack.Marshal(value)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, value interface{}) error {
+	return msgpack.Unmarshal(data, value)
+}
@@ -0,0 +1,33 @@
+package codec
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+type (
+	protobufCodec struct{}
+)
+
+var (
+	// Protobuf only accepts values implementing proto.Message.
+	Protobuf Codec = protobufCodec{}
+)
+
+func (protobufCodec) Marshal(value interface{}) ([]byte, error) {
+	message, ok := value.(proto.Message)
+	if !ok {
+		return nil, errors.Errorf("can't marshal %T (implement proto.Message)", value)
+	}
+
+	return proto.Marshal(message)
+}
+
+func (protobufCodec) Unmarshal(data []byte, value interface{}) error {
+	message, ok := value.(proto.Message)
+	if !ok {
+		return errors.Errorf("can't unmarshal %T (implement proto.Message)", value)
+	}
+
+	return proto.Unmarshal(data, message)
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+// GeoSearchQuery searches the members of a geo key around Member, or around Longitude and Latitude
+// when Member is empty, within Radius or within a box of Width and Height.
+type GeoSearchQuery struct {
+	Member              string
+	Longitude, Latitude float64
+
+	Radius        float64
+	Width, Height float64
+	// Unit of Radius, Width, Height and the distances: m, km, ft or mi. Defaults to km.
+	Unit string
+
+	WithCoord   bool
+	WithDist    bool
+	WithGeoHash bool
+	// Count limits the locations to the nearest ones.
+	Count int
+	// Sort is ASC or DESC by distance. Defaults to no order, ASC with Count.
+	Sort string
+}
+
+// NewGeoSearchCmd returns the GEOSEARCH command of query on key, run it with Process. GEOSEARCH
+// needs redis 6.2.
+func NewGeoSearchCmd(key string, query GeoSearchQuery) (*redis.GeoLocationCmd, error) {
+	args := []interface{}{"geosearch", key}
+	if query.Member != "" {
+		args = append(args, "frommember", query.Member)
+	} else {
+		args = append(args, "fromlonlat", query.Longitude, query.Latitude)
+	}
+
+	q := &redis.GeoRadiusQuery{
+		Unit:        query.Unit,
+		WithCoord:   query.WithCoord,
+		WithDist:    query.WithDist,
+		WithGeoHash: query.WithGeoHash,
+		Count:       query.Count,
+		Sort:        query.Sort,
+	}
+
+	// - the GeoLocationCmd of GEORADIUS appends its radius and unit, then the options GEOSEARCH shares
+	switch {
+	case query.Radius > 0:
+		args = append(args, "byradius")
+		q.Radius = query.Radius
+	case query.Width > 0 && query.Height > 0:
+		args = append(args, "bybox", query.Width)
+		q.Radius = query.Height
+	default:
+		return nil, errors.New("radius or width and height are required!")
+	}
+
+	return redis.NewGeoLocationCmd(q, args...), nil
+}
@@ -0,0 +1,225 @@
+// Package idempotency makes retried requests safe to run twice: the first request with an
+// idempotency key runs while its duplicates wait, then they get its stored response replayed.
+package idempotency
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultTTL     = 24 * time.Hour
+	DefaultLockTTL = time.Minute
+	DefaultPrefix  = "idempotency:"
+)
+
+var (
+	// ErrInProgress is returned by Begin while a request with the same key runs.
+	ErrInProgress = errors.New("idempotency: request in progress")
+	// ErrMismatch is returned by Begin when the key was used by a request with another fingerprint.
+	ErrMismatch = errors.New("idempotency: key reused by a different request")
+	// ErrNotOwner is returned by Complete and Release when the key expired or was taken over by
+	// another request since Begin.
+	ErrNotOwner = errors.New("idempotency: key not held by the request")
+)
+
+type (
+	Option struct {
+		// TTL is how long a response is stored and replayed.
+		TTL time.Duration
+		// LockTTL is how long a request stays in progress when it never completes, e.g. its
+		// instance died. Duplicates get ErrInProgress until then.
+		LockTTL time.Duration
+		// Prefix is prepended to every key.
+		Prefix string
+	}
+
+	// Response is a stored response, replayed as is for duplicates.
+	Response struct {
+		Status int         `json:"status"`
+		Header http.Header `json:"header"`
+		Body   []byte      `json:"body"`
+	}
+
+	Store interface {
+		// Begin marks key in progress for the request of fingerprint, the token it returns identifies
+		// the request to Complete and Release. It returns the response stored for a completed
+		// duplicate, ErrInProgress while a duplicate runs and ErrMismatch when key was used by
+		// another request.
+		Begin(key, fingerprint string) (token string, response *Response, err error)
+		// Complete stores the response of the request of token for TTL.
+		Complete(key, token string, response *Response) error
+		// Release removes key so the request of token can be retried, e.g. after a server error.
+		Release(key, token string) error
+	}
+
+	// record is stored under a key, without response while its request is in progress.
+	record struct {
+		Fingerprint string    `json:"fingerprint"`
+		Token       string    `json:"token,omitempty"`
+		Response    *Response `json:"response,omitempty"`
+	}
+
+	store struct {
+		cache  cache.Cache
+		option Option
+	}
+)
+
+func New(c cache.Cache, option *Option) (Store, error) {
+	if c == nil {
+		return nil, errors.New("cache is required!")
+	}
+
+	opt := Option{}
+	if option != nil {
+		opt = *option
+	}
+
+	if opt.TTL <= 0 {
+		opt.TTL = DefaultTTL
+	}
+
+	if opt.LockTTL <= 0 {
+		opt.LockTTL = DefaultLockTTL
+	}
+
+	if opt.Prefix == "" {
+		opt.Prefix = DefaultPrefix
+	}
+
+	return &store{cache: c, option: opt}, nil
+}
+
+func (s *store) Begin(key, fingerprint string) (string, *Response, error) {
+	k := s.option.Prefix + key
+
+	token, err := newToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	for {
+		ok, err := s.cache.SetNX(k, &record{Fingerprint: fingerprint, Token: token}, s.option.LockTTL)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "failed to lock key %s", key)
+		}
+
+		if ok {
+			return token, nil, nil
+		}
+
+		rec := record{}
+		if err := s.cache.Get(k, &rec); err != nil {
+			// - the record expired or was released since SetNX, lock it again
+			if errors.Cause(err) == redis.Nil {
+				continue
+			}
+			return "", nil, errors.Wrapf(err, "failed to get key %s", key)
+		}
+
+		switch {
+		case rec.Fingerprint != fingerprint:
+			return "", nil, ErrMismatch
+		case rec.Response == nil:
+			return "", nil, ErrInProgress
+		default:
+			return "", rec.Response, nil
+		}
+	}
+}
+
+func (s *store) Complete(key, token string, response *Response) error {
+	return s.owned(key, token, func(p cache.Pipe, rec *record) {
+		rec.Token = ""
+		rec.Response = response
+		p.SetWithExpiration(s.option.Prefix+key, rec, s.option.TTL)
+	})
+}
+
+func (s *store) Release(key, token string) error {
+	return s.owned(key, token, func(p cache.Pipe, rec *record) {
+		p.Remove(s.option.Prefix + key)
+	})
+}
+
+// owned runs the writes of fn in a transaction once the record of key is found still held by the
+// request of token, a record changed meanwhile isn't held anymore.
+func (s *store) owned(key, token string, fn func(p cache.Pipe, rec *record)) error {
+	k := s.option.Prefix + key
+
+	err := s.cache.Watch(func(tx cache.Tx) error {
+		rec := record{}
+		if err := tx.Get(k, &rec); err != nil {
+			if errors.Cause(err) == redis.Nil {
+				return ErrNotOwner
+			}
+			return err
+		}
+
+		if token == "" || rec.Token != token {
+			return ErrNotOwner
+		}
+
+		p := tx.Pipeline()
+		fn(p, &rec)
+		return p.Exec()
+	}, k)
+
+	switch {
+	case err == nil:
+		return nil
+	case err == ErrNotOwner || errors.Cause(err) == cache.ErrTxFailed:
+		return ErrNotOwner
+	default:
+		return errors.Wrapf(err, "failed to write key %s", key)
+	}
+}
+
+// Fingerprint hashes the caller, method, URL and body of r, so a key reused by another request or
+// caller is caught. The body is read and put back for the handlers.
+func Fingerprint(r *http.Request) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(Caller(r) + "\n"))
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+
+	if r.Body != nil {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read body")
+		}
+		_ = r.Body.Close()
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		h.Write(body)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func newToken() (string, error) {
+	value := make([]byte, 16)
+	if _, err := rand.Read(value); err != nil {
+		return "", errors.Wrap(err, "failed to generate token")
+	}
+
+	return hex.EncodeToString(value), nil
+}
+
+func (r record) MarshalBinary() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func (r *record) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, r)
+}
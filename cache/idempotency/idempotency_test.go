@@ -0,0 +1,209 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/memory"
+	paw_http "github.com/PAWSOME-INDONESIA/paw-utilities-go/http"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/logs"
+	shared_dto "github.com/PAWSOME-INDONESIA/paw-utilities-go/shared/dto"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/util/tiketerror"
+	"github.com/labstack/echo/v4"
+)
+
+func newStore(t *testing.T) Store {
+	c, err := memory.New(nil)
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	s, err := New(c, nil)
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	return s
+}
+
+func Test_Begin_locks_and_returns_stored_response(t *testing.T) {
+	s := newStore(t)
+
+	token, response, err := s.Begin("booking", "a")
+	if err != nil || response != nil || token == "" {
+		t.Fatalf("should begin, got %v %v", response, err)
+	}
+
+	if _, _, err := s.Begin("booking", "a"); err != ErrInProgress {
+		t.Errorf("should be in progress, got %v", err)
+	}
+
+	if err := s.Complete("booking", "other", &Response{Status: http.StatusOK}); err != ErrNotOwner {
+		t.Errorf("should not complete the request of another token, got %v", err)
+	}
+
+	if err := s.Complete("booking", token, &Response{Status: http.StatusCreated, Body: []byte("ok")}); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if _, response, err := s.Begin("booking", "a"); err != nil || response.Status != http.StatusCreated || string(response.Body) != "ok" {
+		t.Errorf("unexpected response %v %v", response, err)
+	}
+
+	if _, _, err := s.Begin("booking", "b"); err != ErrMismatch {
+		t.Errorf("should not match another fingerprint, got %v", err)
+	}
+
+	if err := s.Release("booking", token); err != ErrNotOwner {
+		t.Errorf("should not release a completed key, got %v", err)
+	}
+}
+
+func Test_Complete_checks_the_record_is_still_owned(t *testing.T) {
+	s := newStore(t)
+
+	stale, _, _ := s.Begin("booking", "a")
+	_ = s.Release("booking", stale)
+
+	// - the key was released and taken over by a retry before the first request completed
+	token, _, _ := s.Begin("booking", "a")
+
+	if err := s.Complete("booking", stale, &Response{Status: http.StatusOK}); err != ErrNotOwner {
+		t.Errorf("should not overwrite the record of the retry, got %v", err)
+	}
+
+	if err := s.Release("booking", stale); err != ErrNotOwner {
+		t.Errorf("should not release the record of the retry, got %v", err)
+	}
+
+	if _, _, err := s.Begin("booking", "a"); err != ErrInProgress {
+		t.Errorf("retry should still be in progress, got %v", err)
+	}
+
+	if err := s.Complete("booking", token, &Response{Status: http.StatusOK}); err != nil {
+		t.Errorf("should not error %s", err)
+	}
+}
+
+func Test_Middleware_replays_stored_responses(t *testing.T) {
+	calls := 0
+
+	e := echo.New()
+	e.Use(Middleware(newStore(t), nil))
+	e.POST("/booking", func(c echo.Context) error {
+		calls++
+		if c.QueryParam("fail") != "" {
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+		return c.String(http.StatusCreated, "booked")
+	})
+
+	do := func(key, target, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		r.Header.Set(DefaultHeader, key)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+		return w
+	}
+
+	first := do("1", "/booking", "room=1")
+	second := do("1", "/booking", "room=1")
+
+	if calls != 1 || second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("should replay the response, got %d calls, %d %s", calls, second.Code, second.Body.String())
+	}
+
+	if second.Header().Get(ReplayedHeader) != "true" || first.Header().Get(ReplayedHeader) != "" {
+		t.Errorf("should only mark the replayed response")
+	}
+
+	body := shared_dto.BaseResponseDto{}
+	if err := json.Unmarshal(do("1", "/booking", "room=2").Body.Bytes(), &body); err != nil || body.Code != tiketerror.BAD_REQUEST {
+		t.Errorf("should reject a reused key, got %+v %v", body, err)
+	}
+
+	do("2", "/booking?fail=1", "")
+	if do("2", "/booking?fail=1", ""); calls != 3 {
+		t.Errorf("should run again after an error, got %d calls", calls)
+	}
+}
+
+func Test_Middleware_scopes_keys_by_caller_and_records_flushed_responses(t *testing.T) {
+	calls := 0
+
+	e := echo.New()
+	e.Use(Middleware(newStore(t), nil))
+	e.POST("/booking", func(c echo.Context) error {
+		calls++
+		c.Response().WriteHeader(http.StatusCreated)
+		_, _ = c.Response().Write([]byte("booked "))
+		c.Response().Flush()
+		_, _ = c.Response().Write([]byte(c.Request().Header.Get("username")))
+		return nil
+	})
+
+	do := func(username string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/booking", nil)
+		r.Header.Set(DefaultHeader, "1")
+		r.Header.Set("username", username)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+		return w
+	}
+
+	do("alice")
+	if w := do("alice"); calls != 1 || w.Body.String() != "booked alice" {
+		t.Errorf("should replay the whole flushed response, got %d calls, %s", calls, w.Body.String())
+	}
+
+	if w := do("bob"); calls != 2 || w.Body.String() != "booked bob" {
+		t.Errorf("should not share the key with another caller, got %d calls, %s", calls, w.Body.String())
+	}
+}
+
+type handlerContext struct {
+	paw_http.RequestContext
+	request  *http.Request
+	response *echo.Response
+}
+
+func (c *handlerContext) Request() *http.Request {
+	return c.request
+}
+
+func (c *handlerContext) Response() http.ResponseWriter {
+	return c.response
+}
+
+func (c *handlerContext) Logger() logs.Logger {
+	return nil
+}
+
+func Test_Handler_stores_responses_without_body(t *testing.T) {
+	calls := 0
+	handler := Handler(newStore(t), nil, func(c paw_http.RequestContext) error {
+		calls++
+		c.Response().WriteHeader(http.StatusNoContent)
+		return nil
+	})
+
+	e := echo.New()
+	do := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodDelete, "/booking/1", nil)
+		r.Header.Set(DefaultHeader, "1")
+		w := httptest.NewRecorder()
+
+		if err := handler(&handlerContext{request: r, response: echo.NewResponse(w, e)}); err != nil {
+			t.Fatalf("should not error %s", err)
+		}
+		return w
+	}
+
+	do()
+	if w := do(); calls != 1 || w.Code != http.StatusNoContent || w.Header().Get(ReplayedHeader) != "true" {
+		t.Errorf("should replay the response, got %d calls, %d", calls, w.Code)
+	}
+}
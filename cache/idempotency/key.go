@@ -0,0 +1,78 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+// DefaultHeader is the header carrying the idempotency key.
+const DefaultHeader = "Idempotency-Key"
+
+// KeyFunc returns the idempotency key of a request, requests with an empty key always run.
+type KeyFunc func(r *http.Request) string
+
+// Caller identifies the caller of r by the username and storeId of the MandatoryRequestDto and the
+// Authorization header, hashed so credentials aren't kept in the cache. Keys are scoped by it, so
+// callers sending the same key don't share a response.
+func Caller(r *http.Request) string {
+	h := sha256.New()
+	for _, name := range []string{"username", "storeId"} {
+		value := r.Header.Get(name)
+		if value == "" {
+			value = r.URL.Query().Get(name)
+		}
+		h.Write([]byte(name + "=" + value + "\n"))
+	}
+	h.Write([]byte("authorization=" + r.Header.Get("Authorization")))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ByHeader keys on the value of header.
+func ByHeader(header string) KeyFunc {
+	return func(r *http.Request) string {
+		value := r.Header.Get(header)
+		if value == "" {
+			return ""
+		}
+
+		return "header:" + value
+	}
+}
+
+// ByRequestID keys on the requestId of the MandatoryRequestDto, taken from the headers of r before
+// its query parameters.
+func ByRequestID() KeyFunc {
+	return func(r *http.Request) string {
+		value := r.Header.Get("requestId")
+		if value == "" {
+			value = r.URL.Query().Get("requestId")
+		}
+
+		if value == "" {
+			return ""
+		}
+
+		return "requestId:" + value
+	}
+}
+
+// FirstOf keys on the first KeyFunc returning a key.
+func FirstOf(keys ...KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		for _, key := range keys {
+			if k := key(r); k != "" {
+				return k
+			}
+		}
+
+		return ""
+	}
+}
+
+// DefaultKey keys on the Idempotency-Key header, falling back to the requestId of the
+// MandatoryRequestDto.
+func DefaultKey() KeyFunc {
+	return FirstOf(ByHeader(DefaultHeader), ByRequestID())
+}
@@ -0,0 +1,232 @@
+package idempotency
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	paw_http "github.com/PAWSOME-INDONESIA/paw-utilities-go/http"
+	shared_dto "github.com/PAWSOME-INDONESIA/paw-utilities-go/shared/dto"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/util/tiketerror"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+// ReplayedHeader is set to "true" on replayed responses.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// errReplayed is returned by start once a stored response was replayed.
+var errReplayed = errors.New("idempotency: response replayed")
+
+type (
+	// request is a request that runs, its response is recorded to be stored.
+	request struct {
+		store    Store
+		key      string
+		token    string
+		res      *echo.Response
+		recorder *recorder
+	}
+
+	recorder struct {
+		http.ResponseWriter
+		body bytes.Buffer
+	}
+)
+
+// Middleware runs the requests of an echo server once per key and replays the stored response to
+// their duplicates. Duplicates of a running request get DUPLICATE_DATA and requests reusing the key
+// of another one get BAD_REQUEST. Responses of errors returned by the handler and of server errors
+// aren't stored so the request can be retried. Requests run when the cache fails.
+func Middleware(s Store, key KeyFunc) echo.MiddlewareFunc {
+	if key == nil {
+		key = DefaultKey()
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return serve(s, key, c.Response(), c.Request(), func() error {
+				return next(c)
+			}, func(err error) {
+				c.Logger().Errorf("%+v\n", err)
+			})
+		}
+	}
+}
+
+// Handler runs handler once per key like Middleware, register the returned handler as the route
+// handler of a http.Server, e.g. server.POST("/booking", idempotency.Handler(s, nil, book)).
+func Handler(s Store, key KeyFunc, handler paw_http.HandlerFunc) paw_http.HandlerFunc {
+	if key == nil {
+		key = DefaultKey()
+	}
+
+	return func(c paw_http.RequestContext) error {
+		res, ok := c.Response().(*echo.Response)
+		if !ok {
+			return errors.Errorf("idempotency: response %T is not an *echo.Response", c.Response())
+		}
+
+		return serve(s, key, res, c.Request(), func() error {
+			return handler(c)
+		}, func(err error) {
+			if c.Logger() != nil {
+				c.Logger().Errorf("%+v\n", err)
+			}
+		})
+	}
+}
+
+// serve runs next once per key and writes the response of its duplicates instead, log reports the
+// errors that don't fail the request.
+func serve(s Store, key KeyFunc, res *echo.Response, r *http.Request, next func() error, log func(error)) error {
+	req, written, err := start(s, key, res, r)
+	if written {
+		if _, rejected := err.(tiketerror.ErrorStandard); rejected || err == errReplayed {
+			return nil
+		}
+		return err
+	}
+
+	if err != nil {
+		log(err)
+		return next()
+	}
+
+	if req == nil {
+		return next()
+	}
+
+	// - the response of a returned error is written by the error handler, after this one
+	if err := next(); err != nil {
+		if e := s.Release(req.key, req.token); e != nil {
+			log(e)
+		}
+		return err
+	}
+
+	if err := req.finish(); err != nil {
+		log(err)
+	}
+
+	return nil
+}
+
+// start begins the request r, its key is scoped by the Caller. written is true when the response of
+// a duplicate was written, err is then errReplayed or the rejection. It returns a nil request for
+// requests without a key.
+func start(s Store, key KeyFunc, res *echo.Response, r *http.Request) (req *request, written bool, err error) {
+	k := key(r)
+	if k == "" {
+		return nil, false, nil
+	}
+	k = Caller(r) + ":" + k
+
+	fingerprint, err := Fingerprint(r)
+	if err != nil {
+		return nil, false, err
+	}
+
+	token, stored, err := s.Begin(k, fingerprint)
+	switch {
+	case err == ErrInProgress:
+		return nil, true, reject(res, tiketerror.New(tiketerror.DUPLICATE_DATA, err))
+	case err == ErrMismatch:
+		return nil, true, reject(res, tiketerror.New(tiketerror.BAD_REQUEST, err))
+	case err != nil:
+		return nil, false, err
+	case stored != nil:
+		if err := replay(res, stored); err != nil {
+			return nil, true, err
+		}
+		return nil, true, errReplayed
+	}
+
+	rec := &recorder{ResponseWriter: res.Writer}
+	res.Writer = rec
+
+	return &request{store: s, key: k, token: token, res: res, recorder: rec}, false, nil
+}
+
+// finish stores the response once the handler returned, it releases the key of a server error or
+// of a request without response.
+func (req *request) finish() error {
+	if !req.res.Committed || req.res.Status >= http.StatusInternalServerError {
+		return req.store.Release(req.key, req.token)
+	}
+
+	response := &Response{
+		Status: req.res.Status,
+		Header: make(http.Header, len(req.res.Header())),
+		Body:   req.recorder.body.Bytes(),
+	}
+	for name, values := range req.res.Header() {
+		response.Header[name] = append([]string(nil), values...)
+	}
+
+	return req.store.Complete(req.key, req.token, response)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush flushes the response to the client, the body written so far is recorded already.
+func (r *recorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hands the connection over to the handler, the response written on it isn't recorded so
+// the key is released.
+func (r *recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("idempotency: response can't be hijacked")
+	}
+
+	return h.Hijack()
+}
+
+func replay(res *echo.Response, stored *Response) error {
+	for name, values := range stored.Header {
+		res.Header()[name] = values
+	}
+	res.Header().Set(ReplayedHeader, "true")
+	res.WriteHeader(stored.Status)
+
+	if len(stored.Body) == 0 {
+		return nil
+	}
+
+	if _, err := res.Write(stored.Body); err != nil {
+		return errors.Wrap(err, "failed to replay response")
+	}
+
+	return nil
+}
+
+// reject writes the response of rejection, it returns rejection.
+func reject(res *echo.Response, rejection tiketerror.ErrorStandard) error {
+	body := &shared_dto.BaseResponseDto{
+		Code:       rejection.GetCode(),
+		Message:    rejection.GetMessage(),
+		Data:       nil,
+		Errors:     rejection.GetErrors(),
+		ServerTime: time.Now().Unix(),
+	}
+
+	res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	res.WriteHeader(rejection.GetHTTPStatus())
+
+	if err := json.NewEncoder(res).Encode(body); err != nil {
+		return errors.Wrap(err, "failed to write rejection")
+	}
+
+	return rejection
+}
@@ -0,0 +1,431 @@
+// Package instrument decorates a cache.Cache with metrics: hits, misses, errors and latency per
+// operation and key prefix. Metrics are recorded by a Metrics, see NewPrometheus and NewRelic.
+package instrument
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultSeparator = ":"
+)
+
+const (
+	// OK is the result of an operation that succeeded, Hit and Miss those of reads.
+	OK Result = iota
+	Hit
+	Miss
+	Error
+)
+
+type (
+	Result int
+
+	// Metrics records the operations of an instrumented cache. Start is called when an operation on
+	// keys with prefix starts, the returned func is called with its result once it returns. ctx is
+	// the one given to WithContext, context.Background otherwise.
+	Metrics interface {
+		Start(ctx context.Context, operation, prefix string) func(result Result)
+	}
+
+	Option struct {
+		// Separator ends the prefix of a key, keys without one have an empty prefix. Defaults to ":".
+		Separator string
+	}
+
+	instrumented struct {
+		cache.Cache
+		metrics   Metrics
+		separator string
+		ctx       context.Context
+	}
+
+	multi []Metrics
+)
+
+func (r Result) String() string {
+	switch r {
+	case Hit:
+		return "hit"
+	case Miss:
+		return "miss"
+	case Error:
+		return "error"
+	}
+
+	return "ok"
+}
+
+// New returns c recording its operations on metrics.
+func New(c cache.Cache, metrics Metrics, option *Option) (cache.Cache, error) {
+	if c == nil {
+		return nil, errors.New("cache is required!")
+	}
+
+	if metrics == nil {
+		return nil, errors.New("metrics is required!")
+	}
+
+	opt := Option{}
+	if option != nil {
+		opt = *option
+	}
+
+	if opt.Separator == "" {
+		opt.Separator = DefaultSeparator
+	}
+
+	return &instrumented{Cache: c, metrics: metrics, separator: opt.Separator, ctx: context.Background()}, nil
+}
+
+// WithContext returns c recording its operations with ctx, e.g. the request carrying a New Relic
+// transaction. c is returned as is when it isn't instrumented.
+func WithContext(c cache.Cache, ctx context.Context) cache.Cache {
+	i, ok := c.(*instrumented)
+	if !ok || ctx == nil {
+		return c
+	}
+
+	clone := *i
+	clone.ctx = ctx
+	return &clone
+}
+
+// Multi records operations on every one of metrics.
+func Multi(metrics ...Metrics) Metrics {
+	return multi(metrics)
+}
+
+func (m multi) Start(ctx context.Context, operation, prefix string) func(result Result) {
+	done := make([]func(Result), len(m))
+	for n, metrics := range m {
+		done[n] = metrics.Start(ctx, operation, prefix)
+	}
+
+	return func(result Result) {
+		for _, d := range done {
+			d(result)
+		}
+	}
+}
+
+// prefix returns the part of key before the separator.
+func (i *instrumented) prefix(key string) string {
+	n := strings.Index(key, i.separator)
+	if n < 0 {
+		return ""
+	}
+
+	return key[:n]
+}
+
+// start starts recording operation on key, the returned func records the error it points to:
+//
+//	defer i.start("get", key, true)(&err)
+func (i *instrumented) start(operation, key string, read bool) func(err *error) {
+	done := i.metrics.Start(i.ctx, operation, i.prefix(key))
+
+	return func(err *error) {
+		switch {
+		case *err == nil && read:
+			done(Hit)
+		case *err == nil:
+			done(OK)
+		case errors.Cause(*err) == redis.Nil:
+			done(Miss)
+		default:
+			done(Error)
+		}
+	}
+}
+
+func first(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+
+	return keys[0]
+}
+
+func (i *instrumented) SetWithExpiration(key string, value interface{}, duration time.Duration) (err error) {
+	defer i.start("setwithexpiration", key, false)(&err)
+	return i.Cache.SetWithExpiration(key, value, duration)
+}
+
+func (i *instrumented) Set(key string, value interface{}) (err error) {
+	defer i.start("set", key, false)(&err)
+	return i.Cache.Set(key, value)
+}
+
+func (i *instrumented) Get(key string, object interface{}) (err error) {
+	defer i.start("get", key, true)(&err)
+	return i.Cache.Get(key, object)
+}
+
+func (i *instrumented) Incr(key string) (val int64, err error) {
+	defer i.start("incr", key, false)(&err)
+	return i.Cache.Incr(key)
+}
+
+func (i *instrumented) Decr(key string) (val int64, err error) {
+	defer i.start("decr", key, false)(&err)
+	return i.Cache.Decr(key)
+}
+
+func (i *instrumented) IncrBy(key string, value int64) (val int64, err error) {
+	defer i.start("incrby", key, false)(&err)
+	return i.Cache.IncrBy(key, value)
+}
+
+func (i *instrumented) SetNX(key string, value interface{}, ttl time.Duration) (ok bool, err error) {
+	defer i.start("setnx", key, false)(&err)
+	return i.Cache.SetNX(key, value, ttl)
+}
+
+func (i *instrumented) Expire(key string, ttl time.Duration) (ok bool, err error) {
+	defer i.start("expire", key, false)(&err)
+	return i.Cache.Expire(key, ttl)
+}
+
+func (i *instrumented) TTL(key string) (ttl time.Duration, err error) {
+	defer i.start("ttl", key, false)(&err)
+	return i.Cache.TTL(key)
+}
+
+func (i *instrumented) Exists(keys ...string) (val int64, err error) {
+	defer i.start("exists", first(keys), false)(&err)
+	return i.Cache.Exists(keys...)
+}
+
+func (i *instrumented) GetSet(key string, value interface{}, object interface{}) (err error) {
+	defer i.start("getset", key, true)(&err)
+	return i.Cache.GetSet(key, value, object)
+}
+
+func (i *instrumented) SetZSetWithExpiration(key string, duration time.Duration, data ...redis.Z) (err error) {
+	defer i.start("setzsetwithexpiration", key, false)(&err)
+	return i.Cache.SetZSetWithExpiration(key, duration, data...)
+}
+
+func (i *instrumented) SetZSet(key string, data ...redis.Z) (err error) {
+	defer i.start("setzset", key, false)(&err)
+	return i.Cache.SetZSet(key, data...)
+}
+
+func (i *instrumented) GetZSet(key string) (val []redis.Z, err error) {
+	defer i.start("getzset", key, true)(&err)
+	return i.Cache.GetZSet(key)
+}
+
+func (i *instrumented) HMSetWithExpiration(key string, value map[string]interface{}, ttl time.Duration) (err error) {
+	defer i.start("hmsetwithexpiration", key, false)(&err)
+	return i.Cache.HMSetWithExpiration(key, value, ttl)
+}
+
+func (i *instrumented) HMSet(key string, value map[string]interface{}) (err error) {
+	defer i.start("hmset", key, false)(&err)
+	return i.Cache.HMSet(key, value)
+}
+
+func (i *instrumented) HSetWithExpiration(key, field string, value interface{}, ttl time.Duration) (err error) {
+	defer i.start("hsetwithexpiration", key, false)(&err)
+	return i.Cache.HSetWithExpiration(key, field, value, ttl)
+}
+
+func (i *instrumented) HSet(key, field string, value interface{}) (err error) {
+	defer i.start("hset", key, false)(&err)
+	return i.Cache.HSet(key, field, value)
+}
+
+func (i *instrumented) HMGet(key string, fields ...string) (val []interface{}, err error) {
+	defer i.start("hmget", key, false)(&err)
+	return i.Cache.HMGet(key, fields...)
+}
+
+func (i *instrumented) HGetAll(key string) (val map[string]string, err error) {
+	defer i.start("hgetall", key, false)(&err)
+	return i.Cache.HGetAll(key)
+}
+
+func (i *instrumented) HGet(key, field string, response interface{}) (err error) {
+	defer i.start("hget", key, true)(&err)
+	return i.Cache.HGet(key, field, response)
+}
+
+func (i *instrumented) MGet(keys []string) (val []interface{}, err error) {
+	defer i.start("mget", first(keys), false)(&err)
+	return i.Cache.MGet(keys)
+}
+
+func (i *instrumented) MGetInto(keys []string, results interface{}) (err error) {
+	defer i.start("mgetinto", first(keys), false)(&err)
+	return i.Cache.MGetInto(keys, results)
+}
+
+func (i *instrumented) LPush(key string, values ...interface{}) (val int64, err error) {
+	defer i.start("lpush", key, false)(&err)
+	return i.Cache.LPush(key, values...)
+}
+
+func (i *instrumented) RPush(key string, values ...interface{}) (val int64, err error) {
+	defer i.start("rpush", key, false)(&err)
+	return i.Cache.RPush(key, values...)
+}
+
+func (i *instrumented) LPop(key string, object interface{}) (err error) {
+	defer i.start("lpop", key, true)(&err)
+	return i.Cache.LPop(key, object)
+}
+
+func (i *instrumented) RPop(key string, object interface{}) (err error) {
+	defer i.start("rpop", key, true)(&err)
+	return i.Cache.RPop(key, object)
+}
+
+func (i *instrumented) BLPop(timeout time.Duration, object interface{}, keys ...string) (key string, err error) {
+	defer i.start("blpop", first(keys), true)(&err)
+	return i.Cache.BLPop(timeout, object, keys...)
+}
+
+func (i *instrumented) LRange(key string, start, stop int64, results interface{}) (err error) {
+	defer i.start("lrange", key, false)(&err)
+	return i.Cache.LRange(key, start, stop, results)
+}
+
+func (i *instrumented) SAdd(key string, members ...interface{}) (val int64, err error) {
+	defer i.start("sadd", key, false)(&err)
+	return i.Cache.SAdd(key, members...)
+}
+
+func (i *instrumented) SRem(key string, members ...interface{}) (val int64, err error) {
+	defer i.start("srem", key, false)(&err)
+	return i.Cache.SRem(key, members...)
+}
+
+func (i *instrumented) SMembers(key string, results interface{}) (err error) {
+	defer i.start("smembers", key, false)(&err)
+	return i.Cache.SMembers(key, results)
+}
+
+func (i *instrumented) SIsMember(key string, member interface{}) (ok bool, err error) {
+	defer i.start("sismember", key, false)(&err)
+	return i.Cache.SIsMember(key, member)
+}
+
+func (i *instrumented) ZAdd(key string, members ...redis.Z) (val int64, err error) {
+	defer i.start("zadd", key, false)(&err)
+	return i.Cache.ZAdd(key, members...)
+}
+
+func (i *instrumented) ZRem(key string, members ...interface{}) (val int64, err error) {
+	defer i.start("zrem", key, false)(&err)
+	return i.Cache.ZRem(key, members...)
+}
+
+func (i *instrumented) ZRangeByScore(key string, by redis.ZRangeBy) (val []redis.Z, err error) {
+	defer i.start("zrangebyscore", key, false)(&err)
+	return i.Cache.ZRangeByScore(key, by)
+}
+
+func (i *instrumented) ZRevRange(key string, start, stop int64) (val []redis.Z, err error) {
+	defer i.start("zrevrange", key, false)(&err)
+	return i.Cache.ZRevRange(key, start, stop)
+}
+
+func (i *instrumented) ZIncrBy(key string, increment float64, member string) (val float64, err error) {
+	defer i.start("zincrby", key, false)(&err)
+	return i.Cache.ZIncrBy(key, increment, member)
+}
+
+func (i *instrumented) GeoAdd(key string, locations ...*redis.GeoLocation) (val int64, err error) {
+	defer i.start("geoadd", key, false)(&err)
+	return i.Cache.GeoAdd(key, locations...)
+}
+
+func (i *instrumented) GeoRadius(key string, longitude, latitude float64, query redis.GeoRadiusQuery) (val []redis.GeoLocation, err error) {
+	defer i.start("georadius", key, false)(&err)
+	return i.Cache.GeoRadius(key, longitude, latitude, query)
+}
+
+func (i *instrumented) GeoSearch(key string, query cache.GeoSearchQuery) (val []redis.GeoLocation, err error) {
+	defer i.start("geosearch", key, false)(&err)
+	return i.Cache.GeoSearch(key, query)
+}
+
+func (i *instrumented) GeoDist(key, member1, member2, unit string) (val float64, err error) {
+	defer i.start("geodist", key, true)(&err)
+	return i.Cache.GeoDist(key, member1, member2, unit)
+}
+
+func (i *instrumented) PFAdd(key string, elements ...interface{}) (val int64, err error) {
+	defer i.start("pfadd", key, false)(&err)
+	return i.Cache.PFAdd(key, elements...)
+}
+
+func (i *instrumented) PFCount(keys ...string) (val int64, err error) {
+	defer i.start("pfcount", first(keys), false)(&err)
+	return i.Cache.PFCount(keys...)
+}
+
+func (i *instrumented) PFMerge(dest string, keys ...string) (err error) {
+	defer i.start("pfmerge", dest, false)(&err)
+	return i.Cache.PFMerge(dest, keys...)
+}
+
+func (i *instrumented) Keys(pattern string) (val []string, err error) {
+	defer i.start("keys", pattern, false)(&err)
+	return i.Cache.Keys(pattern)
+}
+
+func (i *instrumented) Remove(key string) (err error) {
+	defer i.start("remove", key, false)(&err)
+	return i.Cache.Remove(key)
+}
+
+func (i *instrumented) RemoveByPattern(pattern string, countPerLoop int64) (err error) {
+	defer i.start("removebypattern", pattern, false)(&err)
+	return i.Cache.RemoveByPattern(pattern, countPerLoop)
+}
+
+func (i *instrumented) FlushDatabase() (err error) {
+	defer i.start("flushdatabase", "", false)(&err)
+	return i.Cache.FlushDatabase()
+}
+
+func (i *instrumented) FlushAll() (err error) {
+	defer i.start("flushall", "", false)(&err)
+	return i.Cache.FlushAll()
+}
+
+func (i *instrumented) Pipeline() cache.Pipe {
+	return &pipe{Pipe: i.Cache.Pipeline(), i: i, operation: "pipeline"}
+}
+
+func (i *instrumented) TxPipeline() cache.Pipe {
+	return &pipe{Pipe: i.Cache.TxPipeline(), i: i, operation: "txpipeline"}
+}
+
+func (i *instrumented) Watch(fn func(tx cache.Tx) error, keys ...string) (err error) {
+	defer i.start("watch", first(keys), false)(&err)
+	return i.Cache.Watch(fn, keys...)
+}
+
+func (i *instrumented) RunScript(name string, keys []string, args ...interface{}) *cache.ScriptResult {
+	done := i.start("runscript", first(keys), false)
+	r := i.Cache.RunScript(name, keys, args...)
+
+	err := r.Err()
+	done(&err)
+
+	return r
+}
+
+func (i *instrumented) Client() cache.Cache {
+	return i
+}
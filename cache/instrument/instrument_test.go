@@ -0,0 +1,95 @@
+package instrument
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/memory"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type recorder []string
+
+func (r *recorder) Start(ctx context.Context, operation, prefix string) func(result Result) {
+	return func(result Result) {
+		*r = append(*r, operation+" "+prefix+" "+result.String())
+	}
+}
+
+func Test_records_hits_misses_and_errors_by_prefix(t *testing.T) {
+	remote, _ := memory.New(nil)
+	defer remote.Close()
+
+	r := &recorder{}
+	c, err := New(remote, r, nil)
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	hotel := ""
+	_ = c.Set("hotel:1", "Ayana")
+	_ = c.Get("hotel:1", &hotel)
+	_ = c.Get("hotel:2", &hotel)
+	_, _ = c.Incr("hotel:1")
+
+	p := c.Pipeline()
+	p.Get("room")
+	_ = p.Exec()
+
+	expected := []string{"set hotel ok", "get hotel hit", "get hotel miss", "incr hotel error", "pipeline  ok"}
+	if strings.Join(*r, ",") != strings.Join(expected, ",") {
+		t.Errorf("unexpected records %v", *r)
+	}
+}
+
+func Test_Prometheus_registers_counters_and_histograms(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	p, err := NewPrometheus(&PrometheusOption{Buckets: []float64{1}, Registerer: registry})
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+	p.Start(context.Background(), "get", "hotel")(Hit)
+
+	// - a second cache on the same registry shares the metrics
+	shared, err := NewPrometheus(&PrometheusOption{Buckets: []float64{1}, Registerer: registry})
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+	shared.Start(context.Background(), "get", "hotel")(Miss)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := make([]string, 0, len(metric.GetLabel()))
+			for _, label := range metric.GetLabel() {
+				labels = append(labels, label.GetValue())
+			}
+
+			name := family.GetName() + "{" + strings.Join(labels, ",") + "}"
+			if h := metric.GetHistogram(); h != nil {
+				values[name] = float64(h.GetSampleCount())
+				values[name+" le=1"] = float64(h.GetBucket()[0].GetCumulativeCount())
+			} else {
+				values[name] = metric.GetCounter().GetValue()
+			}
+		}
+	}
+
+	for name, value := range map[string]float64{
+		"cache_operations_total{get,hotel,hit}":            1,
+		"cache_operations_total{get,hotel,miss}":           1,
+		"cache_operation_duration_seconds{get,hotel}":      2,
+		"cache_operation_duration_seconds{get,hotel} le=1": 2,
+	} {
+		if values[name] != value {
+			t.Errorf("%s should be %v, got %v", name, value, values)
+		}
+	}
+}
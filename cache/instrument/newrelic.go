@@ -0,0 +1,42 @@
+package instrument
+
+import (
+	"context"
+
+	newrelic "github.com/newrelic/go-agent"
+)
+
+type (
+	newRelicMetrics struct {
+		product newrelic.DatastoreProduct
+	}
+)
+
+// NewRelic returns Metrics recording operations as datastore segments of the New Relic transaction
+// of the context given to WithContext, the prefix is the collection. Operations without one aren't
+// recorded.
+func NewRelic(product newrelic.DatastoreProduct) Metrics {
+	if product == "" {
+		product = newrelic.DatastoreRedis
+	}
+
+	return &newRelicMetrics{product: product}
+}
+
+func (m *newRelicMetrics) Start(ctx context.Context, operation, prefix string) func(result Result) {
+	txn := newrelic.FromContext(ctx)
+	if txn == nil {
+		return func(Result) {}
+	}
+
+	segment := newrelic.DatastoreSegment{
+		StartTime:  newrelic.StartSegmentNow(txn),
+		Product:    m.product,
+		Collection: prefix,
+		Operation:  operation,
+	}
+
+	return func(Result) {
+		segment.End()
+	}
+}
@@ -0,0 +1,19 @@
+package instrument
+
+import (
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+)
+
+type (
+	// pipe records Exec as one operation, its commands are sent together.
+	pipe struct {
+		cache.Pipe
+		i         *instrumented
+		operation string
+	}
+)
+
+func (p *pipe) Exec() (err error) {
+	defer p.i.start(p.operation, "", false)(&err)
+	return p.Pipe.Exec()
+}
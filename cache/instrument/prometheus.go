@@ -0,0 +1,113 @@
+package instrument
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	DefaultNamespace = "cache"
+)
+
+var (
+	// DefaultBuckets are the latency buckets in seconds, the ones of the Prometheus client.
+	DefaultBuckets = prometheus.DefBuckets
+)
+
+type (
+	PrometheusOption struct {
+		// Namespace prefixes the metric names, defaults to "cache".
+		Namespace string
+		// Buckets of the latency histogram in seconds, in increasing order. Defaults to DefaultBuckets.
+		Buckets []float64
+		// Registerer the metrics are registered with, defaults to prometheus.DefaultRegisterer.
+		Registerer prometheus.Registerer
+	}
+
+	prometheusMetrics struct {
+		operations *prometheus.CounterVec
+		durations  *prometheus.HistogramVec
+	}
+)
+
+// NewPrometheus returns Metrics counting <namespace>_operations_total by operation, prefix and result,
+// and observing <namespace>_operation_duration_seconds by operation and prefix. Metrics already
+// registered under the same names, e.g. by another instrumented cache, are shared.
+func NewPrometheus(option *PrometheusOption) (Metrics, error) {
+	opt := PrometheusOption{}
+	if option != nil {
+		opt = *option
+	}
+
+	if opt.Namespace == "" {
+		opt.Namespace = DefaultNamespace
+	}
+
+	if len(opt.Buckets) == 0 {
+		opt.Buckets = DefaultBuckets
+	}
+
+	if opt.Registerer == nil {
+		opt.Registerer = prometheus.DefaultRegisterer
+	}
+
+	operations := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: opt.Namespace,
+		Name:      "operations_total",
+		Help:      "Cache operations by result: ok, hit, miss or error.",
+	}, []string{"operation", "prefix", "result"})
+
+	durations := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: opt.Namespace,
+		Name:      "operation_duration_seconds",
+		Help:      "Latency of cache operations.",
+		Buckets:   opt.Buckets,
+	}, []string{"operation", "prefix"})
+
+	if err := register(opt.Registerer, operations, &operations); err != nil {
+		return nil, err
+	}
+
+	if err := register(opt.Registerer, durations, &durations); err != nil {
+		return nil, err
+	}
+
+	return &prometheusMetrics{operations: operations, durations: durations}, nil
+}
+
+// register registers collector, target is set to the collector registered before under the same name.
+func register(registerer prometheus.Registerer, collector prometheus.Collector, target interface{}) error {
+	err := registerer.Register(collector)
+	if err == nil {
+		return nil
+	}
+
+	if registered, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		switch t := target.(type) {
+		case **prometheus.CounterVec:
+			if existing, ok := registered.ExistingCollector.(*prometheus.CounterVec); ok {
+				*t = existing
+				return nil
+			}
+		case **prometheus.HistogramVec:
+			if existing, ok := registered.ExistingCollector.(*prometheus.HistogramVec); ok {
+				*t = existing
+				return nil
+			}
+		}
+	}
+
+	return errors.Wrap(err, "failed to register prometheus metrics!")
+}
+
+func (p *prometheusMetrics) Start(ctx context.Context, operation, prefix string) func(result Result) {
+	start := time.Now()
+
+	return func(result Result) {
+		p.operations.WithLabelValues(operation, prefix, result.String()).Inc()
+		p.durations.WithLabelValues(operation, prefix).Observe(time.Since(start).Seconds())
+	}
+}
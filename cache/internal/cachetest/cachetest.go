@@ -0,0 +1,25 @@
+// Package cachetest holds the fixtures shared by the tests of the cache packages.
+package cachetest
+
+import (
+	"testing"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/redis"
+	"github.com/alicebob/miniredis/v2"
+)
+
+// NewRedis returns a cache backed by a miniredis server, the caller closes the server.
+func NewRedis(t *testing.T) (cache.Cache, *miniredis.Miniredis) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	c, err := redis.New(&redis.Option{Address: server.Addr()})
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	return c, server
+}
@@ -0,0 +1,295 @@
+package cache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/codec"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/logs"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	DefaultLockTTL   = 10 * time.Second
+	DefaultLockWait  = 3 * time.Second
+	DefaultLockRetry = 50 * time.Millisecond
+
+	negativeFlag byte = 1
+	headerSize        = 9
+)
+
+var (
+	// ErrNotFound is returned by a Loader when the value doesn't exist, GetOrLoad caches it for
+	// LoadOption.NegativeTTL and returns it for cached negative results.
+	ErrNotFound = errors.New("cache: value not found")
+)
+
+type (
+	// Loader loads the value of a cache miss, e.g. from the database.
+	Loader func() (interface{}, error)
+
+	// Locker takes a lock shared by every instance, Obtain returns false when another owner holds it.
+	Locker interface {
+		Obtain(key string, ttl time.Duration) (release func() error, ok bool, err error)
+	}
+
+	LoadOption struct {
+		// Codec encodes loaded values, defaults to codec.JSON.
+		Codec codec.Codec
+		// StaleTTL keeps an expired value this long, it is served while a single caller reloads it.
+		StaleTTL time.Duration
+		// NegativeTTL caches ErrNotFound results of the loader, 0 doesn't cache them.
+		NegativeTTL time.Duration
+		// Locker deduplicates loads across instances, nil only deduplicates them in-process.
+		Locker Locker
+		// LockTTL bounds how long a loader holds the lock.
+		LockTTL time.Duration
+		// LockWait is how long a caller that didn't get the lock waits for the value before loading it itself.
+		LockWait time.Duration
+		Logger   logs.Logger
+	}
+
+	// Loading is the cache-aside helper around a Cache.
+	Loading struct {
+		cache  Cache
+		option LoadOption
+		group  singleflight.Group
+	}
+
+	entry struct {
+		negative  bool
+		expiresAt time.Time
+		payload   []byte
+	}
+
+	redisLocker struct {
+		client redis.Cmdable
+	}
+)
+
+var (
+	releaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0`)
+)
+
+// NewLoading wraps c with GetOrLoad. Entries are stored as []byte, so the codec of c must support
+// byte slices, which every codec but codec.Protobuf does.
+func NewLoading(c Cache, option *LoadOption) (*Loading, error) {
+	if c == nil {
+		return nil, errors.New("cache is required!")
+	}
+
+	opt := LoadOption{}
+	if option != nil {
+		opt = *option
+	}
+
+	if opt.Codec == nil {
+		opt.Codec = codec.JSON
+	}
+
+	if opt.LockTTL <= 0 {
+		opt.LockTTL = DefaultLockTTL
+	}
+
+	if opt.LockWait <= 0 {
+		opt.LockWait = DefaultLockWait
+	}
+
+	return &Loading{cache: c, option: opt}, nil
+}
+
+// GetOrLoad decodes the cached value of key into object. On a miss the value is loaded once per key
+// in-process, and once across instances with a Locker, then cached for ttl. An expired value is still
+// served for StaleTTL while it is reloaded in the background.
+func (l *Loading) GetOrLoad(key string, ttl time.Duration, object interface{}, loader Loader) error {
+	e, ok := l.get(key)
+
+	if ok && time.Now().After(e.expiresAt) {
+		go func() {
+			// - a separate flight, a refresh that skips waiting for the lock has no entry to share
+			if _, err, _ := l.group.Do("refresh:"+key, func() (interface{}, error) {
+				return l.load(key, ttl, loader, false)
+			}); err != nil && errors.Cause(err) != ErrNotFound {
+				l.errorf("failed to refresh key %s: %s", key, err)
+			}
+		}()
+	}
+
+	if !ok {
+		result, err, _ := l.group.Do(key, func() (interface{}, error) {
+			return l.load(key, ttl, loader, true)
+		})
+
+		if err != nil {
+			return err
+		}
+
+		e = result.(*entry)
+	}
+
+	if e.negative {
+		return ErrNotFound
+	}
+
+	if err := l.option.Codec.Unmarshal(e.payload, object); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal key %s", key)
+	}
+
+	return nil
+}
+
+func (l *Loading) get(key string) (*entry, bool) {
+	raw := make([]byte, 0)
+	if err := l.cache.Get(key, &raw); err != nil {
+		if errors.Cause(err) != redis.Nil {
+			l.errorf("failed to get key %s: %s", key, err)
+		}
+		return nil, false
+	}
+
+	e, err := decodeEntry(raw)
+	if err != nil {
+		l.errorf("failed to decode key %s: %s", key, err)
+		return nil, false
+	}
+
+	return e, true
+}
+
+// load runs loader and caches its result, wait lets a caller without the lock wait for another instance.
+func (l *Loading) load(key string, ttl time.Duration, loader Loader, wait bool) (*entry, error) {
+	if l.option.Locker != nil {
+		release, ok, err := l.option.Locker.Obtain(key+":lock", l.option.LockTTL)
+		if err != nil {
+			l.errorf("failed to obtain lock of key %s: %s", key, err)
+		}
+
+		if ok {
+			defer func() {
+				if err := release(); err != nil {
+					l.errorf("failed to release lock of key %s: %s", key, err)
+				}
+			}()
+		} else if err == nil {
+			if !wait {
+				// - another instance is refreshing it
+				return nil, nil
+			}
+
+			if e := l.await(key); e != nil {
+				return e, nil
+			}
+		}
+	}
+
+	value, err := loader()
+	if err != nil && errors.Cause(err) != ErrNotFound {
+		return nil, err
+	}
+
+	e := &entry{negative: err != nil, expiresAt: time.Now().Add(ttl)}
+	expiration := ttl
+
+	if e.negative {
+		if l.option.NegativeTTL <= 0 {
+			return e, nil
+		}
+
+		e.expiresAt = time.Now().Add(l.option.NegativeTTL)
+		expiration = l.option.NegativeTTL
+	} else {
+		if e.payload, err = l.option.Codec.Marshal(value); err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal key %s", key)
+		}
+
+		if ttl > 0 && l.option.StaleTTL > 0 {
+			expiration += l.option.StaleTTL
+		}
+	}
+
+	if ttl <= 0 {
+		// - never expires
+		e.expiresAt = time.Unix(0, math.MaxInt64)
+	}
+
+	if err := l.cache.SetWithExpiration(key, e.encode(), expiration); err != nil {
+		l.errorf("failed to set key %s: %s", key, err)
+	}
+
+	return e, nil
+}
+
+// await polls key until another instance cached a fresh value or LockWait elapsed.
+func (l *Loading) await(key string) *entry {
+	deadline := time.Now().Add(l.option.LockWait)
+
+	for time.Now().Before(deadline) {
+		time.Sleep(DefaultLockRetry)
+
+		if e, ok := l.get(key); ok && time.Now().Before(e.expiresAt) {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func (l *Loading) errorf(format string, args ...interface{}) {
+	if l.option.Logger != nil {
+		l.option.Logger.Errorf(format, args...)
+	}
+}
+
+// encode lays the entry out as a flag byte, the expiry in unix nanoseconds and the payload.
+func (e *entry) encode() []byte {
+	data := make([]byte, headerSize, headerSize+len(e.payload))
+	if e.negative {
+		data[0] = negativeFlag
+	}
+
+	binary.BigEndian.PutUint64(data[1:headerSize], uint64(e.expiresAt.UnixNano()))
+	return append(data, e.payload...)
+}
+
+func decodeEntry(data []byte) (*entry, error) {
+	if len(data) < headerSize {
+		return nil, errors.New("entry is too short")
+	}
+
+	return &entry{
+		negative:  data[0] == negativeFlag,
+		expiresAt: time.Unix(0, int64(binary.BigEndian.Uint64(data[1:headerSize]))),
+		payload:   data[headerSize:],
+	}, nil
+}
+
+// NewRedisLocker returns a Locker backed by SET NX on client, a lock is only released by its owner.
+func NewRedisLocker(client redis.Cmdable) Locker {
+	return &redisLocker{client: client}
+}
+
+func (r *redisLocker) Obtain(key string, ttl time.Duration) (func() error, bool, error) {
+	token := fmt.Sprintf("%d-%d", time.Now().UnixNano(), rand.Int63())
+
+	ok, err := r.client.SetNX(key, token, ttl).Result()
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to obtain lock %s", key)
+	}
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	return func() error {
+		return releaseScript.Run(r.client, []string{key}, token).Err()
+	}, true, nil
+}
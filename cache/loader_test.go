@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/codec"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+type (
+	mapCache struct {
+		Cache
+		mu   sync.Mutex
+		data map[string][]byte
+	}
+
+	rate struct {
+		Price int `json:"price"`
+	}
+)
+
+func newMapCache() *mapCache {
+	return &mapCache{data: make(map[string][]byte)}
+}
+
+func (m *mapCache) SetWithExpiration(key string, value interface{}, ttl time.Duration) error {
+	data, err := codec.Binary.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = data
+	return nil
+}
+
+func (m *mapCache) Get(key string, value interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, ok := m.data[key]
+	if !ok {
+		return errors.Wrapf(redis.Nil, "key %s does not exits", key)
+	}
+	return codec.Binary.Unmarshal(data, value)
+}
+
+func Test_GetOrLoad_deduplicates_concurrent_loads(t *testing.T) {
+	loading, _ := NewLoading(newMapCache(), nil)
+
+	var loads int32
+	loader := func() (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		time.Sleep(20 * time.Millisecond)
+		return rate{Price: 100}, nil
+	}
+
+	wg := sync.WaitGroup{}
+	for n := 0; n < 20; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			r := rate{}
+			if err := loading.GetOrLoad("rate:1", time.Minute, &r, loader); err != nil || r.Price != 100 {
+				t.Errorf("unexpected rate %+v %v", r, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if loads != 1 {
+		t.Errorf("should load once, loaded %d times", loads)
+	}
+}
+
+func Test_GetOrLoad_caches_negative_results(t *testing.T) {
+	loading, _ := NewLoading(newMapCache(), &LoadOption{NegativeTTL: time.Minute})
+
+	var loads int32
+	loader := func() (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		return nil, ErrNotFound
+	}
+
+	for n := 0; n < 2; n++ {
+		if err := loading.GetOrLoad("rate:2", time.Minute, &rate{}, loader); err != ErrNotFound {
+			t.Errorf("should return ErrNotFound, got %v", err)
+		}
+	}
+
+	if loads != 1 {
+		t.Errorf("should cache the negative result, loaded %d times", loads)
+	}
+}
+
+func Test_GetOrLoad_serves_stale_value_while_refreshing(t *testing.T) {
+	loading, _ := NewLoading(newMapCache(), &LoadOption{StaleTTL: time.Minute})
+
+	var price int32 = 100
+	loader := func() (interface{}, error) {
+		return rate{Price: int(atomic.AddInt32(&price, 1))}, nil
+	}
+
+	r := rate{}
+	if err := loading.GetOrLoad("rate:3", time.Millisecond, &r, loader); err != nil || r.Price != 101 {
+		t.Fatalf("unexpected rate %+v %v", r, err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if err := loading.GetOrLoad("rate:3", time.Minute, &r, loader); err != nil || r.Price != 101 {
+		t.Fatalf("should serve the stale rate, got %+v %v", r, err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if err := loading.GetOrLoad("rate:3", time.Minute, &r, loader); err != nil || r.Price != 102 {
+		t.Errorf("should serve the refreshed rate, got %+v %v", r, err)
+	}
+}
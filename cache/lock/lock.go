@@ -0,0 +1,349 @@
+// Package lock provides distributed locks on the redis caches, on a single node or with Redlock
+// across independent nodes.
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultTTL           = 10 * time.Second
+	DefaultRetryInterval = 100 * time.Millisecond
+	DefaultPrefix        = "lock:"
+	DefaultFenceTTL      = 7 * 24 * time.Hour
+)
+
+var (
+	ErrNotAcquired = errors.New("lock: not acquired")
+	ErrNotHeld     = errors.New("lock: not held")
+
+	// - the lock and its fencing counter share a hash tag so they live in the same cluster slot, the
+	//   counter lease is renewed on every acquisition so idle keys don't leave counters behind forever
+	acquireScript = redis.NewScript(`
+if redis.call("set", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	local fence = redis.call("incr", KEYS[2])
+	redis.call("pexpire", KEYS[2], ARGV[3])
+	return fence
+end
+return 0`)
+
+	releaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0`)
+
+	extendScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0`)
+)
+
+type (
+	Option struct {
+		// TTL is the lease of a lock, it expires unless released, extended or auto renewed.
+		TTL time.Duration
+		// RetryInterval is how often Acquire retries a held lock.
+		RetryInterval time.Duration
+		// AutoRenew extends the lease every TTL/3 until the lock is released.
+		AutoRenew bool
+		// Prefix is prepended to every lock key.
+		Prefix string
+		// FenceTTL is how long the fencing counter of a key outlives its last acquisition, it is
+		// never shorter than TTL. Tokens only increase within that window: a key left unlocked for
+		// longer starts over at 1, so a resource must not keep the last token it accepted longer.
+		FenceTTL time.Duration
+	}
+
+	Locker interface {
+		// Acquire blocks until the lock on key is acquired or ctx is done.
+		Acquire(ctx context.Context, key string) (*Lock, error)
+		// TryAcquire returns ErrNotAcquired when the lock on key is held.
+		TryAcquire(key string) (*Lock, error)
+		// Obtain implements cache.Locker.
+		Obtain(key string, ttl time.Duration) (func() error, bool, error)
+	}
+
+	locker struct {
+		nodes  []redis.Cmdable
+		quorum int
+		option Option
+	}
+
+	// Lock is a held lock, Token is its fencing token: it increases with every acquisition of the key
+	// within Option.FenceTTL, so a resource can reject writes carrying a token lower than the last it accepted.
+	Lock struct {
+		locker *locker
+		key    string
+		value  string
+		token  int64
+		ttl    time.Duration
+
+		mu      sync.Mutex
+		stop    chan struct{}
+		lost    chan struct{}
+		stopped bool
+	}
+)
+
+// New returns a Locker on a single redis, redis-cluster or redis-universal cache.
+func New(c cache.Cache, option *Option) (Locker, error) {
+	return NewRedlock([]cache.Cache{c}, option)
+}
+
+// NewRedlock returns a Locker implementing Redlock: a lock is held when it is acquired on a majority
+// of the independent nodes within its TTL. Fencing tokens are the highest counter among the majority,
+// which only orders acquisitions as long as a majority of the nodes keep their data.
+func NewRedlock(nodes []cache.Cache, option *Option) (Locker, error) {
+	if len(nodes) == 0 {
+		return nil, errors.New("at least one node is required!")
+	}
+
+	clients := make([]redis.Cmdable, 0, len(nodes))
+	for n, node := range nodes {
+		if node == nil {
+			return nil, errors.Errorf("node %d is nil", n)
+		}
+
+		client, ok := cache.Cmdable(node)
+		if !ok {
+			return nil, errors.Errorf("node %d is not a redis cache", n)
+		}
+		clients = append(clients, client)
+	}
+
+	opt := Option{}
+	if option != nil {
+		opt = *option
+	}
+
+	if opt.TTL <= 0 {
+		opt.TTL = DefaultTTL
+	}
+
+	if opt.RetryInterval <= 0 {
+		opt.RetryInterval = DefaultRetryInterval
+	}
+
+	if opt.Prefix == "" {
+		opt.Prefix = DefaultPrefix
+	}
+
+	if opt.FenceTTL <= 0 {
+		opt.FenceTTL = DefaultFenceTTL
+	}
+
+	return &locker{nodes: clients, quorum: len(clients)/2 + 1, option: opt}, nil
+}
+
+func (l *locker) Acquire(ctx context.Context, key string) (*Lock, error) {
+	ticker := time.NewTicker(l.option.RetryInterval)
+	defer ticker.Stop()
+
+	for {
+		lock, err := l.TryAcquire(key)
+		if err != ErrNotAcquired {
+			return lock, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrapf(ctx.Err(), "failed to acquire lock %s", key)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *locker) TryAcquire(key string) (*Lock, error) {
+	return l.acquire(key, l.option.TTL, l.option.AutoRenew)
+}
+
+func (l *locker) Obtain(key string, ttl time.Duration) (func() error, bool, error) {
+	lock, err := l.acquire(key, ttl, false)
+	if err == ErrNotAcquired {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return lock.Release, true, nil
+}
+
+func (l *locker) acquire(key string, ttl time.Duration, renew bool) (*Lock, error) {
+	value, err := token()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	keys := []string{l.key(key), l.key(key) + ":fence"}
+
+	fenceTTL := l.option.FenceTTL
+	if fenceTTL < ttl {
+		fenceTTL = ttl
+	}
+
+	var (
+		acquired int
+		fence    int64
+		failure  error
+	)
+
+	for _, node := range l.nodes {
+		n, err := acquireScript.Run(node, keys, value, int64(ttl/time.Millisecond), int64(fenceTTL/time.Millisecond)).Int64()
+		if err != nil {
+			failure = err
+			continue
+		}
+
+		if n > 0 {
+			acquired++
+			if n > fence {
+				fence = n
+			}
+		}
+	}
+
+	// - the clocks of the nodes drift, the lease is only trusted for what is left of it
+	drift := ttl/100 + 2*time.Millisecond
+	if acquired < l.quorum || time.Since(start)+drift >= ttl {
+		l.release(keys[0], value)
+
+		if failure != nil && acquired < l.quorum {
+			return nil, errors.Wrapf(failure, "failed to acquire lock %s", key)
+		}
+		return nil, ErrNotAcquired
+	}
+
+	lock := &Lock{locker: l, key: keys[0], value: value, token: fence, ttl: ttl, stop: make(chan struct{}), lost: make(chan struct{})}
+
+	if renew {
+		go lock.renew()
+	}
+
+	return lock, nil
+}
+
+// release removes the lock from every node, it reports how many nodes still held it.
+func (l *locker) release(key, value string) (int, error) {
+	var (
+		released int
+		failure  error
+	)
+
+	for _, node := range l.nodes {
+		n, err := releaseScript.Run(node, []string{key}, value).Int64()
+		if err != nil {
+			failure = err
+			continue
+		}
+		released += int(n)
+	}
+
+	return released, failure
+}
+
+func (l *locker) key(key string) string {
+	return l.option.Prefix + "{" + key + "}"
+}
+
+func token() (string, error) {
+	value := make([]byte, 16)
+	if _, err := rand.Read(value); err != nil {
+		return "", errors.Wrap(err, "failed to generate lock value")
+	}
+
+	return hex.EncodeToString(value), nil
+}
+
+// Token returns the fencing token of the lock.
+func (l *Lock) Token() int64 {
+	return l.token
+}
+
+// Lost is closed when auto renewal found the lock no longer held.
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Release releases the lock, it returns ErrNotHeld when the lease already expired.
+func (l *Lock) Release() error {
+	l.stopRenew()
+
+	released, err := l.locker.release(l.key, l.value)
+	if err != nil && released < l.locker.quorum {
+		return errors.Wrapf(err, "failed to release lock %s", l.key)
+	}
+
+	if released < l.locker.quorum {
+		return ErrNotHeld
+	}
+
+	return nil
+}
+
+// Extend resets the lease of the lock to ttl, it returns ErrNotHeld when the lease already expired.
+func (l *Lock) Extend(ttl time.Duration) error {
+	var (
+		extended int
+		failure  error
+	)
+
+	for _, node := range l.locker.nodes {
+		n, err := extendScript.Run(node, []string{l.key}, l.value, int64(ttl/time.Millisecond)).Int64()
+		if err != nil {
+			failure = err
+			continue
+		}
+		extended += int(n)
+	}
+
+	if extended >= l.locker.quorum {
+		return nil
+	}
+
+	if failure != nil {
+		return errors.Wrapf(failure, "failed to extend lock %s", l.key)
+	}
+
+	return ErrNotHeld
+}
+
+func (l *Lock) renew() {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			// - transient errors are retried on the next tick while the lease lasts
+			if err := l.Extend(l.ttl); err == ErrNotHeld {
+				close(l.lost)
+				return
+			}
+		}
+	}
+}
+
+func (l *Lock) stopRenew() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.stopped {
+		l.stopped = true
+		close(l.stop)
+	}
+}
@@ -0,0 +1,128 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/internal/cachetest"
+)
+
+func Test_TryAcquire_is_exclusive_and_increments_token(t *testing.T) {
+	c, server := cachetest.NewRedis(t)
+	defer server.Close()
+
+	locker, _ := New(c, nil)
+
+	first, err := locker.TryAcquire("booking:1")
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if _, err := locker.TryAcquire("booking:1"); err != ErrNotAcquired {
+		t.Errorf("should not acquire a held lock, got %v", err)
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	second, err := locker.TryAcquire("booking:1")
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if second.Token() <= first.Token() {
+		t.Errorf("token should increase, got %d after %d", second.Token(), first.Token())
+	}
+
+	if err := first.Release(); err != ErrNotHeld {
+		t.Errorf("should not release a lock held by another owner, got %v", err)
+	}
+}
+
+func Test_TryAcquire_renews_the_fence_lease(t *testing.T) {
+	c, server := cachetest.NewRedis(t)
+	defer server.Close()
+
+	locker, _ := New(c, &Option{FenceTTL: time.Hour})
+
+	first, err := locker.TryAcquire("booking:1")
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+	_ = first.Release()
+
+	if ttl := server.TTL("lock:{booking:1}:fence"); ttl != time.Hour {
+		t.Errorf("fence should expire after an hour, got %s", ttl)
+	}
+
+	server.FastForward(59 * time.Minute)
+
+	second, err := locker.TryAcquire("booking:1")
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+	_ = second.Release()
+
+	if ttl := server.TTL("lock:{booking:1}:fence"); second.Token() != 2 || ttl != time.Hour {
+		t.Errorf("acquire should renew the fence, got token %d and ttl %s", second.Token(), ttl)
+	}
+
+	server.FastForward(time.Hour)
+
+	if server.Exists("lock:{booking:1}:fence") {
+		t.Errorf("fence of an idle key should expire")
+	}
+}
+
+func Test_Acquire_waits_for_expired_lease(t *testing.T) {
+	c, server := cachetest.NewRedis(t)
+	defer server.Close()
+
+	locker, _ := New(c, &Option{TTL: time.Second, RetryInterval: 10 * time.Millisecond})
+
+	if _, err := locker.TryAcquire("job"); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		server.FastForward(time.Second)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := locker.Acquire(ctx, "job"); err != nil {
+		t.Errorf("should acquire after the lease expired, got %v", err)
+	}
+}
+
+func Test_Extend_and_Redlock_quorum(t *testing.T) {
+	first, one := cachetest.NewRedis(t)
+	defer one.Close()
+
+	second, two := cachetest.NewRedis(t)
+	defer two.Close()
+
+	third, server := cachetest.NewRedis(t)
+
+	locker, _ := NewRedlock([]cache.Cache{first, second, third}, &Option{TTL: time.Second})
+
+	server.Close()
+
+	lock, err := locker.TryAcquire("payment")
+	if err != nil {
+		t.Fatalf("should acquire on a majority, got %v", err)
+	}
+
+	if err := lock.Extend(2 * time.Second); err != nil {
+		t.Errorf("should extend on a majority, got %v", err)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Errorf("should release on a majority, got %v", err)
+	}
+}
@@ -0,0 +1,932 @@
+// Package memory implements cache.Cache in-process, for tests and local development without redis.
+// Values are encoded by the codec like the redis caches, and misses wrap redis.Nil the same way.
+package memory
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/codec"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultCleanupInterval = time.Minute
+
+	stringKind kind = iota
+	hashKind
+	zsetKind
+	listKind
+	setKind
+	// hllKind is a HyperLogLog, kept as a set of its elements.
+	hllKind
+)
+
+var (
+	ErrWrongType  = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	ErrClosed     = errors.New("memory cache is closed")
+	ErrNotInteger = errors.New("ERR value is not an integer or out of range")
+	// ErrScriptNotSupported is the error of RunScript, the memory cache has no Lua interpreter.
+	ErrScriptNotSupported = errors.New("lua scripts are not supported by the memory cache")
+)
+
+type (
+	kind int
+
+	Option struct {
+		// Codec encodes the values of Set, HSet, HMSet and the Pipe, defaults to codec.Binary.
+		Codec codec.Codec
+		// CleanupInterval is how often expired keys are evicted, they are never served once expired.
+		CleanupInterval time.Duration
+	}
+
+	item struct {
+		kind      kind
+		value     []byte
+		hash      map[string][]byte
+		zset      map[string]float64
+		list      [][]byte
+		set       map[string]struct{}
+		expiresAt time.Time
+	}
+
+	memoryClient struct {
+		mu       sync.RWMutex
+		items    map[string]*item
+		codec    codec.Codec
+		channels map[*pubsub]struct{}
+		closed   bool
+		stop     chan struct{}
+		pushed   chan struct{}
+	}
+)
+
+func New(option *Option) (cache.Cache, error) {
+	opt := Option{}
+	if option != nil {
+		opt = *option
+	}
+
+	if opt.CleanupInterval <= 0 {
+		opt.CleanupInterval = DefaultCleanupInterval
+	}
+
+	c := &memoryClient{
+		items:    make(map[string]*item),
+		codec:    codec.Default(opt.Codec),
+		channels: make(map[*pubsub]struct{}),
+		stop:     make(chan struct{}),
+		pushed:   make(chan struct{}),
+	}
+
+	go c.cleanup(opt.CleanupInterval)
+
+	return c, nil
+}
+
+func (c *memoryClient) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			now := time.Now()
+			for key, it := range c.items {
+				if it.expired(now) {
+					delete(c.items, key)
+				}
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+func (it *item) expired(now time.Time) bool {
+	return !it.expiresAt.IsZero() && !now.Before(it.expiresAt)
+}
+
+// get returns the live item of key, the caller holds the lock.
+func (c *memoryClient) get(key string) (*item, bool) {
+	it, ok := c.items[key]
+	if !ok || it.expired(time.Now()) {
+		return nil, false
+	}
+
+	return it, true
+}
+
+// typed returns the live item of key when it holds k, the caller holds the lock.
+func (c *memoryClient) typed(key string, k kind) (*item, bool, error) {
+	it, ok := c.get(key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	if it.kind != k {
+		return nil, false, ErrWrongType
+	}
+
+	return it, true, nil
+}
+
+// hash returns the hash of key, creating it when missing, the caller holds the write lock.
+func (c *memoryClient) hash(key string) (*item, error) {
+	it, ok, err := c.typed(key, hashKind)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		it = &item{kind: hashKind, hash: make(map[string][]byte)}
+		c.items[key] = it
+	}
+
+	return it, nil
+}
+
+// expire sets the ttl of key like EXPIRE, a ttl that isn't positive removes the key. It reports
+// whether key exists.
+func (c *memoryClient) expire(key string, ttl time.Duration) bool {
+	it, ok := c.get(key)
+	if !ok {
+		return false
+	}
+
+	if ttl <= 0 {
+		delete(c.items, key)
+		return true
+	}
+
+	it.expiresAt = time.Now().Add(ttl)
+	return true
+}
+
+func (c *memoryClient) check() error {
+	if c.closed {
+		return ErrClosed
+	}
+
+	return nil
+}
+
+func (c *memoryClient) Ping() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return errors.WithStack(c.check())
+}
+
+func (c *memoryClient) SetWithExpiration(key string, value interface{}, duration time.Duration) error {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal cache with key %s!", key)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return err
+	}
+
+	c.set(key, data, duration)
+	return nil
+}
+
+// set stores a string like SET, the caller holds the write lock.
+func (c *memoryClient) set(key string, data []byte, duration time.Duration) {
+	it := &item{kind: stringKind, value: append([]byte(nil), data...)}
+	if duration > 0 {
+		it.expiresAt = time.Now().Add(duration)
+	}
+
+	c.items[key] = it
+}
+
+func (c *memoryClient) Set(key string, value interface{}) error {
+	return c.SetWithExpiration(key, value, 0)
+}
+
+func (c *memoryClient) Get(key string, data interface{}) error {
+	val, err := c.bytes(key)
+	if err != nil {
+		return err
+	}
+
+	if err := c.codec.Unmarshal(val, data); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+func (c *memoryClient) bytes(key string) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	return c.value(key)
+}
+
+// value returns the string at key like GET, the caller holds the lock.
+func (c *memoryClient) value(key string) ([]byte, error) {
+	it, ok, err := c.typed(key, stringKind)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get key %s!", key)
+	}
+
+	if !ok {
+		return nil, errors.Wrapf(redis.Nil, "key %s does not exits", key)
+	}
+
+	return it.value, nil
+}
+
+func (c *memoryClient) Incr(key string) (int64, error) {
+	return c.IncrBy(key, 1)
+}
+
+func (c *memoryClient) Decr(key string) (int64, error) {
+	return c.IncrBy(key, -1)
+}
+
+func (c *memoryClient) IncrBy(key string, value int64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+
+	val, err := c.incrBy(key, value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to increment key %s!", key)
+	}
+
+	return val, nil
+}
+
+// incrBy increments key like INCRBY keeping its ttl, the caller holds the write lock.
+func (c *memoryClient) incrBy(key string, value int64) (int64, error) {
+	it, ok, err := c.typed(key, stringKind)
+	if err != nil {
+		return 0, err
+	}
+
+	var current int64
+	if ok {
+		if current, err = strconv.ParseInt(string(it.value), 10, 64); err != nil {
+			return 0, ErrNotInteger
+		}
+	} else {
+		it = &item{kind: stringKind}
+		c.items[key] = it
+	}
+
+	current += value
+	it.value = strconv.AppendInt(nil, current, 10)
+	return current, nil
+}
+
+func (c *memoryClient) SetNX(key string, value interface{}, ttl time.Duration) (bool, error) {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to marshal cache with key %s!", key)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return false, err
+	}
+
+	return c.setNX(key, data, ttl), nil
+}
+
+// setNX sets key unless it exists, the caller holds the write lock.
+func (c *memoryClient) setNX(key string, data []byte, ttl time.Duration) bool {
+	if _, ok := c.get(key); ok {
+		return false
+	}
+
+	c.set(key, data, ttl)
+	return true
+}
+
+func (c *memoryClient) Expire(key string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return false, err
+	}
+
+	return c.expire(key, ttl), nil
+}
+
+func (c *memoryClient) TTL(key string) (time.Duration, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+
+	return c.ttl(key), nil
+}
+
+// ttl returns the ttl of key like PTTL, the caller holds the lock.
+func (c *memoryClient) ttl(key string) time.Duration {
+	it, ok := c.get(key)
+	if !ok {
+		return cache.NotExists
+	}
+
+	if it.expiresAt.IsZero() {
+		return cache.NoExpiration
+	}
+
+	return time.Until(it.expiresAt).Truncate(time.Millisecond)
+}
+
+func (c *memoryClient) Exists(keys ...string) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+
+	return c.exists(keys...), nil
+}
+
+// exists counts the live keys like EXISTS, a key is counted as many times as it is given.
+func (c *memoryClient) exists(keys ...string) int64 {
+	var count int64
+	for _, key := range keys {
+		if _, ok := c.get(key); ok {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (c *memoryClient) GetSet(key string, value interface{}, object interface{}) error {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal cache with key %s!", key)
+	}
+
+	val, ok, err := c.getSet(key, data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to getset key %s!", key)
+	}
+
+	if !ok {
+		return errors.Wrapf(redis.Nil, "key %s does not exits", key)
+	}
+
+	if err := c.codec.Unmarshal(val, object); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+func (c *memoryClient) getSet(key string, data []byte) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return nil, false, err
+	}
+
+	return c.swap(key, data)
+}
+
+// swap sets key like GETSET and returns its previous value, the caller holds the write lock.
+func (c *memoryClient) swap(key string, data []byte) ([]byte, bool, error) {
+	it, ok, err := c.typed(key, stringKind)
+	if err != nil {
+		return nil, false, err
+	}
+
+	c.set(key, data, 0)
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	return it.value, true, nil
+}
+
+func (c *memoryClient) Keys(pattern string) ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return []string{}, err
+	}
+
+	return c.keys(pattern), nil
+}
+
+// Scan iterates a snapshot of the keys matching pattern.
+func (c *memoryClient) Scan(pattern string, count int64) cache.Iterator {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return cache.NewKeysIterator(nil, err)
+	}
+
+	return cache.NewKeysIterator(c.keys(pattern), nil)
+}
+
+// keys returns the live keys matching pattern in order, the caller holds the lock.
+func (c *memoryClient) keys(pattern string) []string {
+	keys := make([]string, 0)
+	now := time.Now()
+
+	for key, it := range c.items {
+		if !it.expired(now) && match(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *memoryClient) Remove(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return err
+	}
+
+	c.remove(key)
+	return nil
+}
+
+// remove deletes key like DEL and reports whether it existed, the caller holds the write lock.
+func (c *memoryClient) remove(key string) int64 {
+	_, ok := c.get(key)
+	delete(c.items, key)
+
+	if !ok {
+		return 0
+	}
+
+	return 1
+}
+
+func (c *memoryClient) RemoveByPattern(pattern string, countPerLoop int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return err
+	}
+
+	for _, key := range c.keys(pattern) {
+		delete(c.items, key)
+	}
+
+	return nil
+}
+
+func (c *memoryClient) FlushDatabase() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return err
+	}
+
+	c.items = make(map[string]*item)
+	return nil
+}
+
+func (c *memoryClient) FlushAll() error {
+	return c.FlushDatabase()
+}
+
+func (c *memoryClient) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+
+	c.closed = true
+	close(c.stop)
+
+	channels := c.channels
+	c.channels = make(map[*pubsub]struct{})
+	c.mu.Unlock()
+
+	for p := range channels {
+		p.close()
+	}
+
+	return nil
+}
+
+func (c *memoryClient) SetZSetWithExpiration(key string, duration time.Duration, data ...redis.Z) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.setZSet(key, data...); err != nil {
+		return err
+	}
+
+	c.expire(key, duration)
+	return nil
+}
+
+func (c *memoryClient) SetZSet(key string, data ...redis.Z) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.setZSet(key, data...)
+}
+
+// setZSet replaces key with a sorted set of data, the caller holds the write lock.
+func (c *memoryClient) setZSet(key string, data ...redis.Z) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+
+	delete(c.items, key)
+
+	members := make(map[string]float64, len(data))
+	for _, z := range data {
+		member, err := codec.Binary.Marshal(z.Member)
+		if err != nil {
+			return errors.Wrapf(err, "failed to zadd cache with key %s!", key)
+		}
+		members[string(member)] = z.Score
+	}
+
+	if len(members) > 0 {
+		c.items[key] = &item{kind: zsetKind, zset: members}
+	}
+
+	return nil
+}
+
+func (c *memoryClient) GetZSet(key string) ([]redis.Z, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	it, ok, err := c.typed(key, zsetKind)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run zrange command")
+	}
+
+	if !ok {
+		return nil, errors.Errorf("key %s does not exits", key)
+	}
+
+	return sorted(it.zset), nil
+}
+
+func (c *memoryClient) HMSetWithExpiration(key string, value map[string]interface{}, ttl time.Duration) error {
+	if err := c.HMSet(key, value); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire(key, ttl)
+	return nil
+}
+
+func (c *memoryClient) HMSet(key string, value map[string]interface{}) error {
+	fields, err := c.marshalFields(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to HMSet cache with key %s!", key)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return err
+	}
+
+	if err := c.hset(key, fields); err != nil {
+		return errors.Wrapf(err, "failed to HMSet cache with key %s!", key)
+	}
+
+	return nil
+}
+
+// hset sets fields of the hash at key like HMSET, the caller holds the write lock.
+func (c *memoryClient) hset(key string, fields map[string][]byte) error {
+	it, err := c.hash(key)
+	if err != nil {
+		return err
+	}
+
+	for field, data := range fields {
+		it.hash[field] = data
+	}
+
+	return nil
+}
+
+func (c *memoryClient) HSetWithExpiration(key, field string, value interface{}, ttl time.Duration) error {
+	if err := c.HSet(key, field, value); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire(key, ttl)
+	return nil
+}
+
+func (c *memoryClient) HSet(key, field string, value interface{}) error {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal field %s of key %s!", field, key)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return err
+	}
+
+	if err := c.hset(key, map[string][]byte{field: append([]byte(nil), data...)}); err != nil {
+		return errors.Wrapf(err, "failed to HSet cache with key %s!", key)
+	}
+
+	return nil
+}
+
+func (c *memoryClient) HMGet(key string, fields ...string) ([]interface{}, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	return c.hmget(key, fields...)
+}
+
+// hmget returns fields of the hash at key like HMGET, the caller holds the lock.
+func (c *memoryClient) hmget(key string, fields ...string) ([]interface{}, error) {
+	it, ok, err := c.typed(key, hashKind)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get key %s!", key)
+	}
+
+	values := make([]interface{}, len(fields))
+	for n, field := range fields {
+		if !ok {
+			continue
+		}
+
+		if data, found := it.hash[field]; found {
+			values[n] = string(data)
+		}
+	}
+
+	return values, nil
+}
+
+func (c *memoryClient) HGetAll(key string) (map[string]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	return c.hgetAll(key)
+}
+
+// hgetAll returns the hash at key like HGETALL, the caller holds the lock.
+func (c *memoryClient) hgetAll(key string) (map[string]string, error) {
+	it, ok, err := c.typed(key, hashKind)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get key %s!", key)
+	}
+
+	values := make(map[string]string)
+	if ok {
+		for field, data := range it.hash {
+			values[field] = string(data)
+		}
+	}
+
+	return values, nil
+}
+
+func (c *memoryClient) HGet(key, field string, response interface{}) error {
+	val, err := c.field(key, field)
+	if err != nil {
+		return err
+	}
+
+	if err := c.codec.Unmarshal(val, response); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal field %s of key %s!", field, key)
+	}
+
+	return nil
+}
+
+func (c *memoryClient) field(key, field string) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	return c.hget(key, field)
+}
+
+// hget returns field of the hash at key like HGET, the caller holds the lock.
+func (c *memoryClient) hget(key, field string) ([]byte, error) {
+	it, ok, err := c.typed(key, hashKind)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get key %s!", key)
+	}
+
+	if ok {
+		if data, found := it.hash[field]; found {
+			return data, nil
+		}
+	}
+
+	return nil, errors.Wrapf(redis.Nil, "key %s does not exits", key)
+}
+
+func (c *memoryClient) MGet(key []string) ([]interface{}, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	return c.mget(key...), nil
+}
+
+// mget returns the strings at key like MGET, the caller holds the lock.
+func (c *memoryClient) mget(key ...string) []interface{} {
+	// - like MGET, keys holding another kind are returned as nil
+	values := make([]interface{}, len(key))
+	for n, k := range key {
+		if it, ok, err := c.typed(k, stringKind); ok && err == nil {
+			values[n] = string(it.value)
+		}
+	}
+
+	return values
+}
+
+func (c *memoryClient) MGetInto(keys []string, results interface{}) error {
+	values, err := c.MGet(keys)
+	if err != nil {
+		return err
+	}
+
+	if err := codec.UnmarshalAll(c.codec, values, results); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal keys %s!", keys)
+	}
+
+	return nil
+}
+
+func (c *memoryClient) marshalFields(value map[string]interface{}) (map[string][]byte, error) {
+	fields := make(map[string][]byte, len(value))
+	for field, v := range value {
+		data, err := c.codec.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal field %s", field)
+		}
+		fields[field] = append([]byte(nil), data...)
+	}
+
+	return fields, nil
+}
+
+func (c *memoryClient) Client() cache.Cache {
+	return c
+}
+
+func (c *memoryClient) Pipeline() cache.Pipe {
+	return &pipe{c: c}
+}
+
+func (c *memoryClient) Subscribe(channels ...string) (cache.PubSub, error) {
+	return c.subscribe(channels, nil)
+}
+
+func (c *memoryClient) PSubscribe(patterns ...string) (cache.PubSub, error) {
+	return c.subscribe(nil, patterns)
+}
+
+func (c *memoryClient) subscribe(channels, patterns []string) (cache.PubSub, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	p := newPubSub(c)
+	p.channels = add(p.channels, channels)
+	p.patterns = add(p.patterns, patterns)
+	c.channels[p] = struct{}{}
+	return p, nil
+}
+
+func (c *memoryClient) Publish(channel, message string) error {
+	if err := c.publish(channel, message); err != nil {
+		return errors.Wrapf(err, "failed to publish message to cn %s", channel)
+	}
+
+	return nil
+}
+
+// publish fans message out to every subscriber of channel, once per matching channel and pattern
+// like redis.
+func (c *memoryClient) publish(channel, message string) error {
+	type delivery struct {
+		p       *pubsub
+		message *redis.Message
+	}
+
+	c.mu.RLock()
+	if err := c.check(); err != nil {
+		c.mu.RUnlock()
+		return err
+	}
+
+	deliveries := make([]delivery, 0)
+	for p := range c.channels {
+		for _, cn := range p.channels {
+			if cn == channel {
+				deliveries = append(deliveries, delivery{p: p, message: &redis.Message{Channel: channel, Payload: message}})
+			}
+		}
+
+		for _, pattern := range p.patterns {
+			if match(pattern, channel) {
+				deliveries = append(deliveries, delivery{p: p, message: &redis.Message{Channel: channel, Pattern: pattern, Payload: message}})
+			}
+		}
+	}
+	c.mu.RUnlock()
+
+	for _, d := range deliveries {
+		d.p.deliver(d.message)
+	}
+
+	return nil
+}
+
+func (c *memoryClient) unsubscribe(p *pubsub) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.channels, p)
+}
+
+// RegisterScript is a no-op, RunScript always fails with ErrScriptNotSupported.
+func (c *memoryClient) RegisterScript(name, src string) {}
+
+func (c *memoryClient) RunScript(name string, keys []string, args ...interface{}) *cache.ScriptResult {
+	r := cache.NewScriptResult(c.codec)
+	r.Resolve(nil, errors.WithStack(ErrScriptNotSupported))
+
+	return r
+}
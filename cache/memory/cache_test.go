@@ -0,0 +1,433 @@
+package memory
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+func Test_Get_expires_keys(t *testing.T) {
+	c, _ := New(nil)
+	defer c.Close()
+
+	if err := c.SetWithExpiration("hotel", "Ayana", 20*time.Millisecond); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	name := ""
+	if err := c.Get("hotel", &name); err != nil || name != "Ayana" {
+		t.Fatalf("unexpected hotel %s %v", name, err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if err := c.Get("hotel", &name); errors.Cause(err) != redis.Nil {
+		t.Errorf("should miss expired key, got %v", err)
+	}
+
+	if keys, _ := c.Keys("*"); len(keys) != 0 {
+		t.Errorf("should not list expired key, got %v", keys)
+	}
+}
+
+func Test_Hash_and_ZSet(t *testing.T) {
+	c, _ := New(nil)
+	defer c.Close()
+
+	if err := c.HMSet("hotel:1", map[string]interface{}{"name": "Ayana", "star": 5}); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	star := 0
+	if err := c.HGet("hotel:1", "star", &star); err != nil || star != 5 {
+		t.Errorf("unexpected star %d %v", star, err)
+	}
+
+	values, _ := c.HMGet("hotel:1", "name", "city")
+	if !reflect.DeepEqual(values, []interface{}{"Ayana", nil}) {
+		t.Errorf("unexpected values %v", values)
+	}
+
+	if err := c.Get("hotel:1", &star); errors.Cause(err) != ErrWrongType {
+		t.Errorf("should not get a hash, got %v", err)
+	}
+
+	if err := c.SetZSet("rank", redis.Z{Score: 2, Member: "b"}, redis.Z{Score: 1, Member: "a"}, redis.Z{Score: 2, Member: 1}); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	z, _ := c.GetZSet("rank")
+	expected := []redis.Z{{Score: 1, Member: "a"}, {Score: 2, Member: "1"}, {Score: 2, Member: "b"}}
+	if !reflect.DeepEqual(z, expected) {
+		t.Errorf("unexpected zset %v", z)
+	}
+}
+
+func Test_Counters_and_TTL(t *testing.T) {
+	c, _ := New(nil)
+	defer c.Close()
+
+	if n, _ := c.Incr("quota"); n != 1 {
+		t.Errorf("unexpected quota %d", n)
+	}
+
+	if n, _ := c.IncrBy("quota", 5); n != 6 {
+		t.Errorf("unexpected quota %d", n)
+	}
+
+	if ttl, _ := c.TTL("quota"); ttl != cache.NoExpiration {
+		t.Errorf("unexpected ttl %s", ttl)
+	}
+
+	if ok, _ := c.Expire("quota", time.Minute); !ok {
+		t.Errorf("should expire existing key")
+	}
+
+	if ttl, _ := c.TTL("quota"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("unexpected ttl %s", ttl)
+	}
+
+	if ttl, _ := c.TTL("missing"); ttl != cache.NotExists {
+		t.Errorf("unexpected ttl %s", ttl)
+	}
+
+	if ok, _ := c.SetNX("quota", 0, 0); ok {
+		t.Errorf("should not set existing key")
+	}
+
+	previous := int64(0)
+	if err := c.GetSet("quota", 10, &previous); err != nil || previous != 6 {
+		t.Errorf("unexpected previous quota %d %v", previous, err)
+	}
+
+	if n, _ := c.Exists("quota", "missing", "quota"); n != 2 {
+		t.Errorf("unexpected exists %d", n)
+	}
+
+	_ = c.Set("hotel", "Ayana")
+	if _, err := c.Incr("hotel"); errors.Cause(err) != ErrNotInteger {
+		t.Errorf("should not increment a string, got %v", err)
+	}
+
+	p := c.Pipeline()
+	count := p.Decr("quota")
+	set := p.SetNX("lock", "owner", time.Second)
+	exists := p.Exists("lock")
+
+	if err := p.Exec(); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if count.Val() != 9 || !set.Val() || exists.Val() != 1 {
+		t.Errorf("unexpected pipeline results %d %t %d", count.Val(), set.Val(), exists.Val())
+	}
+}
+
+func Test_List_Set_and_ZSet_ranges(t *testing.T) {
+	c, _ := New(nil)
+	defer c.Close()
+
+	_, _ = c.RPush("queue", "b", "c")
+	_, _ = c.LPush("queue", "a")
+
+	items := make([]string, 0)
+	if err := c.LRange("queue", 0, -1, &items); err != nil || !reflect.DeepEqual(items, []string{"a", "b", "c"}) {
+		t.Errorf("unexpected items %v %v", items, err)
+	}
+
+	item := ""
+	if err := c.RPop("queue", &item); err != nil || item != "c" {
+		t.Errorf("unexpected item %s %v", item, err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_, _ = c.RPush("jobs", "job:1")
+	}()
+
+	if key, err := c.BLPop(time.Second, &item, "empty", "jobs"); err != nil || key != "jobs" || item != "job:1" {
+		t.Errorf("unexpected pop %s %s %v", key, item, err)
+	}
+
+	if _, err := c.BLPop(10*time.Millisecond, &item, "jobs"); errors.Cause(err) != redis.Nil {
+		t.Errorf("should time out, got %v", err)
+	}
+
+	if n, _ := c.SAdd("tags", "beach", "pool", "beach"); n != 2 {
+		t.Errorf("unexpected added %d", n)
+	}
+
+	if ok, _ := c.SIsMember("tags", "pool"); !ok {
+		t.Errorf("should be member")
+	}
+
+	_, _ = c.SRem("tags", "pool")
+	tags := make([]string, 0)
+	if err := c.SMembers("tags", &tags); err != nil || !reflect.DeepEqual(tags, []string{"beach"}) {
+		t.Errorf("unexpected tags %v %v", tags, err)
+	}
+
+	_, _ = c.ZAdd("board", redis.Z{Score: 10, Member: "a"}, redis.Z{Score: 20, Member: "b"}, redis.Z{Score: 30, Member: "c"})
+	if score, _ := c.ZIncrBy("board", 25, "a"); score != 35 {
+		t.Errorf("unexpected score %f", score)
+	}
+
+	top, _ := c.ZRevRange("board", 0, 1)
+	if !reflect.DeepEqual(top, []redis.Z{{Score: 35, Member: "a"}, {Score: 30, Member: "c"}}) {
+		t.Errorf("unexpected top %v", top)
+	}
+
+	page, _ := c.ZRangeByScore("board", redis.ZRangeBy{Min: "(20", Max: "+inf", Offset: 1, Count: 1})
+	if !reflect.DeepEqual(page, []redis.Z{{Score: 35, Member: "a"}}) {
+		t.Errorf("unexpected page %v", page)
+	}
+}
+
+func Test_Keys_and_RemoveByPattern(t *testing.T) {
+	c, _ := New(nil)
+	defer c.Close()
+
+	for _, key := range []string{"hotel:1", "hotel:2", "hotel:10", "room:1"} {
+		_ = c.Set(key, key)
+	}
+
+	for pattern, expected := range map[string][]string{
+		"hotel:?":    {"hotel:1", "hotel:2"},
+		"*:1*":       {"hotel:1", "hotel:10", "room:1"},
+		"hotel:[^1]": {"hotel:2"},
+		"[a-r]oom:*": {"room:1"},
+	} {
+		if keys, _ := c.Keys(pattern); !reflect.DeepEqual(keys, expected) {
+			t.Errorf("unexpected keys of %s: %v", pattern, keys)
+		}
+	}
+
+	if err := c.RemoveByPattern("hotel:*", 10); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if keys, _ := c.Keys("*"); !reflect.DeepEqual(keys, []string{"room:1"}) {
+		t.Errorf("unexpected keys %v", keys)
+	}
+}
+
+func Test_Pipeline_runs_on_Exec(t *testing.T) {
+	c, _ := New(nil)
+	defer c.Close()
+
+	p := c.Pipeline()
+	p.Set("hotel", "Ayana")
+	hotel := p.Get("hotel")
+	missing := p.Get("missing")
+
+	name := ""
+	if err := c.Get("hotel", &name); errors.Cause(err) != redis.Nil {
+		t.Errorf("should not set before Exec, got %v", err)
+	}
+
+	if err := hotel.Scan(&name); err != cache.ErrNotExecuted {
+		t.Errorf("should not scan before Exec, got %v", err)
+	}
+
+	if err := p.Exec(); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if err := hotel.Scan(&name); err != nil || name != "Ayana" {
+		t.Errorf("unexpected name %s %v", name, err)
+	}
+
+	if err := missing.Scan(&name); errors.Cause(err) != redis.Nil {
+		t.Errorf("should miss, got %v", err)
+	}
+}
+
+func Test_Watch_fails_on_changed_keys(t *testing.T) {
+	c, _ := New(nil)
+	defer c.Close()
+
+	_ = c.Set("stock", 1)
+
+	err := c.Watch(func(tx cache.Tx) error {
+		stock := 0
+		if err := tx.Get("stock", &stock); err != nil {
+			return err
+		}
+
+		_ = c.Set("stock", 0)
+
+		p := tx.Pipeline()
+		set := p.Set("stock", stock-1)
+		if err := p.Exec(); errors.Cause(err) != cache.ErrTxFailed {
+			t.Errorf("should fail the transaction, got %v", err)
+		}
+
+		if errors.Cause(set.Err()) != cache.ErrTxFailed {
+			t.Errorf("should fail the queued command, got %v", set.Err())
+		}
+		return nil
+	}, "stock")
+
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	err = c.Watch(func(tx cache.Tx) error {
+		p := tx.Pipeline()
+		p.Decr("stock")
+		return p.Exec()
+	}, "stock")
+
+	stock := 0
+	if err != nil || c.Get("stock", &stock) != nil || stock != -1 {
+		t.Errorf("unexpected stock %d %v", stock, err)
+	}
+}
+
+func Test_Subscribe_fans_out(t *testing.T) {
+	c, _ := New(nil)
+
+	first, _ := c.Subscribe("hotel")
+	second, _ := c.Subscribe("hotel")
+
+	if err := first.Publish("updated"); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	for _, p := range []interface{ Channel() <-chan *redis.Message }{first, second} {
+		select {
+		case message := <-p.Channel():
+			if message.Payload != "updated" || message.Channel != "hotel" {
+				t.Errorf("unexpected message %+v", message)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("should receive message")
+		}
+	}
+
+	_ = c.Close()
+
+	if _, ok := <-first.Channel(); ok {
+		t.Errorf("channel should be closed")
+	}
+}
+
+func Test_PSubscribe_and_Handle(t *testing.T) {
+	c, _ := New(nil)
+	defer c.Close()
+
+	p, _ := c.PSubscribe("hotel:*")
+	_ = p.Subscribe("room")
+
+	received := make(chan string, 3)
+	failed := make(chan string, 3)
+	go cache.Handle(p, func(message *redis.Message) error {
+		if message.Payload == "panic" {
+			panic("bad message")
+		}
+		received <- message.Pattern + " " + message.Channel
+		return nil
+	}, func(message *redis.Message, err error) {
+		failed <- message.Channel
+	})
+
+	_ = c.Publish("hotel:1", "updated")
+	_ = c.Publish("room", "panic")
+	_ = c.Publish("flight", "updated")
+	_ = c.Publish("room", "updated")
+
+	for _, expected := range []string{"hotel:* hotel:1", " room"} {
+		select {
+		case message := <-received:
+			if message != expected {
+				t.Errorf("unexpected message %s, expected %s", message, expected)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("should receive %s", expected)
+		}
+	}
+
+	if channel := <-failed; channel != "room" {
+		t.Errorf("unexpected failed message of %s", channel)
+	}
+
+	_ = p.Unsubscribe()
+	_ = c.Publish("room", "updated")
+
+	select {
+	case message := <-received:
+		t.Errorf("should not receive after Unsubscribe, got %s", message)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func Test_Geo_matches_redis(t *testing.T) {
+	c, _ := New(nil)
+	defer c.Close()
+
+	// - the example of the redis documentation
+	_, _ = c.GeoAdd("sicily",
+		&redis.GeoLocation{Name: "Palermo", Longitude: 13.361389, Latitude: 38.115556},
+		&redis.GeoLocation{Name: "Catania", Longitude: 15.087269, Latitude: 37.502669})
+
+	if dist, err := c.GeoDist("sicily", "Palermo", "Catania", "m"); err != nil || dist != 166274.1516 {
+		t.Errorf("unexpected distance %f %v", dist, err)
+	}
+
+	if _, err := c.GeoDist("sicily", "Palermo", "Agrigento", ""); errors.Cause(err) != redis.Nil {
+		t.Errorf("should miss a missing member, got %v", err)
+	}
+
+	locations, err := c.GeoRadius("sicily", 15, 37, redis.GeoRadiusQuery{Radius: 200, WithDist: true, WithCoord: true, Sort: "ASC"})
+	if err != nil || len(locations) != 2 || locations[0].Name != "Catania" || locations[0].Dist != 56.4413 ||
+		locations[1].Dist != 190.4424 || math.Abs(locations[1].Longitude-13.361389) > 1e-5 {
+		t.Errorf("unexpected locations %+v %v", locations, err)
+	}
+
+	locations, err = c.GeoSearch("sicily", cache.GeoSearchQuery{Member: "Palermo", Radius: 100, Unit: "km"})
+	if err != nil || len(locations) != 1 || locations[0].Name != "Palermo" {
+		t.Errorf("unexpected locations %+v %v", locations, err)
+	}
+
+	locations, err = c.GeoSearch("sicily", cache.GeoSearchQuery{Longitude: 15, Latitude: 37, Width: 400, Height: 400, Count: 1})
+	if err != nil || len(locations) != 1 || locations[0].Name != "Catania" {
+		t.Errorf("unexpected locations %+v %v", locations, err)
+	}
+}
+
+func Test_PFCount_counts_unique_elements(t *testing.T) {
+	c, _ := New(nil)
+	defer c.Close()
+
+	if changed, err := c.PFAdd("visitors:mon", "a", "b", "c"); err != nil || changed != 1 {
+		t.Errorf("unexpected pfadd %d %v", changed, err)
+	}
+
+	if changed, _ := c.PFAdd("visitors:mon", "a"); changed != 0 {
+		t.Errorf("should not change on a known element")
+	}
+
+	_, _ = c.PFAdd("visitors:tue", "c", "d")
+
+	if count, err := c.PFCount("visitors:mon", "visitors:tue"); err != nil || count != 4 {
+		t.Errorf("unexpected count %d %v", count, err)
+	}
+
+	if err := c.PFMerge("visitors:week", "visitors:mon", "visitors:tue"); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if count, _ := c.PFCount("visitors:week"); count != 4 {
+		t.Errorf("unexpected merged count %d", count)
+	}
+
+	_ = c.Set("hotel", "Ayana")
+	if _, err := c.PFCount("hotel"); errors.Cause(err) != ErrWrongType {
+		t.Errorf("should not count a string, got %v", err)
+	}
+}
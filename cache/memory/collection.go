@@ -0,0 +1,665 @@
+package memory
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/codec"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidScore = errors.New("ERR min or max is not a float")
+)
+
+// collection returns the item of kind k at key, creating it when missing, the caller holds the write lock.
+func (c *memoryClient) collection(key string, k kind) (*item, error) {
+	it, ok, err := c.typed(key, k)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		it = &item{kind: k}
+		switch k {
+		case setKind, hllKind:
+			it.set = make(map[string]struct{})
+		case zsetKind:
+			it.zset = make(map[string]float64)
+		}
+		c.items[key] = it
+	}
+
+	return it, nil
+}
+
+// removeEmpty removes key once its collection is empty, like redis does.
+func (c *memoryClient) removeEmpty(key string, it *item) {
+	if len(it.list) == 0 && len(it.set) == 0 && len(it.zset) == 0 {
+		delete(c.items, key)
+	}
+}
+
+func (c *memoryClient) LPush(key string, values ...interface{}) (int64, error) {
+	return c.pushValues(key, values, true)
+}
+
+func (c *memoryClient) RPush(key string, values ...interface{}) (int64, error) {
+	return c.pushValues(key, values, false)
+}
+
+func (c *memoryClient) pushValues(key string, values []interface{}, left bool) (int64, error) {
+	data, err := c.marshalValues(values)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to push key %s!", key)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+
+	val, err := c.push(key, data, left)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to push key %s!", key)
+	}
+
+	return val, nil
+}
+
+// push pushes data to the list at key like LPUSH or RPUSH, the caller holds the write lock.
+func (c *memoryClient) push(key string, data [][]byte, left bool) (int64, error) {
+	it, err := c.collection(key, listKind)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, d := range data {
+		if left {
+			it.list = append([][]byte{d}, it.list...)
+		} else {
+			it.list = append(it.list, d)
+		}
+	}
+
+	// - wakes up the BLPop waiting for a push
+	close(c.pushed)
+	c.pushed = make(chan struct{})
+
+	return int64(len(it.list)), nil
+}
+
+func (c *memoryClient) LPop(key string, object interface{}) error {
+	return c.popValue(key, object, true)
+}
+
+func (c *memoryClient) RPop(key string, object interface{}) error {
+	return c.popValue(key, object, false)
+}
+
+func (c *memoryClient) popValue(key string, object interface{}, left bool) error {
+	c.mu.Lock()
+	err := c.check()
+	var (
+		val []byte
+		ok  bool
+	)
+	if err == nil {
+		val, ok, err = c.pop(key, left)
+	}
+	c.mu.Unlock()
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to pop key %s!", key)
+	}
+
+	if !ok {
+		return errors.Wrapf(redis.Nil, "key %s does not exits", key)
+	}
+
+	if err := c.codec.Unmarshal(val, object); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+// pop pops an element of the list at key like LPOP or RPOP, the caller holds the write lock.
+func (c *memoryClient) pop(key string, left bool) ([]byte, bool, error) {
+	it, ok, err := c.typed(key, listKind)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	var val []byte
+	if left {
+		val, it.list = it.list[0], it.list[1:]
+	} else {
+		val, it.list = it.list[len(it.list)-1], it.list[:len(it.list)-1]
+	}
+
+	c.removeEmpty(key, it)
+	return val, true, nil
+}
+
+func (c *memoryClient) BLPop(timeout time.Duration, object interface{}, keys ...string) (string, error) {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		c.mu.Lock()
+		if err := c.check(); err != nil {
+			c.mu.Unlock()
+			return "", errors.Wrapf(err, "failed to blpop keys %s!", keys)
+		}
+
+		for _, key := range keys {
+			val, ok, err := c.pop(key, true)
+			if err != nil {
+				c.mu.Unlock()
+				return "", errors.Wrapf(err, "failed to blpop keys %s!", keys)
+			}
+
+			if ok {
+				c.mu.Unlock()
+				if err := c.codec.Unmarshal(val, object); err != nil {
+					return "", errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+				}
+				return key, nil
+			}
+		}
+		pushed := c.pushed
+		c.mu.Unlock()
+
+		select {
+		case <-pushed:
+		case <-c.stop:
+			return "", errors.Wrapf(ErrClosed, "failed to blpop keys %s!", keys)
+		case <-deadline:
+			return "", errors.Wrapf(redis.Nil, "keys %s are empty", keys)
+		}
+	}
+}
+
+func (c *memoryClient) LRange(key string, start, stop int64, results interface{}) error {
+	c.mu.RLock()
+	err := c.check()
+	var values []interface{}
+	if err == nil {
+		values, err = c.lrange(key, start, stop)
+	}
+	c.mu.RUnlock()
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to lrange key %s!", key)
+	}
+
+	if err := codec.UnmarshalAll(c.codec, values, results); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+// lrange returns the elements of the list at key like LRANGE, the caller holds the lock.
+func (c *memoryClient) lrange(key string, start, stop int64) ([]interface{}, error) {
+	it, ok, err := c.typed(key, listKind)
+	if err != nil || !ok {
+		return []interface{}{}, err
+	}
+
+	from, to := span(len(it.list), start, stop)
+	values := make([]interface{}, 0, to-from)
+	for _, val := range it.list[from:to] {
+		values = append(values, string(val))
+	}
+
+	return values, nil
+}
+
+func (c *memoryClient) SAdd(key string, members ...interface{}) (int64, error) {
+	data, err := c.marshalValues(members)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to sadd key %s!", key)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+
+	added, err := c.sadd(key, data)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to sadd key %s!", key)
+	}
+
+	return added, nil
+}
+
+// sadd adds data to the set at key like SADD, the caller holds the write lock.
+func (c *memoryClient) sadd(key string, data [][]byte) (int64, error) {
+	it, err := c.collection(key, setKind)
+	if err != nil {
+		return 0, err
+	}
+
+	var added int64
+	for _, d := range data {
+		if _, ok := it.set[string(d)]; !ok {
+			it.set[string(d)] = struct{}{}
+			added++
+		}
+	}
+
+	c.removeEmpty(key, it)
+	return added, nil
+}
+
+func (c *memoryClient) SRem(key string, members ...interface{}) (int64, error) {
+	data, err := c.marshalValues(members)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to srem key %s!", key)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+
+	removed, err := c.srem(key, data)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to srem key %s!", key)
+	}
+
+	return removed, nil
+}
+
+// srem removes data from the set at key like SREM, the caller holds the write lock.
+func (c *memoryClient) srem(key string, data [][]byte) (int64, error) {
+	it, ok, err := c.typed(key, setKind)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	var removed int64
+	for _, d := range data {
+		if _, ok := it.set[string(d)]; ok {
+			delete(it.set, string(d))
+			removed++
+		}
+	}
+
+	c.removeEmpty(key, it)
+	return removed, nil
+}
+
+func (c *memoryClient) SMembers(key string, results interface{}) error {
+	c.mu.RLock()
+	err := c.check()
+	var values []interface{}
+	if err == nil {
+		values, err = c.members(key)
+	}
+	c.mu.RUnlock()
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to get members of key %s!", key)
+	}
+
+	if err := codec.UnmarshalAll(c.codec, values, results); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+// members returns the members of the set at key in order, the caller holds the lock.
+func (c *memoryClient) members(key string) ([]interface{}, error) {
+	it, ok, err := c.typed(key, setKind)
+	if err != nil || !ok {
+		return []interface{}{}, err
+	}
+
+	members := make([]string, 0, len(it.set))
+	for member := range it.set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	values := make([]interface{}, len(members))
+	for n, member := range members {
+		values[n] = member
+	}
+
+	return values, nil
+}
+
+func (c *memoryClient) SIsMember(key string, member interface{}) (bool, error) {
+	data, err := c.codec.Marshal(member)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to marshal member of key %s!", key)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return false, err
+	}
+
+	found, err := c.sismember(key, data)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to check member of key %s!", key)
+	}
+
+	return found, nil
+}
+
+// sismember reports whether data is a member of the set at key, the caller holds the lock.
+func (c *memoryClient) sismember(key string, data []byte) (bool, error) {
+	it, ok, err := c.typed(key, setKind)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	_, found := it.set[string(data)]
+	return found, nil
+}
+
+func (c *memoryClient) ZAdd(key string, members ...redis.Z) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+
+	added, err := c.zadd(key, members...)
+	if err != nil {
+		return added, errors.Wrapf(err, "failed to zadd cache with key %s!", key)
+	}
+
+	return added, nil
+}
+
+// zadd adds members to the sorted set at key like ZADD, the caller holds the write lock.
+func (c *memoryClient) zadd(key string, members ...redis.Z) (int64, error) {
+	it, err := c.collection(key, zsetKind)
+	if err != nil {
+		return 0, err
+	}
+	defer c.removeEmpty(key, it)
+
+	var added int64
+	for _, z := range members {
+		member, err := codec.Binary.Marshal(z.Member)
+		if err != nil {
+			return added, err
+		}
+
+		if _, ok := it.zset[string(member)]; !ok {
+			added++
+		}
+		it.zset[string(member)] = z.Score
+	}
+
+	return added, nil
+}
+
+func (c *memoryClient) ZRem(key string, members ...interface{}) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+
+	removed, err := c.zrem(key, members...)
+	if err != nil {
+		return removed, errors.Wrapf(err, "failed to zrem cache with key %s!", key)
+	}
+
+	return removed, nil
+}
+
+// zrem removes members from the sorted set at key like ZREM, the caller holds the write lock.
+func (c *memoryClient) zrem(key string, members ...interface{}) (int64, error) {
+	it, ok, err := c.typed(key, zsetKind)
+	if err != nil || !ok {
+		return 0, err
+	}
+	defer c.removeEmpty(key, it)
+
+	var removed int64
+	for _, m := range members {
+		member, err := codec.Binary.Marshal(m)
+		if err != nil {
+			return removed, err
+		}
+
+		if _, ok := it.zset[string(member)]; ok {
+			delete(it.zset, string(member))
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+func (c *memoryClient) ZRangeByScore(key string, by redis.ZRangeBy) ([]redis.Z, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	result, err := c.zrangeByScore(key, by)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run zrangebyscore command")
+	}
+
+	return result, nil
+}
+
+// zrangeByScore returns the members of the sorted set at key within by like ZRANGEBYSCORE, the
+// caller holds the lock.
+func (c *memoryClient) zrangeByScore(key string, by redis.ZRangeBy) ([]redis.Z, error) {
+	min, minExclusive, err := score(by.Min)
+	if err != nil {
+		return nil, err
+	}
+
+	max, maxExclusive, err := score(by.Max)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.zrange(key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]redis.Z, 0)
+	for _, z := range data {
+		if z.Score < min || (minExclusive && z.Score == min) || z.Score > max || (maxExclusive && z.Score == max) {
+			continue
+		}
+		result = append(result, z)
+	}
+
+	// - LIMIT is only sent with an offset or a count, a negative count returns every element
+	if by.Offset == 0 && by.Count == 0 {
+		return result, nil
+	}
+
+	if by.Offset < 0 || by.Offset >= int64(len(result)) {
+		return []redis.Z{}, nil
+	}
+
+	result = result[by.Offset:]
+	if by.Count >= 0 && by.Count < int64(len(result)) {
+		result = result[:by.Count]
+	}
+
+	return result, nil
+}
+
+func (c *memoryClient) ZRevRange(key string, start, stop int64) ([]redis.Z, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	result, err := c.zrevRange(key, start, stop)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run zrevrange command")
+	}
+
+	return result, nil
+}
+
+// zrevRange returns the members of the sorted set at key like ZREVRANGE, the caller holds the lock.
+func (c *memoryClient) zrevRange(key string, start, stop int64) ([]redis.Z, error) {
+	data, err := c.zrange(key)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+		data[i], data[j] = data[j], data[i]
+	}
+
+	from, to := span(len(data), start, stop)
+	return data[from:to], nil
+}
+
+func (c *memoryClient) ZIncrBy(key string, increment float64, member string) (float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+
+	val, err := c.zincrBy(key, increment, member)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to zincrby cache with key %s!", key)
+	}
+
+	return val, nil
+}
+
+// zincrBy increments member of the sorted set at key like ZINCRBY, the caller holds the write lock.
+func (c *memoryClient) zincrBy(key string, increment float64, member string) (float64, error) {
+	it, err := c.collection(key, zsetKind)
+	if err != nil {
+		return 0, err
+	}
+
+	it.zset[member] += increment
+	return it.zset[member], nil
+}
+
+// zrange returns the sorted set at key ordered like ZRANGE, the caller holds the lock.
+func (c *memoryClient) zrange(key string) ([]redis.Z, error) {
+	it, ok, err := c.typed(key, zsetKind)
+	if err != nil || !ok {
+		return []redis.Z{}, err
+	}
+
+	return sorted(it.zset), nil
+}
+
+// sorted orders members like ZRANGE, by score then member.
+func sorted(zset map[string]float64) []redis.Z {
+	data := make([]redis.Z, 0, len(zset))
+	for member, score := range zset {
+		data = append(data, redis.Z{Score: score, Member: member})
+	}
+
+	sort.Slice(data, func(i, j int) bool {
+		if data[i].Score != data[j].Score {
+			return data[i].Score < data[j].Score
+		}
+		return data[i].Member.(string) < data[j].Member.(string)
+	})
+
+	return data
+}
+
+// span converts the inclusive start and stop of LRANGE and ZRANGE, negative from the end, to slice bounds.
+func span(length int, start, stop int64) (int, int) {
+	n := int64(length)
+
+	if start < 0 {
+		start += n
+	}
+
+	if stop < 0 {
+		stop += n
+	}
+
+	if start < 0 {
+		start = 0
+	}
+
+	if stop >= n {
+		stop = n - 1
+	}
+
+	if start > stop {
+		return 0, 0
+	}
+
+	return int(start), int(stop + 1)
+}
+
+// score parses a bound of ZRANGEBYSCORE, "(" makes it exclusive.
+func score(bound string) (float64, bool, error) {
+	exclusive := strings.HasPrefix(bound, "(")
+	bound = strings.TrimPrefix(bound, "(")
+
+	switch bound {
+	case "-inf":
+		return math.Inf(-1), exclusive, nil
+	case "+inf", "inf":
+		return math.Inf(1), exclusive, nil
+	}
+
+	value, err := strconv.ParseFloat(bound, 64)
+	if err != nil {
+		return 0, false, ErrInvalidScore
+	}
+
+	return value, exclusive, nil
+}
+
+func (c *memoryClient) marshalValues(values []interface{}) ([][]byte, error) {
+	data := make([][]byte, 0, len(values))
+	for n, value := range values {
+		d, err := c.codec.Marshal(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal value %d", n)
+		}
+		data = append(data, append([]byte(nil), d...))
+	}
+
+	return data, nil
+}
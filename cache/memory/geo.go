@@ -0,0 +1,279 @@
+package memory
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+// The geohash of redis: 26 bits of latitude interleaved with 26 bits of longitude, stored as the
+// score of the member.
+const (
+	geoStep         = 26
+	geoLatitudeMax  = 85.05112878
+	geoLongitudeMax = 180
+	earthRadius     = 6372797.560856
+)
+
+var (
+	ErrInvalidCoordinates = errors.New("ERR invalid longitude,latitude pair")
+	ErrUnsupportedUnit    = errors.New("ERR unsupported unit provided. please use M, KM, FT, MI")
+
+	units = map[string]float64{"m": 1, "km": 1000, "ft": 0.3048, "mi": 1609.34}
+)
+
+func (c *memoryClient) GeoAdd(key string, locations ...*redis.GeoLocation) (int64, error) {
+	members := make([]redis.Z, 0, len(locations))
+	for _, location := range locations {
+		hash, err := geohash(location.Longitude, location.Latitude)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to geoadd key %s!", key)
+		}
+		members = append(members, redis.Z{Score: float64(hash), Member: location.Name})
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+
+	added, err := c.zadd(key, members...)
+	if err != nil {
+		return added, errors.Wrapf(err, "failed to geoadd key %s!", key)
+	}
+
+	return added, nil
+}
+
+func (c *memoryClient) GeoRadius(key string, longitude, latitude float64, query redis.GeoRadiusQuery) ([]redis.GeoLocation, error) {
+	val, err := c.geoSearch(key, cache.GeoSearchQuery{
+		Longitude:   longitude,
+		Latitude:    latitude,
+		Radius:      query.Radius,
+		Unit:        query.Unit,
+		WithCoord:   query.WithCoord,
+		WithDist:    query.WithDist,
+		WithGeoHash: query.WithGeoHash,
+		Count:       query.Count,
+		Sort:        query.Sort,
+	}, true)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to georadius key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *memoryClient) GeoSearch(key string, query cache.GeoSearchQuery) ([]redis.GeoLocation, error) {
+	if query.Radius <= 0 && (query.Width <= 0 || query.Height <= 0) {
+		return nil, errors.Wrapf(errors.New("radius or width and height are required!"), "failed to geosearch key %s!", key)
+	}
+
+	val, err := c.geoSearch(key, query, query.Radius > 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to geosearch key %s!", key)
+	}
+
+	return val, nil
+}
+
+// geoSearch returns the members of key within the radius of query, or within its box, like
+// GEOSEARCH. Coordinates and distances are the ones of the geohash cells, like redis.
+func (c *memoryClient) geoSearch(key string, query cache.GeoSearchQuery, byRadius bool) ([]redis.GeoLocation, error) {
+	unit, err := unitOf(query.Unit)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	it, ok, err := c.typed(key, zsetKind)
+	if err != nil || !ok {
+		return []redis.GeoLocation{}, err
+	}
+
+	longitude, latitude := query.Longitude, query.Latitude
+	if query.Member != "" {
+		score, ok := it.zset[query.Member]
+		if !ok {
+			return nil, errors.New("ERR could not decode requested zset member")
+		}
+		longitude, latitude = decode(uint64(score))
+	} else if _, err := geohash(longitude, latitude); err != nil {
+		return nil, err
+	}
+
+	locations := make([]redis.GeoLocation, 0)
+	for member, score := range it.zset {
+		lon, lat := decode(uint64(score))
+
+		dist := distance(longitude, latitude, lon, lat)
+		if byRadius && dist > query.Radius*unit {
+			continue
+		}
+
+		// - like redis, a box is measured along the meridian and the parallel of the member
+		if !byRadius && (math.Abs(lat-latitude)*math.Pi/180*earthRadius > query.Height*unit/2 ||
+			distance(longitude, lat, lon, lat) > query.Width*unit/2) {
+			continue
+		}
+
+		location := redis.GeoLocation{Name: member, Dist: dist}
+		if query.WithCoord {
+			location.Longitude, location.Latitude = lon, lat
+		}
+		if query.WithGeoHash {
+			location.GeoHash = int64(score)
+		}
+		locations = append(locations, location)
+	}
+
+	order := strings.ToUpper(query.Sort)
+	if order == "" && query.Count > 0 {
+		order = "ASC"
+	}
+
+	sort.Slice(locations, func(i, j int) bool {
+		a, b := locations[i], locations[j]
+		switch {
+		case order == "ASC" && a.Dist != b.Dist:
+			return a.Dist < b.Dist
+		case order == "DESC" && a.Dist != b.Dist:
+			return a.Dist > b.Dist
+		}
+		return a.Name < b.Name
+	})
+
+	if query.Count > 0 && len(locations) > query.Count {
+		locations = locations[:query.Count]
+	}
+
+	for n := range locations {
+		if query.WithDist {
+			locations[n].Dist = math.Round(locations[n].Dist/unit*10000) / 10000
+		} else {
+			locations[n].Dist = 0
+		}
+	}
+
+	return locations, nil
+}
+
+func (c *memoryClient) GeoDist(key, member1, member2, unit string) (float64, error) {
+	if unit == "" {
+		unit = "km"
+	}
+
+	u, err := unitOf(unit)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to geodist key %s!", key)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+
+	it, ok, err := c.typed(key, zsetKind)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to geodist key %s!", key)
+	}
+
+	var score1, score2 float64
+	ok1, ok2 := false, false
+	if ok {
+		score1, ok1 = it.zset[member1]
+		score2, ok2 = it.zset[member2]
+	}
+
+	if !ok1 || !ok2 {
+		return 0, errors.Wrapf(redis.Nil, "member %s or %s of key %s does not exits", member1, member2, key)
+	}
+
+	lon1, lat1 := decode(uint64(score1))
+	lon2, lat2 := decode(uint64(score2))
+
+	return math.Round(distance(lon1, lat1, lon2, lat2)/u*10000) / 10000, nil
+}
+
+func unitOf(unit string) (float64, error) {
+	if unit == "" {
+		return units["km"], nil
+	}
+
+	u, ok := units[strings.ToLower(unit)]
+	if !ok {
+		return 0, ErrUnsupportedUnit
+	}
+
+	return u, nil
+}
+
+// geohash encodes longitude and latitude in the 52 bits of a score.
+func geohash(longitude, latitude float64) (uint64, error) {
+	if math.Abs(longitude) > geoLongitudeMax || math.Abs(latitude) > geoLatitudeMax {
+		return 0, ErrInvalidCoordinates
+	}
+
+	lat := uint32((latitude + geoLatitudeMax) / (2 * geoLatitudeMax) * (1 << geoStep))
+	lon := uint32((longitude + geoLongitudeMax) / (2 * geoLongitudeMax) * (1 << geoStep))
+
+	return interleave(lat) | interleave(lon)<<1, nil
+}
+
+// decode returns the center of the geohash cell of hash.
+func decode(hash uint64) (float64, float64) {
+	lat := float64(deinterleave(hash))
+	lon := float64(deinterleave(hash >> 1))
+	cell := float64(uint64(1) << geoStep)
+
+	latitude := -geoLatitudeMax + (lat+0.5)/cell*2*geoLatitudeMax
+	longitude := -geoLongitudeMax + (lon+0.5)/cell*2*geoLongitudeMax
+
+	return math.Max(-geoLongitudeMax, math.Min(geoLongitudeMax, longitude)),
+		math.Max(-geoLatitudeMax, math.Min(geoLatitudeMax, latitude))
+}
+
+// interleave spreads the bits of v on the even bits of the result.
+func interleave(v uint32) uint64 {
+	x := uint64(v)
+	x = (x | x<<16) & 0x0000FFFF0000FFFF
+	x = (x | x<<8) & 0x00FF00FF00FF00FF
+	x = (x | x<<4) & 0x0F0F0F0F0F0F0F0F
+	x = (x | x<<2) & 0x3333333333333333
+	x = (x | x<<1) & 0x5555555555555555
+	return x
+}
+
+// deinterleave gathers the even bits of v.
+func deinterleave(v uint64) uint32 {
+	x := v & 0x5555555555555555
+	x = (x | x>>1) & 0x3333333333333333
+	x = (x | x>>2) & 0x0F0F0F0F0F0F0F0F
+	x = (x | x>>4) & 0x00FF00FF00FF00FF
+	x = (x | x>>8) & 0x0000FFFF0000FFFF
+	x = (x | x>>16) & 0x00000000FFFFFFFF
+	return uint32(x)
+}
+
+// distance returns the haversine distance in meters.
+func distance(lon1, lat1, lon2, lat2 float64) float64 {
+	lat1r, lat2r := lat1*math.Pi/180, lat2*math.Pi/180
+	u := math.Sin((lat2r - lat1r) / 2)
+	v := math.Sin((lon2 - lon1) * math.Pi / 180 / 2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(u*u+math.Cos(lat1r)*math.Cos(lat2r)*v*v))
+}
@@ -0,0 +1,89 @@
+package memory
+
+// match reports whether key matches the glob pattern of KEYS: '*' and '?' match any run and any
+// character, '[...]' a class with ranges and '^' negation, and '\' escapes the next character.
+func match(pattern, key string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+
+			if len(pattern) == 1 {
+				return true
+			}
+
+			for n := 0; n <= len(key); n++ {
+				if match(pattern[1:], key[n:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(key) == 0 {
+				return false
+			}
+			key = key[1:]
+			pattern = pattern[1:]
+		case '[':
+			if len(key) == 0 {
+				return false
+			}
+
+			matched, rest := matchClass(pattern[1:], key[0])
+			if !matched {
+				return false
+			}
+			key = key[1:]
+			pattern = rest
+		default:
+			if pattern[0] == '\\' && len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+
+			if len(key) == 0 || key[0] != pattern[0] {
+				return false
+			}
+			key = key[1:]
+			pattern = pattern[1:]
+		}
+	}
+
+	return len(key) == 0
+}
+
+// matchClass matches c against the class pattern starts with, past its '[', and returns the
+// pattern after the class.
+func matchClass(pattern string, c byte) (bool, string) {
+	negate := len(pattern) > 0 && pattern[0] == '^'
+	if negate {
+		pattern = pattern[1:]
+	}
+
+	matched := false
+	for len(pattern) > 0 && pattern[0] != ']' {
+		switch {
+		case pattern[0] == '\\' && len(pattern) > 1:
+			matched = matched || pattern[1] == c
+			pattern = pattern[2:]
+		case len(pattern) > 2 && pattern[1] == '-' && pattern[2] != ']':
+			low, high := pattern[0], pattern[2]
+			if low > high {
+				low, high = high, low
+			}
+			matched = matched || (c >= low && c <= high)
+			pattern = pattern[3:]
+		default:
+			matched = matched || pattern[0] == c
+			pattern = pattern[1:]
+		}
+	}
+
+	if len(pattern) > 0 {
+		// - skips the closing ']'
+		pattern = pattern[1:]
+	}
+
+	return matched != negate, pattern
+}
@@ -0,0 +1,505 @@
+package memory
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+type (
+	// pipe queues commands and runs them together on Exec, no other command runs in between. The
+	// pipe of a tx only runs when the watched keys are unchanged.
+	pipe struct {
+		c        *memoryClient
+		commands []command
+		keys     []string
+		watched  map[string]*item
+	}
+
+	// command runs a queued command under the write lock and sets its result, fail sets it instead
+	// when the pipeline doesn't run.
+	command struct {
+		result interface{ Err() error }
+		run    func()
+		fail   func(error)
+	}
+
+	tx struct {
+		c       *memoryClient
+		keys    []string
+		watched map[string]*item
+	}
+)
+
+func (p *pipe) queue(result interface{ Err() error }, run func(), fail func(error)) {
+	p.commands = append(p.commands, command{result: result, run: run, fail: fail})
+}
+
+func (p *pipe) Set(key string, value interface{}) *cache.StatusResult {
+	return p.SetWithExpiration(key, value, 0)
+}
+
+func (p *pipe) SetWithExpiration(key string, value interface{}, expired time.Duration) *cache.StatusResult {
+	r := &cache.StatusResult{}
+
+	data, err := p.c.codec.Marshal(value)
+	if err != nil {
+		r.Resolve(errors.Wrapf(err, "failed to marshal key %s", key))
+		return r
+	}
+
+	p.queue(r, func() {
+		p.c.set(key, data, expired)
+		r.Resolve(nil)
+	}, r.Resolve)
+
+	return r
+}
+
+func (p *pipe) Get(key string) *cache.ValueResult {
+	r := cache.NewValueResult(p.c.codec)
+	p.queue(r, func() {
+		r.Resolve(p.c.value(key))
+	}, func(err error) { r.Resolve(nil, err) })
+
+	return r
+}
+
+func (p *pipe) GetSet(key string, value interface{}) *cache.ValueResult {
+	r := cache.NewValueResult(p.c.codec)
+
+	data, err := p.c.codec.Marshal(value)
+	if err != nil {
+		r.Resolve(nil, errors.Wrapf(err, "failed to marshal key %s", key))
+		return r
+	}
+
+	p.queue(r, func() {
+		val, ok, err := p.c.swap(key, data)
+		if err == nil && !ok {
+			err = redis.Nil
+		}
+		r.Resolve(val, missing(err, key))
+	}, func(err error) { r.Resolve(nil, err) })
+
+	return r
+}
+
+func (p *pipe) MGet(keys ...string) *cache.ValuesResult {
+	r := cache.NewValuesResult(p.c.codec)
+	p.queue(r, func() {
+		r.Resolve(p.c.mget(keys...), nil)
+	}, func(err error) { r.Resolve(nil, err) })
+
+	return r
+}
+
+func (p *pipe) Remove(key string) *cache.IntResult {
+	r := &cache.IntResult{}
+	p.queue(r, func() {
+		r.Resolve(p.c.remove(key), nil)
+	}, func(err error) { r.Resolve(0, err) })
+
+	return r
+}
+
+func (p *pipe) Incr(key string) *cache.IntResult {
+	return p.IncrBy(key, 1)
+}
+
+func (p *pipe) Decr(key string) *cache.IntResult {
+	return p.IncrBy(key, -1)
+}
+
+func (p *pipe) IncrBy(key string, value int64) *cache.IntResult {
+	r := &cache.IntResult{}
+	p.queue(r, func() {
+		val, err := p.c.incrBy(key, value)
+		r.Resolve(val, missing(err, key))
+	}, func(err error) { r.Resolve(0, err) })
+
+	return r
+}
+
+func (p *pipe) SetNX(key string, value interface{}, ttl time.Duration) *cache.BoolResult {
+	r := &cache.BoolResult{}
+
+	data, err := p.c.codec.Marshal(value)
+	if err != nil {
+		r.Resolve(false, errors.Wrapf(err, "failed to marshal key %s", key))
+		return r
+	}
+
+	p.queue(r, func() {
+		r.Resolve(p.c.setNX(key, data, ttl), nil)
+	}, func(err error) { r.Resolve(false, err) })
+
+	return r
+}
+
+func (p *pipe) Expire(key string, ttl time.Duration) *cache.BoolResult {
+	r := &cache.BoolResult{}
+	p.queue(r, func() {
+		r.Resolve(p.c.expire(key, ttl), nil)
+	}, func(err error) { r.Resolve(false, err) })
+
+	return r
+}
+
+func (p *pipe) TTL(key string) *cache.DurationResult {
+	r := &cache.DurationResult{}
+	p.queue(r, func() {
+		r.Resolve(p.c.ttl(key), nil)
+	}, func(err error) { r.Resolve(0, err) })
+
+	return r
+}
+
+func (p *pipe) Exists(keys ...string) *cache.IntResult {
+	r := &cache.IntResult{}
+	p.queue(r, func() {
+		r.Resolve(p.c.exists(keys...), nil)
+	}, func(err error) { r.Resolve(0, err) })
+
+	return r
+}
+
+func (p *pipe) HSet(key, field string, value interface{}) *cache.StatusResult {
+	return p.HMSet(key, map[string]interface{}{field: value})
+}
+
+func (p *pipe) HMSet(key string, value map[string]interface{}) *cache.StatusResult {
+	r := &cache.StatusResult{}
+
+	fields, err := p.c.marshalFields(value)
+	if err != nil {
+		r.Resolve(errors.Wrapf(err, "failed to marshal fields of key %s", key))
+		return r
+	}
+
+	p.queue(r, func() {
+		r.Resolve(missing(p.c.hset(key, fields), key))
+	}, r.Resolve)
+
+	return r
+}
+
+func (p *pipe) HGet(key, field string) *cache.ValueResult {
+	r := cache.NewValueResult(p.c.codec)
+	p.queue(r, func() {
+		r.Resolve(p.c.hget(key, field))
+	}, func(err error) { r.Resolve(nil, err) })
+
+	return r
+}
+
+func (p *pipe) HMGet(key string, fields ...string) *cache.ValuesResult {
+	r := cache.NewValuesResult(p.c.codec)
+	p.queue(r, func() {
+		r.Resolve(p.c.hmget(key, fields...))
+	}, func(err error) { r.Resolve(nil, err) })
+
+	return r
+}
+
+func (p *pipe) HGetAll(key string) *cache.StringMapResult {
+	r := &cache.StringMapResult{}
+	p.queue(r, func() {
+		r.Resolve(p.c.hgetAll(key))
+	}, func(err error) { r.Resolve(nil, err) })
+
+	return r
+}
+
+func (p *pipe) LPush(key string, values ...interface{}) *cache.IntResult {
+	return p.push(key, values, true)
+}
+
+func (p *pipe) RPush(key string, values ...interface{}) *cache.IntResult {
+	return p.push(key, values, false)
+}
+
+func (p *pipe) push(key string, values []interface{}, left bool) *cache.IntResult {
+	r := &cache.IntResult{}
+
+	data, err := p.c.marshalValues(values)
+	if err != nil {
+		r.Resolve(0, errors.Wrapf(err, "failed to push key %s", key))
+		return r
+	}
+
+	p.queue(r, func() {
+		val, err := p.c.push(key, data, left)
+		r.Resolve(val, missing(err, key))
+	}, func(err error) { r.Resolve(0, err) })
+
+	return r
+}
+
+func (p *pipe) LPop(key string) *cache.ValueResult {
+	return p.pop(key, true)
+}
+
+func (p *pipe) RPop(key string) *cache.ValueResult {
+	return p.pop(key, false)
+}
+
+func (p *pipe) pop(key string, left bool) *cache.ValueResult {
+	r := cache.NewValueResult(p.c.codec)
+	p.queue(r, func() {
+		val, ok, err := p.c.pop(key, left)
+		if err == nil && !ok {
+			err = redis.Nil
+		}
+		r.Resolve(val, missing(err, key))
+	}, func(err error) { r.Resolve(nil, err) })
+
+	return r
+}
+
+func (p *pipe) LRange(key string, start, stop int64) *cache.ValuesResult {
+	r := cache.NewValuesResult(p.c.codec)
+	p.queue(r, func() {
+		val, err := p.c.lrange(key, start, stop)
+		r.Resolve(val, missing(err, key))
+	}, func(err error) { r.Resolve(nil, err) })
+
+	return r
+}
+
+func (p *pipe) SAdd(key string, members ...interface{}) *cache.IntResult {
+	return p.members(key, members, p.c.sadd)
+}
+
+func (p *pipe) SRem(key string, members ...interface{}) *cache.IntResult {
+	return p.members(key, members, p.c.srem)
+}
+
+func (p *pipe) members(key string, members []interface{}, fn func(string, [][]byte) (int64, error)) *cache.IntResult {
+	r := &cache.IntResult{}
+
+	data, err := p.c.marshalValues(members)
+	if err != nil {
+		r.Resolve(0, errors.Wrapf(err, "failed to marshal members of key %s", key))
+		return r
+	}
+
+	p.queue(r, func() {
+		val, err := fn(key, data)
+		r.Resolve(val, missing(err, key))
+	}, func(err error) { r.Resolve(0, err) })
+
+	return r
+}
+
+func (p *pipe) SMembers(key string) *cache.ValuesResult {
+	r := cache.NewValuesResult(p.c.codec)
+	p.queue(r, func() {
+		val, err := p.c.members(key)
+		r.Resolve(val, missing(err, key))
+	}, func(err error) { r.Resolve(nil, err) })
+
+	return r
+}
+
+func (p *pipe) SIsMember(key string, member interface{}) *cache.BoolResult {
+	r := &cache.BoolResult{}
+
+	data, err := p.c.codec.Marshal(member)
+	if err != nil {
+		r.Resolve(false, errors.Wrapf(err, "failed to marshal member of key %s", key))
+		return r
+	}
+
+	p.queue(r, func() {
+		val, err := p.c.sismember(key, data)
+		r.Resolve(val, missing(err, key))
+	}, func(err error) { r.Resolve(false, err) })
+
+	return r
+}
+
+func (p *pipe) ZAdd(key string, members ...redis.Z) *cache.IntResult {
+	r := &cache.IntResult{}
+	p.queue(r, func() {
+		val, err := p.c.zadd(key, members...)
+		r.Resolve(val, missing(err, key))
+	}, func(err error) { r.Resolve(0, err) })
+
+	return r
+}
+
+func (p *pipe) ZRem(key string, members ...interface{}) *cache.IntResult {
+	r := &cache.IntResult{}
+	p.queue(r, func() {
+		val, err := p.c.zrem(key, members...)
+		r.Resolve(val, missing(err, key))
+	}, func(err error) { r.Resolve(0, err) })
+
+	return r
+}
+
+func (p *pipe) ZIncrBy(key string, increment float64, member string) *cache.FloatResult {
+	r := &cache.FloatResult{}
+	p.queue(r, func() {
+		val, err := p.c.zincrBy(key, increment, member)
+		r.Resolve(val, missing(err, key))
+	}, func(err error) { r.Resolve(0, err) })
+
+	return r
+}
+
+func (p *pipe) ZRangeByScore(key string, by redis.ZRangeBy) *cache.ZResult {
+	r := &cache.ZResult{}
+	p.queue(r, func() {
+		val, err := p.c.zrangeByScore(key, by)
+		r.Resolve(val, missing(err, key))
+	}, func(err error) { r.Resolve(nil, err) })
+
+	return r
+}
+
+func (p *pipe) ZRevRange(key string, start, stop int64) *cache.ZResult {
+	r := &cache.ZResult{}
+	p.queue(r, func() {
+		val, err := p.c.zrevRange(key, start, stop)
+		r.Resolve(val, missing(err, key))
+	}, func(err error) { r.Resolve(nil, err) })
+
+	return r
+}
+
+func (p *pipe) Exec() error {
+	p.c.mu.Lock()
+	defer p.c.mu.Unlock()
+
+	commands := p.commands
+	p.commands = nil
+
+	failure := p.c.check()
+	if failure == nil && p.watched != nil && !reflect.DeepEqual(p.c.snapshot(p.keys), p.watched) {
+		failure = cache.ErrTxFailed
+	}
+
+	if failure != nil {
+		for _, command := range commands {
+			command.fail(failure)
+		}
+		return errors.Wrap(failure, "failed to exec pipeline")
+	}
+
+	for _, command := range commands {
+		command.run()
+	}
+
+	// - misses are only reported by their results
+	for _, command := range commands {
+		if err := command.result.Err(); err != nil && errors.Cause(err) != redis.Nil {
+			return errors.Wrap(err, "failed to exec pipeline")
+		}
+	}
+
+	return nil
+}
+
+// missing wraps the error of a command run by Exec, redis.Nil for a missing key.
+func missing(err error, key string) error {
+	if err == redis.Nil {
+		return errors.Wrapf(err, "key %s does not exits", key)
+	}
+
+	return errors.Wrapf(err, "failed to run command on key %s", key)
+}
+
+func (c *memoryClient) TxPipeline() cache.Pipe {
+	return &pipe{c: c}
+}
+
+// Watch runs fn with a copy of the watched keys, the pipeline of its tx compares them on Exec.
+func (c *memoryClient) Watch(fn func(tx cache.Tx) error, keys ...string) error {
+	c.mu.RLock()
+	err := c.check()
+	watched := c.snapshot(keys)
+	c.mu.RUnlock()
+
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return fn(&tx{c: c, keys: keys, watched: watched})
+}
+
+// snapshot copies the live items of keys, nil for missing ones, the caller holds the lock.
+func (c *memoryClient) snapshot(keys []string) map[string]*item {
+	items := make(map[string]*item, len(keys))
+	for _, key := range keys {
+		items[key] = nil
+		if it, ok := c.get(key); ok {
+			items[key] = it.clone()
+		}
+	}
+
+	return items
+}
+
+func (it *item) clone() *item {
+	c := &item{kind: it.kind, expiresAt: it.expiresAt}
+
+	if it.value != nil {
+		c.value = append([]byte(nil), it.value...)
+	}
+
+	if it.list != nil {
+		c.list = append([][]byte(nil), it.list...)
+	}
+
+	if it.hash != nil {
+		c.hash = make(map[string][]byte, len(it.hash))
+		for field, data := range it.hash {
+			c.hash[field] = data
+		}
+	}
+
+	if it.zset != nil {
+		c.zset = make(map[string]float64, len(it.zset))
+		for member, score := range it.zset {
+			c.zset[member] = score
+		}
+	}
+
+	if it.set != nil {
+		c.set = make(map[string]struct{}, len(it.set))
+		for member := range it.set {
+			c.set[member] = struct{}{}
+		}
+	}
+
+	return c
+}
+
+func (t *tx) Get(key string, object interface{}) error {
+	return t.c.Get(key, object)
+}
+
+func (t *tx) HGet(key, field string, object interface{}) error {
+	return t.c.HGet(key, field, object)
+}
+
+func (t *tx) HGetAll(key string) (map[string]string, error) {
+	return t.c.HGetAll(key)
+}
+
+func (t *tx) Exists(keys ...string) (int64, error) {
+	return t.c.Exists(keys...)
+}
+
+func (t *tx) TTL(key string) (time.Duration, error) {
+	return t.c.TTL(key)
+}
+
+func (t *tx) Pipeline() cache.Pipe {
+	return &pipe{c: t.c, keys: t.keys, watched: t.watched}
+}
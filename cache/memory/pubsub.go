@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+const (
+	// channelSize and deliveryTimeout follow the go-redis PubSub channel.
+	channelSize     = 100
+	deliveryTimeout = 30 * time.Second
+)
+
+type (
+	// pubsub receives the messages of its channels and patterns, they are guarded by the lock of
+	// the cache.
+	pubsub struct {
+		c        *memoryClient
+		channels []string
+		patterns []string
+		ch       chan *redis.Message
+		mu       sync.Mutex
+		once     sync.Once
+		done     chan struct{}
+	}
+)
+
+func newPubSub(c *memoryClient) *pubsub {
+	return &pubsub{c: c, ch: make(chan *redis.Message, channelSize), done: make(chan struct{})}
+}
+
+func (p *pubsub) Receive() error {
+	select {
+	case <-p.done:
+		return errors.Wrap(ErrClosed, "failed to receive")
+	default:
+		return nil
+	}
+}
+
+func (p *pubsub) Publish(message string) error {
+	p.c.mu.RLock()
+	if len(p.channels) == 0 {
+		p.c.mu.RUnlock()
+		return errors.New("no channel to publish to!")
+	}
+	channel := p.channels[0]
+	p.c.mu.RUnlock()
+
+	if err := p.c.publish(channel, message); err != nil {
+		return errors.Wrapf(err, "failed to publish message to cn %s", channel)
+	}
+
+	return nil
+}
+
+func (p *pubsub) Subscribe(channels ...string) error {
+	return p.update(func() {
+		p.channels = add(p.channels, channels)
+	})
+}
+
+func (p *pubsub) PSubscribe(patterns ...string) error {
+	return p.update(func() {
+		p.patterns = add(p.patterns, patterns)
+	})
+}
+
+func (p *pubsub) Unsubscribe(channels ...string) error {
+	return p.update(func() {
+		p.channels = remove(p.channels, channels)
+	})
+}
+
+func (p *pubsub) PUnsubscribe(patterns ...string) error {
+	return p.update(func() {
+		p.patterns = remove(p.patterns, patterns)
+	})
+}
+
+// update changes the channels or patterns of an open subscription under the lock of the cache.
+func (p *pubsub) update(fn func()) error {
+	p.c.mu.Lock()
+	defer p.c.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return errors.WithStack(ErrClosed)
+	default:
+	}
+
+	fn()
+	return nil
+}
+
+// add appends the names missing from list.
+func add(list, names []string) []string {
+	for _, name := range names {
+		if !contains(list, name) {
+			list = append(list, name)
+		}
+	}
+
+	return list
+}
+
+// remove removes names from list, every name when names is empty like UNSUBSCRIBE.
+func remove(list, names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+
+	kept := make([]string, 0, len(list))
+	for _, n := range list {
+		if !contains(names, n) {
+			kept = append(kept, n)
+		}
+	}
+
+	return kept
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *pubsub) Channel() <-chan *redis.Message {
+	return p.ch
+}
+
+func (p *pubsub) Close() error {
+	p.c.unsubscribe(p)
+	p.close()
+	return nil
+}
+
+// deliver waits for a full channel like go-redis, dropping the message after deliveryTimeout.
+func (p *pubsub) deliver(message *redis.Message) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+
+	timer := time.NewTimer(deliveryTimeout)
+	defer timer.Stop()
+
+	select {
+	case p.ch <- message:
+	case <-p.done:
+	case <-timer.C:
+		log.Printf("memory: %s channel is full for %s (message is dropped)", message.Channel, deliveryTimeout)
+	}
+}
+
+func (p *pubsub) close() {
+	p.once.Do(func() {
+		close(p.done)
+
+		// - waits for a pending delivery before closing the channel
+		p.mu.Lock()
+		close(p.ch)
+		p.mu.Unlock()
+	})
+}
@@ -0,0 +1,343 @@
+// Package namespace prefixes every key, pattern and pub/sub channel of a cache.Cache with a service
+// namespace and a version, so services sharing a redis don't collide. Bumping the version
+// invalidates every key logically, the keys of older versions are left to expire.
+package namespace
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultSeparator = ":"
+)
+
+type (
+	Option struct {
+		// Namespace is the name of the service owning the keys.
+		Namespace string
+		// Version is bumped to invalidate every key of the namespace.
+		Version int
+		// Separator joins the namespace, the version and the key, defaults to ":".
+		Separator string
+	}
+
+	namespaced struct {
+		cache.Cache
+		prefix string
+	}
+
+	iterator struct {
+		cache.Iterator
+		n *namespaced
+	}
+)
+
+// New returns c with its keys prefixed by "<namespace>:v<version>:".
+func New(c cache.Cache, option *Option) (cache.Cache, error) {
+	if c == nil {
+		return nil, errors.New("cache is required!")
+	}
+
+	if option == nil || option.Namespace == "" {
+		return nil, errors.New("namespace is required!")
+	}
+
+	separator := option.Separator
+	if separator == "" {
+		separator = DefaultSeparator
+	}
+
+	prefix := option.Namespace + separator + "v" + strconv.Itoa(option.Version) + separator
+	return &namespaced{Cache: c, prefix: prefix}, nil
+}
+
+func (n *namespaced) key(key string) string {
+	return n.prefix + key
+}
+
+func (n *namespaced) keys(keys []string) []string {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = n.prefix + key
+	}
+
+	return prefixed
+}
+
+func (n *namespaced) strip(key string) string {
+	return strings.TrimPrefix(key, n.prefix)
+}
+
+func (n *namespaced) SetWithExpiration(key string, value interface{}, duration time.Duration) error {
+	return n.Cache.SetWithExpiration(n.key(key), value, duration)
+}
+
+func (n *namespaced) Set(key string, value interface{}) error {
+	return n.Cache.Set(n.key(key), value)
+}
+
+func (n *namespaced) Get(key string, data interface{}) error {
+	return n.Cache.Get(n.key(key), data)
+}
+
+func (n *namespaced) Incr(key string) (int64, error) {
+	return n.Cache.Incr(n.key(key))
+}
+
+func (n *namespaced) Decr(key string) (int64, error) {
+	return n.Cache.Decr(n.key(key))
+}
+
+func (n *namespaced) IncrBy(key string, value int64) (int64, error) {
+	return n.Cache.IncrBy(n.key(key), value)
+}
+
+func (n *namespaced) SetNX(key string, value interface{}, ttl time.Duration) (bool, error) {
+	return n.Cache.SetNX(n.key(key), value, ttl)
+}
+
+func (n *namespaced) Expire(key string, ttl time.Duration) (bool, error) {
+	return n.Cache.Expire(n.key(key), ttl)
+}
+
+func (n *namespaced) TTL(key string) (time.Duration, error) {
+	return n.Cache.TTL(n.key(key))
+}
+
+func (n *namespaced) Exists(keys ...string) (int64, error) {
+	return n.Cache.Exists(n.keys(keys)...)
+}
+
+func (n *namespaced) GetSet(key string, value interface{}, object interface{}) error {
+	return n.Cache.GetSet(n.key(key), value, object)
+}
+
+func (n *namespaced) SetZSetWithExpiration(key string, duration time.Duration, data ...redis.Z) error {
+	return n.Cache.SetZSetWithExpiration(n.key(key), duration, data...)
+}
+
+func (n *namespaced) SetZSet(key string, data ...redis.Z) error {
+	return n.Cache.SetZSet(n.key(key), data...)
+}
+
+func (n *namespaced) GetZSet(key string) ([]redis.Z, error) {
+	return n.Cache.GetZSet(n.key(key))
+}
+
+func (n *namespaced) HMSetWithExpiration(key string, value map[string]interface{}, ttl time.Duration) error {
+	return n.Cache.HMSetWithExpiration(n.key(key), value, ttl)
+}
+
+func (n *namespaced) HMSet(key string, value map[string]interface{}) error {
+	return n.Cache.HMSet(n.key(key), value)
+}
+
+func (n *namespaced) HSetWithExpiration(key, field string, value interface{}, ttl time.Duration) error {
+	return n.Cache.HSetWithExpiration(n.key(key), field, value, ttl)
+}
+
+func (n *namespaced) HSet(key, field string, value interface{}) error {
+	return n.Cache.HSet(n.key(key), field, value)
+}
+
+func (n *namespaced) HMGet(key string, fields ...string) ([]interface{}, error) {
+	return n.Cache.HMGet(n.key(key), fields...)
+}
+
+func (n *namespaced) HGetAll(key string) (map[string]string, error) {
+	return n.Cache.HGetAll(n.key(key))
+}
+
+func (n *namespaced) HGet(key, field string, response interface{}) error {
+	return n.Cache.HGet(n.key(key), field, response)
+}
+
+func (n *namespaced) MGet(keys []string) ([]interface{}, error) {
+	return n.Cache.MGet(n.keys(keys))
+}
+
+func (n *namespaced) MGetInto(keys []string, results interface{}) error {
+	return n.Cache.MGetInto(n.keys(keys), results)
+}
+
+func (n *namespaced) LPush(key string, values ...interface{}) (int64, error) {
+	return n.Cache.LPush(n.key(key), values...)
+}
+
+func (n *namespaced) RPush(key string, values ...interface{}) (int64, error) {
+	return n.Cache.RPush(n.key(key), values...)
+}
+
+func (n *namespaced) LPop(key string, object interface{}) error {
+	return n.Cache.LPop(n.key(key), object)
+}
+
+func (n *namespaced) RPop(key string, object interface{}) error {
+	return n.Cache.RPop(n.key(key), object)
+}
+
+func (n *namespaced) BLPop(timeout time.Duration, object interface{}, keys ...string) (string, error) {
+	key, err := n.Cache.BLPop(timeout, object, n.keys(keys)...)
+	return n.strip(key), err
+}
+
+func (n *namespaced) LRange(key string, start, stop int64, results interface{}) error {
+	return n.Cache.LRange(n.key(key), start, stop, results)
+}
+
+func (n *namespaced) SAdd(key string, members ...interface{}) (int64, error) {
+	return n.Cache.SAdd(n.key(key), members...)
+}
+
+func (n *namespaced) SRem(key string, members ...interface{}) (int64, error) {
+	return n.Cache.SRem(n.key(key), members...)
+}
+
+func (n *namespaced) SMembers(key string, results interface{}) error {
+	return n.Cache.SMembers(n.key(key), results)
+}
+
+func (n *namespaced) SIsMember(key string, member interface{}) (bool, error) {
+	return n.Cache.SIsMember(n.key(key), member)
+}
+
+func (n *namespaced) ZAdd(key string, members ...redis.Z) (int64, error) {
+	return n.Cache.ZAdd(n.key(key), members...)
+}
+
+func (n *namespaced) ZRem(key string, members ...interface{}) (int64, error) {
+	return n.Cache.ZRem(n.key(key), members...)
+}
+
+func (n *namespaced) ZRangeByScore(key string, by redis.ZRangeBy) ([]redis.Z, error) {
+	return n.Cache.ZRangeByScore(n.key(key), by)
+}
+
+func (n *namespaced) ZRevRange(key string, start, stop int64) ([]redis.Z, error) {
+	return n.Cache.ZRevRange(n.key(key), start, stop)
+}
+
+func (n *namespaced) ZIncrBy(key string, increment float64, member string) (float64, error) {
+	return n.Cache.ZIncrBy(n.key(key), increment, member)
+}
+
+func (n *namespaced) GeoAdd(key string, locations ...*redis.GeoLocation) (int64, error) {
+	return n.Cache.GeoAdd(n.key(key), locations...)
+}
+
+func (n *namespaced) GeoRadius(key string, longitude, latitude float64, query redis.GeoRadiusQuery) ([]redis.GeoLocation, error) {
+	return n.Cache.GeoRadius(n.key(key), longitude, latitude, query)
+}
+
+func (n *namespaced) GeoSearch(key string, query cache.GeoSearchQuery) ([]redis.GeoLocation, error) {
+	return n.Cache.GeoSearch(n.key(key), query)
+}
+
+func (n *namespaced) GeoDist(key, member1, member2, unit string) (float64, error) {
+	return n.Cache.GeoDist(n.key(key), member1, member2, unit)
+}
+
+func (n *namespaced) PFAdd(key string, elements ...interface{}) (int64, error) {
+	return n.Cache.PFAdd(n.key(key), elements...)
+}
+
+func (n *namespaced) PFCount(keys ...string) (int64, error) {
+	return n.Cache.PFCount(n.keys(keys)...)
+}
+
+func (n *namespaced) PFMerge(dest string, keys ...string) error {
+	return n.Cache.PFMerge(n.key(dest), n.keys(keys)...)
+}
+
+// Keys lists the keys of the namespace matching pattern, without their prefix.
+func (n *namespaced) Keys(pattern string) ([]string, error) {
+	keys, err := n.Cache.Keys(n.key(pattern))
+	if err != nil {
+		return keys, err
+	}
+
+	for i, key := range keys {
+		keys[i] = n.strip(key)
+	}
+
+	return keys, nil
+}
+
+func (n *namespaced) Scan(pattern string, count int64) cache.Iterator {
+	return &iterator{Iterator: n.Cache.Scan(n.key(pattern), count), n: n}
+}
+
+func (i *iterator) Val() string {
+	return i.n.strip(i.Iterator.Val())
+}
+
+func (n *namespaced) Remove(key string) error {
+	return n.Cache.Remove(n.key(key))
+}
+
+func (n *namespaced) RemoveByPattern(pattern string, countPerLoop int64) error {
+	return n.Cache.RemoveByPattern(n.key(pattern), countPerLoop)
+}
+
+// FlushDatabase removes the keys of the namespace only, other services keep theirs.
+func (n *namespaced) FlushDatabase() error {
+	return n.Cache.RemoveByPattern(n.key("*"), cache.DefaultScanCount)
+}
+
+// FlushAll removes the keys of the namespace only, like FlushDatabase.
+func (n *namespaced) FlushAll() error {
+	return n.FlushDatabase()
+}
+
+func (n *namespaced) Pipeline() cache.Pipe {
+	return &pipe{Pipe: n.Cache.Pipeline(), n: n}
+}
+
+func (n *namespaced) TxPipeline() cache.Pipe {
+	return &pipe{Pipe: n.Cache.TxPipeline(), n: n}
+}
+
+func (n *namespaced) Watch(fn func(tx cache.Tx) error, keys ...string) error {
+	return n.Cache.Watch(func(tx cache.Tx) error {
+		return fn(&namespacedTx{Tx: tx, n: n})
+	}, n.keys(keys)...)
+}
+
+// RunScript runs the script with keys prefixed, the keys the script builds itself are not.
+func (n *namespaced) RunScript(name string, keys []string, args ...interface{}) *cache.ScriptResult {
+	return n.Cache.RunScript(name, n.keys(keys), args...)
+}
+
+func (n *namespaced) Client() cache.Cache {
+	return n
+}
+
+// Subscribe subscribes the channels of the namespace, messages are received with channel unprefixed.
+func (n *namespaced) Subscribe(channels ...string) (cache.PubSub, error) {
+	p, err := n.Cache.Subscribe(n.keys(channels)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pubsub{PubSub: p, n: n}, nil
+}
+
+// PSubscribe subscribes the channels of the namespace matching patterns.
+func (n *namespaced) PSubscribe(patterns ...string) (cache.PubSub, error) {
+	p, err := n.Cache.PSubscribe(n.keys(patterns)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pubsub{PubSub: p, n: n}, nil
+}
+
+func (n *namespaced) Publish(channel, message string) error {
+	return n.Cache.Publish(n.key(channel), message)
+}
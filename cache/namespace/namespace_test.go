@@ -0,0 +1,88 @@
+package namespace
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/memory"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+func Test_prefixes_keys_and_isolates_versions(t *testing.T) {
+	remote, _ := memory.New(nil)
+	defer remote.Close()
+
+	c, _ := New(remote, &Option{Namespace: "hotel", Version: 1})
+	_ = c.Set("room:1", "deluxe")
+	_ = remote.Set("other:room:1", "suite")
+
+	p := c.Pipeline()
+	p.Set("room:2", "twin")
+	if err := p.Exec(); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if keys, _ := remote.Keys("*"); !reflect.DeepEqual(keys, []string{"hotel:v1:room:1", "hotel:v1:room:2", "other:room:1"}) {
+		t.Errorf("unexpected remote keys %v", keys)
+	}
+
+	if keys, _ := c.Keys("room:*"); !reflect.DeepEqual(keys, []string{"room:1", "room:2"}) {
+		t.Errorf("unexpected keys %v", keys)
+	}
+
+	room := ""
+	if err := c.Get("room:1", &room); err != nil || room != "deluxe" {
+		t.Errorf("unexpected room %s %v", room, err)
+	}
+
+	bumped, _ := New(remote, &Option{Namespace: "hotel", Version: 2})
+	if err := bumped.Get("room:1", &room); errors.Cause(err) != redis.Nil {
+		t.Errorf("should miss keys of older versions, got %v", err)
+	}
+
+	_ = c.FlushDatabase()
+	if keys, _ := remote.Keys("*"); !reflect.DeepEqual(keys, []string{"other:room:1"}) {
+		t.Errorf("should only flush the namespace, got %v", keys)
+	}
+}
+
+func Test_Subscribe_strips_the_namespace(t *testing.T) {
+	remote, _ := memory.New(nil)
+	defer remote.Close()
+
+	c, _ := New(remote, &Option{Namespace: "hotel"})
+
+	sub, err := c.Subscribe("rooms")
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+	defer sub.Close()
+
+	var other cache.PubSub
+	if other, err = remote.Subscribe("rooms"); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+	defer other.Close()
+
+	if err := sub.Publish("booked"); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	select {
+	case message := <-sub.Channel():
+		if message.Channel != "rooms" || message.Payload != "booked" {
+			t.Errorf("unexpected message %v", message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("should receive the message")
+	}
+
+	select {
+	case message := <-other.Channel():
+		t.Errorf("should not receive messages of the namespace, got %v", message)
+	default:
+	}
+}
@@ -0,0 +1,172 @@
+package namespace
+
+import (
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/go-redis/redis"
+)
+
+type (
+	pipe struct {
+		cache.Pipe
+		n *namespaced
+	}
+
+	namespacedTx struct {
+		cache.Tx
+		n *namespaced
+	}
+)
+
+func (p *pipe) Set(key string, value interface{}) *cache.StatusResult {
+	return p.Pipe.Set(p.n.key(key), value)
+}
+
+func (p *pipe) SetWithExpiration(key string, value interface{}, expired time.Duration) *cache.StatusResult {
+	return p.Pipe.SetWithExpiration(p.n.key(key), value, expired)
+}
+
+func (p *pipe) Get(key string) *cache.ValueResult {
+	return p.Pipe.Get(p.n.key(key))
+}
+
+func (p *pipe) GetSet(key string, value interface{}) *cache.ValueResult {
+	return p.Pipe.GetSet(p.n.key(key), value)
+}
+
+func (p *pipe) MGet(keys ...string) *cache.ValuesResult {
+	return p.Pipe.MGet(p.n.keys(keys)...)
+}
+
+func (p *pipe) Remove(key string) *cache.IntResult {
+	return p.Pipe.Remove(p.n.key(key))
+}
+
+func (p *pipe) Incr(key string) *cache.IntResult {
+	return p.Pipe.Incr(p.n.key(key))
+}
+
+func (p *pipe) Decr(key string) *cache.IntResult {
+	return p.Pipe.Decr(p.n.key(key))
+}
+
+func (p *pipe) IncrBy(key string, value int64) *cache.IntResult {
+	return p.Pipe.IncrBy(p.n.key(key), value)
+}
+
+func (p *pipe) SetNX(key string, value interface{}, ttl time.Duration) *cache.BoolResult {
+	return p.Pipe.SetNX(p.n.key(key), value, ttl)
+}
+
+func (p *pipe) Expire(key string, ttl time.Duration) *cache.BoolResult {
+	return p.Pipe.Expire(p.n.key(key), ttl)
+}
+
+func (p *pipe) TTL(key string) *cache.DurationResult {
+	return p.Pipe.TTL(p.n.key(key))
+}
+
+func (p *pipe) Exists(keys ...string) *cache.IntResult {
+	return p.Pipe.Exists(p.n.keys(keys)...)
+}
+
+func (p *pipe) HSet(key, field string, value interface{}) *cache.StatusResult {
+	return p.Pipe.HSet(p.n.key(key), field, value)
+}
+
+func (p *pipe) HMSet(key string, value map[string]interface{}) *cache.StatusResult {
+	return p.Pipe.HMSet(p.n.key(key), value)
+}
+
+func (p *pipe) HGet(key, field string) *cache.ValueResult {
+	return p.Pipe.HGet(p.n.key(key), field)
+}
+
+func (p *pipe) HMGet(key string, fields ...string) *cache.ValuesResult {
+	return p.Pipe.HMGet(p.n.key(key), fields...)
+}
+
+func (p *pipe) HGetAll(key string) *cache.StringMapResult {
+	return p.Pipe.HGetAll(p.n.key(key))
+}
+
+func (p *pipe) LPush(key string, values ...interface{}) *cache.IntResult {
+	return p.Pipe.LPush(p.n.key(key), values...)
+}
+
+func (p *pipe) RPush(key string, values ...interface{}) *cache.IntResult {
+	return p.Pipe.RPush(p.n.key(key), values...)
+}
+
+func (p *pipe) LPop(key string) *cache.ValueResult {
+	return p.Pipe.LPop(p.n.key(key))
+}
+
+func (p *pipe) RPop(key string) *cache.ValueResult {
+	return p.Pipe.RPop(p.n.key(key))
+}
+
+func (p *pipe) LRange(key string, start, stop int64) *cache.ValuesResult {
+	return p.Pipe.LRange(p.n.key(key), start, stop)
+}
+
+func (p *pipe) SAdd(key string, members ...interface{}) *cache.IntResult {
+	return p.Pipe.SAdd(p.n.key(key), members...)
+}
+
+func (p *pipe) SRem(key string, members ...interface{}) *cache.IntResult {
+	return p.Pipe.SRem(p.n.key(key), members...)
+}
+
+func (p *pipe) SMembers(key string) *cache.ValuesResult {
+	return p.Pipe.SMembers(p.n.key(key))
+}
+
+func (p *pipe) SIsMember(key string, member interface{}) *cache.BoolResult {
+	return p.Pipe.SIsMember(p.n.key(key), member)
+}
+
+func (p *pipe) ZAdd(key string, members ...redis.Z) *cache.IntResult {
+	return p.Pipe.ZAdd(p.n.key(key), members...)
+}
+
+func (p *pipe) ZRem(key string, members ...interface{}) *cache.IntResult {
+	return p.Pipe.ZRem(p.n.key(key), members...)
+}
+
+func (p *pipe) ZIncrBy(key string, increment float64, member string) *cache.FloatResult {
+	return p.Pipe.ZIncrBy(p.n.key(key), increment, member)
+}
+
+func (p *pipe) ZRangeByScore(key string, by redis.ZRangeBy) *cache.ZResult {
+	return p.Pipe.ZRangeByScore(p.n.key(key), by)
+}
+
+func (p *pipe) ZRevRange(key string, start, stop int64) *cache.ZResult {
+	return p.Pipe.ZRevRange(p.n.key(key), start, stop)
+}
+
+func (t *namespacedTx) Get(key string, object interface{}) error {
+	return t.Tx.Get(t.n.key(key), object)
+}
+
+func (t *namespacedTx) HGet(key, field string, object interface{}) error {
+	return t.Tx.HGet(t.n.key(key), field, object)
+}
+
+func (t *namespacedTx) HGetAll(key string) (map[string]string, error) {
+	return t.Tx.HGetAll(t.n.key(key))
+}
+
+func (t *namespacedTx) Exists(keys ...string) (int64, error) {
+	return t.Tx.Exists(t.n.keys(keys)...)
+}
+
+func (t *namespacedTx) TTL(key string) (time.Duration, error) {
+	return t.Tx.TTL(t.n.key(key))
+}
+
+func (t *namespacedTx) Pipeline() cache.Pipe {
+	return &pipe{Pipe: t.Tx.Pipeline(), n: t.n}
+}
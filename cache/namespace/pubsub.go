@@ -0,0 +1,54 @@
+package namespace
+
+import (
+	"sync"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/go-redis/redis"
+)
+
+type (
+	// pubsub strips the namespace from the channel of the messages it receives.
+	pubsub struct {
+		cache.PubSub
+		n        *namespaced
+		once     sync.Once
+		messages chan *redis.Message
+	}
+)
+
+func (p *pubsub) Channel() <-chan *redis.Message {
+	p.once.Do(func() {
+		in := p.PubSub.Channel()
+		p.messages = make(chan *redis.Message, cap(in))
+
+		go func() {
+			defer close(p.messages)
+
+			for message := range in {
+				m := *message
+				m.Channel = p.n.strip(m.Channel)
+				m.Pattern = p.n.strip(m.Pattern)
+				p.messages <- &m
+			}
+		}()
+	})
+
+	return p.messages
+}
+
+func (p *pubsub) Subscribe(channels ...string) error {
+	return p.PubSub.Subscribe(p.n.keys(channels)...)
+}
+
+func (p *pubsub) PSubscribe(patterns ...string) error {
+	return p.PubSub.PSubscribe(p.n.keys(patterns)...)
+}
+
+func (p *pubsub) Unsubscribe(channels ...string) error {
+	return p.PubSub.Unsubscribe(p.n.keys(channels)...)
+}
+
+func (p *pubsub) PUnsubscribe(patterns ...string) error {
+	return p.PubSub.PUnsubscribe(p.n.keys(patterns)...)
+}
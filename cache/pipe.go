@@ -0,0 +1,415 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/codec"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+type (
+	redisPipe struct {
+		pipe      redis.Pipeliner
+		codec     codec.Codec
+		resolvers []func()
+	}
+)
+
+// NewRedisPipe returns a Pipe queuing commands on a go-redis pipeline or transaction, values are
+// encoded by c like the cache that created it.
+func NewRedisPipe(pipe redis.Pipeliner, c codec.Codec) Pipe {
+	return &redisPipe{pipe: pipe, codec: codec.Default(c)}
+}
+
+// resolve runs resolver on Exec, once the replies are read.
+func (p *redisPipe) resolve(resolver func()) {
+	p.resolvers = append(p.resolvers, resolver)
+}
+
+// missing wraps the redis.Nil reply of a missing key.
+func missing(err error, key string) error {
+	if err == redis.Nil {
+		return errors.Wrapf(err, "key %s does not exits", key)
+	}
+
+	return errors.Wrapf(err, "failed to run command on key %s", key)
+}
+
+func (p *redisPipe) marshalValues(values []interface{}) ([]interface{}, error) {
+	data := make([]interface{}, 0, len(values))
+	for n, value := range values {
+		d, err := p.codec.Marshal(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal value %d", n)
+		}
+		data = append(data, d)
+	}
+
+	return data, nil
+}
+
+func (p *redisPipe) Set(key string, value interface{}) *StatusResult {
+	return p.SetWithExpiration(key, value, 0)
+}
+
+func (p *redisPipe) SetWithExpiration(key string, value interface{}, expired time.Duration) *StatusResult {
+	r := &StatusResult{}
+
+	data, err := p.codec.Marshal(value)
+	if err != nil {
+		r.Resolve(errors.Wrapf(err, "failed to marshal key %s", key))
+		return r
+	}
+
+	cmd := p.pipe.Set(key, data, expired)
+	p.resolve(func() { r.Resolve(missing(cmd.Err(), key)) })
+	return r
+}
+
+func (p *redisPipe) Get(key string) *ValueResult {
+	r := NewValueResult(p.codec)
+	cmd := p.pipe.Get(key)
+	p.resolve(func() {
+		data, err := cmd.Bytes()
+		r.Resolve(data, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) GetSet(key string, value interface{}) *ValueResult {
+	r := NewValueResult(p.codec)
+
+	data, err := p.codec.Marshal(value)
+	if err != nil {
+		r.Resolve(nil, errors.Wrapf(err, "failed to marshal key %s", key))
+		return r
+	}
+
+	cmd := p.pipe.GetSet(key, data)
+	p.resolve(func() {
+		data, err := cmd.Bytes()
+		r.Resolve(data, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) MGet(keys ...string) *ValuesResult {
+	r := NewValuesResult(p.codec)
+	cmd := p.pipe.MGet(keys...)
+	p.resolve(func() {
+		vals, err := cmd.Result()
+		r.Resolve(vals, errors.Wrapf(err, "failed to get keys %s", keys))
+	})
+	return r
+}
+
+func (p *redisPipe) Remove(key string) *IntResult {
+	r := &IntResult{}
+	cmd := p.pipe.Del(key)
+	p.resolve(func() {
+		val, err := cmd.Result()
+		r.Resolve(val, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) Incr(key string) *IntResult {
+	return p.IncrBy(key, 1)
+}
+
+func (p *redisPipe) Decr(key string) *IntResult {
+	return p.IncrBy(key, -1)
+}
+
+func (p *redisPipe) IncrBy(key string, value int64) *IntResult {
+	r := &IntResult{}
+	cmd := p.pipe.IncrBy(key, value)
+	p.resolve(func() {
+		val, err := cmd.Result()
+		r.Resolve(val, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) SetNX(key string, value interface{}, ttl time.Duration) *BoolResult {
+	r := &BoolResult{}
+
+	data, err := p.codec.Marshal(value)
+	if err != nil {
+		r.Resolve(false, errors.Wrapf(err, "failed to marshal key %s", key))
+		return r
+	}
+
+	cmd := p.pipe.SetNX(key, data, ttl)
+	p.resolve(func() {
+		val, err := cmd.Result()
+		r.Resolve(val, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) Expire(key string, ttl time.Duration) *BoolResult {
+	r := &BoolResult{}
+	cmd := p.pipe.PExpire(key, ttl)
+	p.resolve(func() {
+		val, err := cmd.Result()
+		r.Resolve(val, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) TTL(key string) *DurationResult {
+	r := &DurationResult{}
+	cmd := p.pipe.PTTL(key)
+	p.resolve(func() {
+		val, err := cmd.Result()
+		r.Resolve(RedisTTL(val), missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) Exists(keys ...string) *IntResult {
+	r := &IntResult{}
+	cmd := p.pipe.Exists(keys...)
+	p.resolve(func() {
+		val, err := cmd.Result()
+		r.Resolve(val, errors.Wrapf(err, "failed to check keys %s", keys))
+	})
+	return r
+}
+
+func (p *redisPipe) HSet(key, field string, value interface{}) *StatusResult {
+	r := &StatusResult{}
+
+	data, err := p.codec.Marshal(value)
+	if err != nil {
+		r.Resolve(errors.Wrapf(err, "failed to marshal field %s of key %s", field, key))
+		return r
+	}
+
+	cmd := p.pipe.HSet(key, field, data)
+	p.resolve(func() { r.Resolve(missing(cmd.Err(), key)) })
+	return r
+}
+
+func (p *redisPipe) HMSet(key string, value map[string]interface{}) *StatusResult {
+	r := &StatusResult{}
+
+	fields := make(map[string]interface{}, len(value))
+	for field, v := range value {
+		data, err := p.codec.Marshal(v)
+		if err != nil {
+			r.Resolve(errors.Wrapf(err, "failed to marshal field %s of key %s", field, key))
+			return r
+		}
+		fields[field] = data
+	}
+
+	cmd := p.pipe.HMSet(key, fields)
+	p.resolve(func() { r.Resolve(missing(cmd.Err(), key)) })
+	return r
+}
+
+func (p *redisPipe) HGet(key, field string) *ValueResult {
+	r := NewValueResult(p.codec)
+	cmd := p.pipe.HGet(key, field)
+	p.resolve(func() {
+		data, err := cmd.Bytes()
+		r.Resolve(data, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) HMGet(key string, fields ...string) *ValuesResult {
+	r := NewValuesResult(p.codec)
+	cmd := p.pipe.HMGet(key, fields...)
+	p.resolve(func() {
+		vals, err := cmd.Result()
+		r.Resolve(vals, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) HGetAll(key string) *StringMapResult {
+	r := &StringMapResult{}
+	cmd := p.pipe.HGetAll(key)
+	p.resolve(func() {
+		val, err := cmd.Result()
+		r.Resolve(val, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) LPush(key string, values ...interface{}) *IntResult {
+	return p.push(key, values, p.pipe.LPush)
+}
+
+func (p *redisPipe) RPush(key string, values ...interface{}) *IntResult {
+	return p.push(key, values, p.pipe.RPush)
+}
+
+func (p *redisPipe) push(key string, values []interface{}, push func(string, ...interface{}) *redis.IntCmd) *IntResult {
+	r := &IntResult{}
+
+	data, err := p.marshalValues(values)
+	if err != nil {
+		r.Resolve(0, errors.Wrapf(err, "failed to push key %s", key))
+		return r
+	}
+
+	cmd := push(key, data...)
+	p.resolve(func() {
+		val, err := cmd.Result()
+		r.Resolve(val, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) LPop(key string) *ValueResult {
+	return p.pop(key, p.pipe.LPop(key))
+}
+
+func (p *redisPipe) RPop(key string) *ValueResult {
+	return p.pop(key, p.pipe.RPop(key))
+}
+
+func (p *redisPipe) pop(key string, cmd *redis.StringCmd) *ValueResult {
+	r := NewValueResult(p.codec)
+	p.resolve(func() {
+		data, err := cmd.Bytes()
+		r.Resolve(data, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) LRange(key string, start, stop int64) *ValuesResult {
+	return p.strings(key, p.pipe.LRange(key, start, stop))
+}
+
+func (p *redisPipe) SMembers(key string) *ValuesResult {
+	return p.strings(key, p.pipe.SMembers(key))
+}
+
+func (p *redisPipe) strings(key string, cmd *redis.StringSliceCmd) *ValuesResult {
+	r := NewValuesResult(p.codec)
+	p.resolve(func() {
+		val, err := cmd.Result()
+
+		vals := make([]interface{}, len(val))
+		for n, v := range val {
+			vals[n] = v
+		}
+		r.Resolve(vals, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) SAdd(key string, members ...interface{}) *IntResult {
+	return p.push(key, members, p.pipe.SAdd)
+}
+
+func (p *redisPipe) SRem(key string, members ...interface{}) *IntResult {
+	return p.push(key, members, p.pipe.SRem)
+}
+
+func (p *redisPipe) SIsMember(key string, member interface{}) *BoolResult {
+	r := &BoolResult{}
+
+	data, err := p.codec.Marshal(member)
+	if err != nil {
+		r.Resolve(false, errors.Wrapf(err, "failed to marshal member of key %s", key))
+		return r
+	}
+
+	cmd := p.pipe.SIsMember(key, data)
+	p.resolve(func() {
+		val, err := cmd.Result()
+		r.Resolve(val, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) ZAdd(key string, members ...redis.Z) *IntResult {
+	r := &IntResult{}
+	cmd := p.pipe.ZAdd(key, members...)
+	p.resolve(func() {
+		val, err := cmd.Result()
+		r.Resolve(val, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) ZRem(key string, members ...interface{}) *IntResult {
+	r := &IntResult{}
+	cmd := p.pipe.ZRem(key, members...)
+	p.resolve(func() {
+		val, err := cmd.Result()
+		r.Resolve(val, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) ZIncrBy(key string, increment float64, member string) *FloatResult {
+	r := &FloatResult{}
+	cmd := p.pipe.ZIncrBy(key, increment, member)
+	p.resolve(func() {
+		val, err := cmd.Result()
+		r.Resolve(val, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) ZRangeByScore(key string, by redis.ZRangeBy) *ZResult {
+	return p.z(key, p.pipe.ZRangeByScoreWithScores(key, by))
+}
+
+func (p *redisPipe) ZRevRange(key string, start, stop int64) *ZResult {
+	return p.z(key, p.pipe.ZRevRangeWithScores(key, start, stop))
+}
+
+func (p *redisPipe) z(key string, cmd *redis.ZSliceCmd) *ZResult {
+	r := &ZResult{}
+	p.resolve(func() {
+		val, err := cmd.Result()
+		r.Resolve(val, missing(err, key))
+	})
+	return r
+}
+
+func (p *redisPipe) Exec() error {
+	cmds, err := p.pipe.Exec()
+
+	resolvers := p.resolvers
+	p.resolvers = nil
+
+	for _, resolve := range resolvers {
+		resolve()
+	}
+
+	// - misses are only reported by their results
+	if err != redis.Nil {
+		return errors.Wrap(err, "failed to exec pipeline")
+	}
+
+	for _, cmd := range cmds {
+		if err := cmd.Err(); err != nil && err != redis.Nil {
+			return errors.Wrap(err, "failed to exec pipeline")
+		}
+	}
+
+	return nil
+}
+
+// RedisTTL converts the millisecond replies of PTTL for missing and persistent keys to NotExists
+// and NoExpiration.
+func RedisTTL(val time.Duration) time.Duration {
+	switch val {
+	case -time.Millisecond:
+		return NoExpiration
+	case -2 * time.Millisecond:
+		return NotExists
+	}
+
+	return val
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+func Test_RedisPipe_resolves_results_on_Exec(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+	defer server.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	p := NewRedisPipe(client.Pipeline(), nil)
+	p.Set("hotel", "Ayana")
+	hotel := p.Get("hotel")
+	missing := p.Get("missing")
+	count := p.Incr("count")
+
+	if count.Err() != ErrNotExecuted {
+		t.Errorf("should not resolve before Exec, got %v", count.Err())
+	}
+
+	if err := p.Exec(); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	name := ""
+	if err := hotel.Scan(&name); err != nil || name != "Ayana" {
+		t.Errorf("unexpected name %s %v", name, err)
+	}
+
+	if err := missing.Scan(&name); errors.Cause(err) != redis.Nil {
+		t.Errorf("should miss, got %v", err)
+	}
+
+	if count.Val() != 1 {
+		t.Errorf("unexpected count %d", count.Val())
+	}
+}
+
+func Test_RedisTx_fails_on_changed_keys(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+	defer server.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	err = client.Watch(func(tx *redis.Tx) error {
+		_ = server.Set("stock", "0")
+
+		p := NewRedisTx(tx, nil).Pipeline()
+		set := p.Set("stock", "1")
+		err := p.Exec()
+
+		if errors.Cause(set.Err()) != ErrTxFailed {
+			t.Errorf("should fail the queued command, got %v", set.Err())
+		}
+		return err
+	}, "stock")
+
+	if errors.Cause(err) != ErrTxFailed {
+		t.Errorf("should fail the transaction, got %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+// Package pool implements cache.Pool over named caches, e.g. one per logical DB or one per shard.
+// Keys are spread across standalone instances with a consistent-hash ring, so adding or removing
+// an instance only moves the keys it owns.
+package pool
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultHealthCheckInterval = 10 * time.Second
+	DefaultReplicas            = 100
+)
+
+type (
+	Option struct {
+		// Clients are the caches of the pool by name. Names place them on the hash ring, renaming a
+		// client moves its keys.
+		Clients map[string]cache.Cache
+		// HealthCheckInterval is how often every client is pinged, unhealthy ones are skipped.
+		HealthCheckInterval time.Duration
+		// Replicas is the number of points of each client on the hash ring.
+		Replicas int
+	}
+
+	Pool interface {
+		cache.Pool
+		// Get returns the client named name.
+		Get(name string) (cache.Cache, error)
+		// Shard returns the client owning key on the hash ring, the next healthy one while it is
+		// unhealthy. Only the {hash tag} of a key is hashed, like redis-cluster.
+		Shard(key string) cache.Cache
+	}
+
+	member struct {
+		name    string
+		client  cache.Cache
+		healthy int32
+	}
+
+	point struct {
+		hash   uint32
+		member *member
+	}
+
+	pool struct {
+		members []*member
+		names   map[string]*member
+		ring    []point
+		next    uint32
+		stop    chan struct{}
+		once    sync.Once
+	}
+)
+
+func New(option *Option) (Pool, error) {
+	opt := Option{}
+	if option != nil {
+		opt = *option
+	}
+
+	if len(opt.Clients) == 0 {
+		return nil, errors.New("clients are required!")
+	}
+
+	if opt.HealthCheckInterval <= 0 {
+		opt.HealthCheckInterval = DefaultHealthCheckInterval
+	}
+
+	if opt.Replicas <= 0 {
+		opt.Replicas = DefaultReplicas
+	}
+
+	p := &pool{
+		names: make(map[string]*member, len(opt.Clients)),
+		stop:  make(chan struct{}),
+	}
+
+	for name, client := range opt.Clients {
+		if client == nil {
+			return nil, errors.Errorf("client %s is required!", name)
+		}
+
+		m := &member{name: name, client: client}
+		p.members = append(p.members, m)
+		p.names[name] = m
+
+		for n := 0; n < opt.Replicas; n++ {
+			p.ring = append(p.ring, point{hash: crc32.ChecksumIEEE([]byte(name + "-" + strconv.Itoa(n))), member: m})
+		}
+	}
+
+	sort.Slice(p.members, func(i, j int) bool {
+		return p.members[i].name < p.members[j].name
+	})
+
+	sort.Slice(p.ring, func(i, j int) bool {
+		if p.ring[i].hash != p.ring[j].hash {
+			return p.ring[i].hash < p.ring[j].hash
+		}
+		return p.ring[i].member.name < p.ring[j].member.name
+	})
+
+	p.check()
+	go p.watch(opt.HealthCheckInterval)
+
+	return p, nil
+}
+
+func (p *pool) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.check()
+		}
+	}
+}
+
+// check pings every client at once and records whether it is healthy.
+func (p *pool) check() {
+	var wg sync.WaitGroup
+
+	for _, m := range p.members {
+		wg.Add(1)
+		go func(m *member) {
+			defer wg.Done()
+
+			healthy := int32(0)
+			if err := m.client.Ping(); err == nil {
+				healthy = 1
+			}
+			atomic.StoreInt32(&m.healthy, healthy)
+		}(m)
+	}
+
+	wg.Wait()
+}
+
+func (m *member) isHealthy() bool {
+	return atomic.LoadInt32(&m.healthy) == 1
+}
+
+// Use runs callback with the next healthy client.
+func (p *pool) Use(callback cache.PoolCallback) {
+	callback(p.Client())
+}
+
+// Client returns the healthy clients in turn, or any client when none is healthy so callers get
+// its error.
+func (p *pool) Client() cache.Cache {
+	start := atomic.AddUint32(&p.next, 1)
+
+	for n := range p.members {
+		m := p.members[(int(start)+n)%len(p.members)]
+		if m.isHealthy() {
+			return m.client
+		}
+	}
+
+	return p.members[int(start)%len(p.members)].client
+}
+
+func (p *pool) Get(name string) (cache.Cache, error) {
+	m, ok := p.names[name]
+	if !ok {
+		return nil, errors.Errorf("client %s does not exist!", name)
+	}
+
+	return m.client, nil
+}
+
+func (p *pool) Shard(key string) cache.Cache {
+	hash := crc32.ChecksumIEEE([]byte(hashTag(key)))
+	start := sort.Search(len(p.ring), func(i int) bool {
+		return p.ring[i].hash >= hash
+	})
+
+	for n := range p.ring {
+		if m := p.ring[(start+n)%len(p.ring)].member; m.isHealthy() {
+			return m.client
+		}
+	}
+
+	return p.ring[start%len(p.ring)].member.client
+}
+
+// hashTag returns the part of key between the first { and the next }, key itself when there is none.
+func hashTag(key string) string {
+	start := strings.IndexByte(key, '{')
+	if start < 0 {
+		return key
+	}
+
+	end := strings.IndexByte(key[start+1:], '}')
+	if end <= 0 {
+		return key
+	}
+
+	return key[start+1 : start+1+end]
+}
+
+// Close stops the health checks and closes every client, it returns the first error.
+func (p *pool) Close() error {
+	var err error
+
+	p.once.Do(func() {
+		close(p.stop)
+
+		for _, m := range p.members {
+			if e := m.client.Close(); e != nil && err == nil {
+				err = errors.Wrapf(e, "failed to close client %s!", m.name)
+			}
+		}
+	})
+
+	return err
+}
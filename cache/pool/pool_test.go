@@ -0,0 +1,87 @@
+package pool
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/memory"
+)
+
+func newPool(t *testing.T, names ...string) (Pool, map[string]cache.Cache) {
+	clients := make(map[string]cache.Cache)
+	for _, name := range names {
+		c, err := memory.New(nil)
+		if err != nil {
+			t.Fatalf("should not error %s", err)
+		}
+		clients[name] = c
+	}
+
+	p, err := New(&Option{Clients: clients})
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	return p, clients
+}
+
+func Test_Shard_spreads_keys_and_skips_unhealthy_clients(t *testing.T) {
+	p, clients := newPool(t, "a", "b", "c")
+	defer p.Close()
+
+	owners := make(map[cache.Cache]int)
+	for n := 0; n < 300; n++ {
+		key := fmt.Sprintf("hotel:%d", n)
+		if p.Shard(key) != p.Shard(key) {
+			t.Fatalf("should shard %s to the same client", key)
+		}
+		owners[p.Shard(key)]++
+	}
+
+	if len(owners) != 3 {
+		t.Errorf("should spread keys on every client, got %v", owners)
+	}
+
+	if p.Shard("{hotel:1}:rooms") != p.Shard("hotel:1") {
+		t.Errorf("should shard by hash tag")
+	}
+
+	owner := p.Shard("hotel:1")
+	_ = owner.Close()
+	p.(*pool).check()
+
+	if p.Shard("hotel:1") == owner {
+		t.Errorf("should skip the unhealthy client")
+	}
+
+	for n := 0; n < 10; n++ {
+		p.Use(func(client cache.Cache) {
+			if client == owner {
+				t.Errorf("should not use the unhealthy client")
+			}
+		})
+	}
+
+	if c, err := p.Get("a"); err != nil || c != clients["a"] {
+		t.Errorf("unexpected client %v %v", c, err)
+	}
+
+	if _, err := p.Get("d"); err == nil {
+		t.Errorf("should not get a missing client")
+	}
+}
+
+func Test_Close_closes_every_client(t *testing.T) {
+	p, clients := newPool(t, "a", "b")
+
+	if err := p.Close(); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	for name, c := range clients {
+		if c.Ping() == nil {
+			t.Errorf("should close client %s", name)
+		}
+	}
+}
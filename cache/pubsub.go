@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"log"
+	"sync"
+
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+type (
+	// MessageHandler handles a message received by Handle.
+	MessageHandler func(message *redis.Message) error
+	// ErrorHandler is given the message whose handler failed or panicked.
+	ErrorHandler func(message *redis.Message, err error)
+
+	redisPubSub struct {
+		client   redis.Cmdable
+		p        *redis.PubSub
+		mu       sync.Mutex
+		channels []string
+		onClose  func()
+		once     sync.Once
+	}
+)
+
+// Handle runs handler for every message of p until p is closed. The errors and panics of handler
+// are given to onError with their message and the next message is handled, they are logged when
+// onError is nil.
+func Handle(p PubSub, handler MessageHandler, onError ErrorHandler) {
+	for message := range p.Channel() {
+		if err := handle(handler, message); err != nil {
+			if onError == nil {
+				log.Printf("failed to handle message of cn %s: %s", message.Channel, err)
+				continue
+			}
+			onError(message, err)
+		}
+	}
+}
+
+func handle(handler MessageHandler, message *redis.Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("handler panicked: %v", r)
+		}
+	}()
+
+	return handler(message)
+}
+
+// NewRedisPubSub returns the PubSub of a go-redis subscription to channels, Publish sends to the
+// first of them with client. go-redis reconnects after a connection loss and subscribes again to
+// every channel and pattern. onClose is called by the first Close.
+func NewRedisPubSub(client redis.Cmdable, p *redis.PubSub, channels []string, onClose func()) PubSub {
+	return &redisPubSub{client: client, p: p, channels: append([]string(nil), channels...), onClose: onClose}
+}
+
+func (p *redisPubSub) Receive() error {
+	if _, err := p.p.Receive(); err != nil {
+		return errors.Wrap(err, "failed to receive")
+	}
+
+	return nil
+}
+
+func (p *redisPubSub) Publish(message string) error {
+	p.mu.Lock()
+	if len(p.channels) == 0 {
+		p.mu.Unlock()
+		return errors.New("no channel to publish to!")
+	}
+	channel := p.channels[0]
+	p.mu.Unlock()
+
+	if err := p.client.Publish(channel, message).Err(); err != nil {
+		return errors.Wrapf(err, "failed to publish message to cn %s", channel)
+	}
+
+	return nil
+}
+
+func (p *redisPubSub) Channel() <-chan *redis.Message {
+	return p.p.Channel()
+}
+
+func (p *redisPubSub) Subscribe(channels ...string) error {
+	if err := p.p.Subscribe(channels...); err != nil {
+		return errors.Wrapf(err, "failed to subscribe cn %s", channels)
+	}
+
+	p.mu.Lock()
+	p.channels = append(p.channels, channels...)
+	p.mu.Unlock()
+
+	return nil
+}
+
+func (p *redisPubSub) PSubscribe(patterns ...string) error {
+	if err := p.p.PSubscribe(patterns...); err != nil {
+		return errors.Wrapf(err, "failed to subscribe patterns %s", patterns)
+	}
+
+	return nil
+}
+
+func (p *redisPubSub) Unsubscribe(channels ...string) error {
+	if err := p.p.Unsubscribe(channels...); err != nil {
+		return errors.Wrapf(err, "failed to unsubscribe cn %s", channels)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(channels) == 0 {
+		p.channels = nil
+		return nil
+	}
+
+	removed := make(map[string]struct{}, len(channels))
+	for _, channel := range channels {
+		removed[channel] = struct{}{}
+	}
+
+	kept := p.channels[:0]
+	for _, channel := range p.channels {
+		if _, ok := removed[channel]; !ok {
+			kept = append(kept, channel)
+		}
+	}
+	p.channels = kept
+
+	return nil
+}
+
+func (p *redisPubSub) PUnsubscribe(patterns ...string) error {
+	if err := p.p.PUnsubscribe(patterns...); err != nil {
+		return errors.Wrapf(err, "failed to unsubscribe patterns %s", patterns)
+	}
+
+	return nil
+}
+
+func (p *redisPubSub) Close() error {
+	var err error
+
+	p.once.Do(func() {
+		if p.onClose != nil {
+			p.onClose()
+		}
+		err = errors.WithStack(p.p.Close())
+	})
+
+	return err
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+)
+
+func Test_RedisPubSub_receives_channels_and_patterns(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+	defer server.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	closed := false
+	p := NewRedisPubSub(client, client.PSubscribe("hotel:*"), nil, func() { closed = true })
+	if err := p.Subscribe("room"); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	// - waits for both subscriptions before publishing
+	for n := 0; n < 2; n++ {
+		if err := p.Receive(); err != nil {
+			t.Fatalf("should not error %s", err)
+		}
+	}
+
+	if err := p.Publish("booked"); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+	_ = client.Publish("hotel:1", "updated").Err()
+
+	for _, expected := range []string{" room booked", "hotel:* hotel:1 updated"} {
+		select {
+		case message := <-p.Channel():
+			if got := message.Pattern + " " + message.Channel + " " + message.Payload; got != expected {
+				t.Errorf("unexpected message %s, expected %s", got, expected)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("should receive %s", expected)
+		}
+	}
+
+	_ = p.Close()
+	_ = p.Close()
+
+	if !closed {
+		t.Errorf("should call onClose")
+	}
+}
@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+
+	shared_dto "github.com/PAWSOME-INDONESIA/paw-utilities-go/shared/dto"
+)
+
+// KeyFunc returns the key a request is limited on, requests with an empty key aren't limited.
+type KeyFunc func(r *http.Request) string
+
+// ByIP keys on the client IP, see RealIP. Without trusted proxies it keys on the remote address, as
+// forwarding headers sent straight to the server can be set to anything by the client.
+func ByIP(trusted ...*net.IPNet) KeyFunc {
+	return func(r *http.Request) string {
+		return "ip:" + RealIP(r, trusted...)
+	}
+}
+
+// ByHeader keys on the value of header, requests without it aren't limited.
+func ByHeader(header string) KeyFunc {
+	return func(r *http.Request) string {
+		value := r.Header.Get(header)
+		if value == "" {
+			return ""
+		}
+
+		return "header:" + header + ":" + value
+	}
+}
+
+// ByMandatory keys on fields of the MandatoryRequestDto, named by their json tag e.g. "storeId" or
+// "username". Requests carrying none of the fields aren't limited.
+func ByMandatory(fields ...string) KeyFunc {
+	return func(r *http.Request) string {
+		values := mandatoryValues(r)
+		parts := make([]string, 0, len(fields))
+		found := false
+
+		for _, field := range fields {
+			value := values[field]
+			if value != "" {
+				found = true
+			}
+			parts = append(parts, field+"="+value)
+		}
+
+		if !found {
+			return ""
+		}
+
+		return "mandatory:" + strings.Join(parts, ",")
+	}
+}
+
+// Compose keys on every KeyFunc together, e.g. a limit per store and IP. Requests with an empty
+// key for any of them aren't limited.
+func Compose(keys ...KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			part := key(r)
+			if part == "" {
+				return ""
+			}
+			parts = append(parts, part)
+		}
+
+		return strings.Join(parts, "|")
+	}
+}
+
+// RealIP returns the client IP of r. The forwarding headers are only read when the remote address is
+// one of the trusted proxies: X-Forwarded-For is walked from the right and its first address that
+// isn't a trusted proxy is the client, X-Real-IP is used when it's missing.
+func RealIP(r *http.Request, trusted ...*net.IPNet) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+
+	if !isTrusted(remote, trusted) {
+		return remote
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		ips := strings.Split(forwarded, ",")
+		for n := len(ips) - 1; n >= 0; n-- {
+			ip := strings.TrimSpace(ips[n])
+			if !isTrusted(ip, trusted) || n == 0 {
+				return ip
+			}
+		}
+	}
+
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+
+	return remote
+}
+
+// isTrusted reports whether ip is in one of the trusted proxy networks.
+func isTrusted(ip string, trusted []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, network := range trusted {
+		if network != nil && network.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mandatoryValues reads the string fields of the MandatoryRequestDto from the headers of r, falling
+// back to its query parameters.
+func mandatoryValues(r *http.Request) map[string]string {
+	values := make(map[string]string)
+	kind := reflect.TypeOf(shared_dto.MandatoryRequestDto{})
+
+	for n := 0; n < kind.NumField(); n++ {
+		name := strings.Split(kind.Field(n).Tag.Get("json"), ",")[0]
+
+		value := r.Header.Get(name)
+		if value == "" {
+			value = r.URL.Query().Get(name)
+		}
+		values[name] = value
+	}
+
+	return values
+}
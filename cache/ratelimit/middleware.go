@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	paw_http "github.com/PAWSOME-INDONESIA/paw-utilities-go/http"
+	shared_dto "github.com/PAWSOME-INDONESIA/paw-utilities-go/shared/dto"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/util/tiketerror"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+// Middleware limits the requests of an echo server per key. Requests pass when the cache fails.
+func Middleware(l Limiter, key KeyFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			result, err := allow(l, key, c.Request())
+			if err != nil {
+				c.Logger().Errorf("%+v\n", err)
+				return next(c)
+			}
+
+			if result != nil {
+				if body, rejection := respond(c.Response().Header(), result); rejection != nil {
+					return c.JSON(rejection.GetHTTPStatus(), body)
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// Handler limits the requests of a http.Server per key, register it with Use or as a route handler.
+// Requests pass when the cache fails.
+func Handler(l Limiter, key KeyFunc) paw_http.HandlerFunc {
+	return func(c paw_http.RequestContext) error {
+		result, err := allow(l, key, c.Request())
+		if err != nil {
+			if c.Logger() != nil {
+				c.Logger().Errorf("%+v\n", err)
+			}
+			return nil
+		}
+
+		if result != nil {
+			// - the error stops the chain, the response is already written
+			if body, rejection := respond(c.Response().Header(), result); rejection != nil {
+				if err := c.JSON(rejection.GetHTTPStatus(), body); err != nil {
+					return err
+				}
+				return rejection
+			}
+		}
+
+		return nil
+	}
+}
+
+// allow returns a nil result for requests that aren't limited.
+func allow(l Limiter, key KeyFunc, r *http.Request) (*Result, error) {
+	k := key(r)
+	if k == "" {
+		return nil, nil
+	}
+
+	return l.Allow(k)
+}
+
+// respond sets the rate limit headers, it returns the TOO_MANY_REQUEST response of a rejected request.
+func respond(header http.Header, result *Result) (*shared_dto.BaseResponseDto, tiketerror.ErrorStandard) {
+	header.Set("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+	header.Set("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+
+	if result.Allowed {
+		return nil, nil
+	}
+
+	if result.RetryAfter > 0 {
+		seconds := (result.RetryAfter + time.Second - 1) / time.Second
+		header.Set("Retry-After", strconv.FormatInt(int64(seconds), 10))
+	}
+
+	err := tiketerror.New(tiketerror.TOO_MANY_REQUEST, errors.New("too many requests"))
+
+	return &shared_dto.BaseResponseDto{
+		Code:       err.GetCode(),
+		Message:    err.GetMessage(),
+		Data:       nil,
+		Errors:     err.GetErrors(),
+		ServerTime: time.Now().Unix(),
+	}, err
+}
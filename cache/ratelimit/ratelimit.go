@@ -0,0 +1,254 @@
+// Package ratelimit limits requests per key on the redis caches. Every algorithm runs as a single
+// Lua script, so instances sharing a cache share the limit.
+package ratelimit
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+type Algorithm int
+
+const (
+	// FixedWindow counts requests in consecutive windows, a burst of twice the limit can pass
+	// around the edge of two windows.
+	FixedWindow Algorithm = iota
+	// SlidingWindow logs every request of the last window, it is exact but stores one entry per request.
+	SlidingWindow
+	// TokenBucket refills Limit tokens per Window up to Burst, a request takes one token.
+	TokenBucket
+)
+
+const (
+	DefaultWindow = time.Second
+	DefaultPrefix = "ratelimit:"
+)
+
+var (
+	fixedWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+
+local count = tonumber(redis.call("get", KEYS[1]) or "0")
+local ttl = redis.call("pttl", KEYS[1])
+
+if count + n > limit then
+	return {0, limit - count, ttl}
+end
+
+count = redis.call("incrby", KEYS[1], n)
+if ttl < 0 then
+	redis.call("pexpire", KEYS[1], window)
+end
+
+return {1, limit - count, 0}`)
+
+	slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local now = tonumber(ARGV[4])
+
+redis.call("zremrangebyscore", KEYS[1], "-inf", now - window)
+local count = redis.call("zcard", KEYS[1])
+
+if count + n > limit then
+	-- the request passes once the entries taking its place leave the window
+	local index = count + n - limit - 1
+	local entry = redis.call("zrange", KEYS[1], index, index, "withscores")
+	return {0, limit - count, tonumber(entry[2]) + window - now}
+end
+
+for i = 1, n do
+	redis.call("zadd", KEYS[1], now, ARGV[5] .. ":" .. i)
+end
+redis.call("pexpire", KEYS[1], window)
+
+return {1, limit - count - n, 0}`)
+
+	tokenBucketScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local now = tonumber(ARGV[4])
+
+local bucket = redis.call("hmget", KEYS[1], "tokens", "ts")
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= n then
+	tokens = tokens - n
+	allowed = 1
+else
+	retry = math.ceil((n - tokens) / rate)
+end
+
+redis.call("hmset", KEYS[1], "tokens", tokens, "ts", now)
+redis.call("pexpire", KEYS[1], math.ceil(burst / rate))
+
+return {allowed, math.floor(tokens), retry}`)
+)
+
+type (
+	Option struct {
+		Algorithm Algorithm
+		// Limit is the number of requests allowed per Window.
+		Limit  int64
+		Window time.Duration
+		// Burst is the size of the token bucket, it defaults to Limit.
+		Burst int64
+		// Prefix is prepended to every key.
+		Prefix string
+	}
+
+	Limiter interface {
+		// Allow takes one request from the limit of key.
+		Allow(key string) (*Result, error)
+		// AllowN takes n requests from the limit of key, either all of them or none.
+		AllowN(key string, n int64) (*Result, error)
+	}
+
+	Result struct {
+		Allowed bool
+		// Limit is the number of requests allowed per window, or the burst of a token bucket.
+		Limit int64
+		// Remaining is the number of requests left after this one.
+		Remaining int64
+		// RetryAfter is how long a rejected request waits before it can pass, -1 when it never can
+		// because it asks more than the limit.
+		RetryAfter time.Duration
+	}
+
+	limiter struct {
+		client redis.Cmdable
+		option Option
+	}
+)
+
+// New returns a Limiter on a redis, redis-cluster or redis-universal cache. Sliding windows and token
+// buckets are timed by the clock of the instances, which should be kept in sync.
+func New(c cache.Cache, option *Option) (Limiter, error) {
+	if c == nil {
+		return nil, errors.New("cache is required!")
+	}
+
+	client, ok := cache.Cmdable(c)
+	if !ok {
+		return nil, errors.New("cache is not a redis cache")
+	}
+
+	if option == nil || option.Limit <= 0 {
+		return nil, errors.New("limit is required!")
+	}
+
+	opt := *option
+
+	if opt.Algorithm < FixedWindow || opt.Algorithm > TokenBucket {
+		return nil, errors.Errorf("unknown algorithm %d", opt.Algorithm)
+	}
+
+	if opt.Window <= 0 {
+		opt.Window = DefaultWindow
+	}
+
+	if opt.Burst <= 0 {
+		opt.Burst = opt.Limit
+	}
+
+	if opt.Prefix == "" {
+		opt.Prefix = DefaultPrefix
+	}
+
+	return &limiter{client: client, option: opt}, nil
+}
+
+func (l *limiter) Allow(key string) (*Result, error) {
+	return l.AllowN(key, 1)
+}
+
+func (l *limiter) AllowN(key string, n int64) (*Result, error) {
+	limit := l.option.Limit
+	if l.option.Algorithm == TokenBucket {
+		limit = l.option.Burst
+	}
+
+	if n > limit {
+		return &Result{Limit: limit, RetryAfter: -1}, nil
+	}
+
+	var (
+		keys   = []string{l.option.Prefix + key}
+		window = int64(l.option.Window / time.Millisecond)
+		now    = time.Now().UnixNano() / int64(time.Millisecond)
+		reply  interface{}
+		err    error
+	)
+
+	switch l.option.Algorithm {
+	case FixedWindow:
+		reply, err = fixedWindowScript.Run(l.client, keys, limit, window, n).Result()
+	case SlidingWindow:
+		var id string
+		if id, err = requestID(); err != nil {
+			return nil, err
+		}
+		reply, err = slidingWindowScript.Run(l.client, keys, limit, window, n, now, id).Result()
+	case TokenBucket:
+		rate := strconv.FormatFloat(float64(l.option.Limit)/float64(window), 'f', -1, 64)
+		reply, err = tokenBucketScript.Run(l.client, keys, limit, rate, n, now).Result()
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to limit key %s", key)
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 3 {
+		return nil, errors.Errorf("unexpected reply %v of key %s", reply, key)
+	}
+
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	retry, _ := values[2].(int64)
+
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	if retry < 0 {
+		retry = 0
+	}
+
+	return &Result{
+		Allowed:    allowed == 1,
+		Limit:      limit,
+		Remaining:  remaining,
+		RetryAfter: time.Duration(retry) * time.Millisecond,
+	}, nil
+}
+
+// requestID tells apart the entries of requests logged in the same millisecond.
+func requestID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", errors.Wrap(err, "failed to generate request id")
+	}
+
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/internal/cachetest"
+	shared_dto "github.com/PAWSOME-INDONESIA/paw-utilities-go/shared/dto"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/util/tiketerror"
+	"github.com/labstack/echo/v4"
+)
+
+func Test_Allow_rejects_over_limit(t *testing.T) {
+	c, server := cachetest.NewRedis(t)
+	defer server.Close()
+
+	for _, algorithm := range []Algorithm{FixedWindow, SlidingWindow, TokenBucket} {
+		l, err := New(c, &Option{Algorithm: algorithm, Limit: 3, Window: time.Minute})
+		if err != nil {
+			t.Fatalf("should not error %s", err)
+		}
+
+		key := "user:" + string('a'+rune(algorithm))
+
+		for n := int64(0); n < 3; n++ {
+			result, err := l.Allow(key)
+			if err != nil {
+				t.Fatalf("should not error %s", err)
+			}
+
+			if !result.Allowed || result.Remaining != 2-n {
+				t.Errorf("algorithm %d should allow request %d, got %+v", algorithm, n, result)
+			}
+		}
+
+		result, err := l.Allow(key)
+		if err != nil {
+			t.Fatalf("should not error %s", err)
+		}
+
+		if result.Allowed || result.RetryAfter <= 0 || result.RetryAfter > time.Minute {
+			t.Errorf("algorithm %d should reject request over the limit, got %+v", algorithm, result)
+		}
+
+		if result, _ := l.AllowN(key+":n", 4); result.Allowed || result.RetryAfter != -1 {
+			t.Errorf("algorithm %d should never allow more than the limit, got %+v", algorithm, result)
+		}
+	}
+}
+
+func Test_FixedWindow_resets_after_window(t *testing.T) {
+	c, server := cachetest.NewRedis(t)
+	defer server.Close()
+
+	l, _ := New(c, &Option{Limit: 1, Window: time.Second})
+
+	if result, _ := l.Allow("user"); !result.Allowed {
+		t.Fatalf("should allow first request")
+	}
+
+	if result, _ := l.Allow("user"); result.Allowed {
+		t.Fatalf("should reject second request")
+	}
+
+	server.FastForward(time.Second)
+
+	if result, _ := l.Allow("user"); !result.Allowed {
+		t.Errorf("should allow request in the next window")
+	}
+}
+
+func Test_Middleware_responds_too_many_request(t *testing.T) {
+	c, server := cachetest.NewRedis(t)
+	defer server.Close()
+
+	l, _ := New(c, &Option{Limit: 1, Window: time.Minute})
+
+	e := echo.New()
+	e.Use(Middleware(l, ByMandatory("storeId")))
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	request := func(store string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("storeId", store)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := request("TIKETCOM"); w.Body.String() != "ok" {
+		t.Fatalf("should pass first request, got %s", w.Body.String())
+	}
+
+	w := request("TIKETCOM")
+
+	response := shared_dto.BaseResponseDto{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if response.Code != tiketerror.TOO_MANY_REQUEST || w.Header().Get("Retry-After") == "" {
+		t.Errorf("should respond too many request, got %s", w.Body.String())
+	}
+
+	if w := request("OTHER"); w.Body.String() != "ok" {
+		t.Errorf("should limit stores separately, got %s", w.Body.String())
+	}
+}
+
+func Test_RealIP_reads_forwarding_headers_of_trusted_proxies_only(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+
+	request := func(remote string, header, value string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remote
+		if header != "" {
+			r.Header.Set(header, value)
+		}
+		return r
+	}
+
+	cases := []struct {
+		request *http.Request
+		trusted []*net.IPNet
+		ip      string
+	}{
+		{request("203.0.113.7:1234", "X-Forwarded-For", "198.51.100.1"), nil, "203.0.113.7"},
+		{request("203.0.113.7:1234", "X-Forwarded-For", "198.51.100.1"), []*net.IPNet{proxies}, "203.0.113.7"},
+		{request("10.0.0.2:1234", "X-Forwarded-For", "198.51.100.1, 203.0.113.7, 10.0.0.3"), []*net.IPNet{proxies}, "203.0.113.7"},
+		{request("10.0.0.2:1234", "X-Forwarded-For", "10.0.0.4, 10.0.0.3"), []*net.IPNet{proxies}, "10.0.0.4"},
+		{request("10.0.0.2:1234", "X-Real-IP", "203.0.113.7"), []*net.IPNet{proxies}, "203.0.113.7"},
+		{request("10.0.0.2:1234", "", ""), []*net.IPNet{proxies}, "10.0.0.2"},
+	}
+
+	for n, c := range cases {
+		if ip := RealIP(c.request, c.trusted...); ip != c.ip {
+			t.Errorf("case %d: unexpected ip %s, should be %s", n, ip, c.ip)
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package redis_universal
 
 import (
-	"encoding"
 	"fmt"
 	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/codec"
 	"github.com/go-redis/redis"
 	"github.com/pkg/errors"
+	"log"
+	"sync"
 	"time"
 )
 
@@ -22,10 +24,16 @@ type (
 		ReadTimeout  time.Duration
 		WriteTimeout time.Duration
 		MaxConnAge   time.Duration
+		// Codec encodes the values of Set, HSet, HMSet and the Pipe, defaults to codec.Binary.
+		Codec codec.Codec
 	}
 
 	redisUniversalClient struct {
-		r redis.UniversalClient
+		r             redis.UniversalClient
+		codec         codec.Codec
+		mu            sync.Mutex
+		subscriptions map[cache.PubSub]struct{}
+		scripts       cache.Scripts
 	}
 )
 
@@ -50,7 +58,7 @@ func New(option *Option) (cache.Cache, error) {
 		return nil, errors.Wrap(err, "Failed to connect to redis!")
 	}
 
-	return &redisUniversalClient{r: client}, nil
+	return &redisUniversalClient{r: client, codec: codec.Default(option.Codec), subscriptions: make(map[cache.PubSub]struct{})}, nil
 }
 
 func (c *redisUniversalClient) Ping() error {
@@ -65,7 +73,12 @@ func (c *redisUniversalClient) SetWithExpiration(key string, value interface{},
 		return err
 	}
 
-	if _, err := c.r.Set(key, value, duration).Result(); err != nil {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal cache with key %s!", key)
+	}
+
+	if _, err := c.r.Set(key, data, duration).Result(); err != nil {
 		return errors.Wrapf(err, "failed to set cache with key %s!", key)
 	}
 	return nil
@@ -80,37 +93,511 @@ func (c *redisUniversalClient) Set(key string, value interface{}) error {
 }
 
 func (c *redisUniversalClient) Get(key string, data interface{}) error {
-	if _, ok := data.(encoding.BinaryUnmarshaler); !ok {
-		return errors.New(fmt.Sprintf("failed to get cache with key %s!: redis: can't unmarshal (implement encoding.BinaryUnmarshaler)", key))
+	if err := check(c); err != nil {
+		return err
+	}
+
+	val, err := c.r.Get(key).Bytes()
+
+	if err == redis.Nil {
+		return errors.Wrapf(err, "key %s does not exits", key)
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to get key %s!", key)
 	}
 
+	if err := c.codec.Unmarshal(val, data); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+func (c *redisUniversalClient) Incr(key string) (int64, error) {
+	return c.IncrBy(key, 1)
+}
+
+func (c *redisUniversalClient) Decr(key string) (int64, error) {
+	return c.IncrBy(key, -1)
+}
+
+func (c *redisUniversalClient) IncrBy(key string, value int64) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.IncrBy(key, value).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to increment key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) SetNX(key string, value interface{}, ttl time.Duration) (bool, error) {
+	if err := check(c); err != nil {
+		return false, err
+	}
+
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to marshal cache with key %s!", key)
+	}
+
+	ok, err := c.r.SetNX(key, data, ttl).Result()
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to setnx cache with key %s!", key)
+	}
+
+	return ok, nil
+}
+
+func (c *redisUniversalClient) Expire(key string, ttl time.Duration) (bool, error) {
+	if err := check(c); err != nil {
+		return false, err
+	}
+
+	ok, err := c.r.PExpire(key, ttl).Result()
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to expire key %s!", key)
+	}
+
+	return ok, nil
+}
+
+func (c *redisUniversalClient) TTL(key string) (time.Duration, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.PTTL(key).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get ttl of key %s!", key)
+	}
+
+	return cache.RedisTTL(val), nil
+}
+
+func (c *redisUniversalClient) Exists(keys ...string) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.Exists(keys...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to check keys %s!", keys)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) GetSet(key string, value interface{}, object interface{}) error {
 	if err := check(c); err != nil {
 		return err
 	}
 
-	val, err := c.r.Get(key).Result()
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal cache with key %s!", key)
+	}
 
+	val, err := c.r.GetSet(key, data).Bytes()
 	if err == redis.Nil {
 		return errors.Wrapf(err, "key %s does not exits", key)
 	}
 
 	if err != nil {
-		return errors.Wrapf(err, "failed to get key %s!", key)
+		return errors.Wrapf(err, "failed to getset key %s!", key)
+	}
+
+	if err := c.codec.Unmarshal(val, object); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+func (c *redisUniversalClient) LPush(key string, values ...interface{}) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	data, err := c.marshalValues(values)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to lpush key %s!", key)
+	}
+
+	val, err := c.r.LPush(key, data...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to lpush key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) RPush(key string, values ...interface{}) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	data, err := c.marshalValues(values)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to rpush key %s!", key)
+	}
+
+	val, err := c.r.RPush(key, data...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to rpush key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) LPop(key string, object interface{}) error {
+	if err := check(c); err != nil {
+		return err
+	}
+
+	return c.pop(key, c.r.LPop(key), object)
+}
+
+func (c *redisUniversalClient) RPop(key string, object interface{}) error {
+	if err := check(c); err != nil {
+		return err
+	}
+
+	return c.pop(key, c.r.RPop(key), object)
+}
+
+func (c *redisUniversalClient) pop(key string, cmd *redis.StringCmd, object interface{}) error {
+	val, err := cmd.Bytes()
+	if err == redis.Nil {
+		return errors.Wrapf(err, "key %s does not exits", key)
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to pop key %s!", key)
+	}
+
+	if err := c.codec.Unmarshal(val, object); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+func (c *redisUniversalClient) BLPop(timeout time.Duration, object interface{}, keys ...string) (string, error) {
+	if err := check(c); err != nil {
+		return "", err
+	}
+
+	val, err := c.r.BLPop(timeout, keys...).Result()
+	if err == redis.Nil {
+		return "", errors.Wrapf(err, "keys %s are empty", keys)
+	}
+
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to blpop keys %s!", keys)
+	}
+
+	if err := c.codec.Unmarshal([]byte(val[1]), object); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal cache with key %s!", val[0])
+	}
+
+	return val[0], nil
+}
+
+func (c *redisUniversalClient) LRange(key string, start, stop int64, results interface{}) error {
+	if err := check(c); err != nil {
+		return err
+	}
+
+	val, err := c.r.LRange(key, start, stop).Result()
+	if err != nil {
+		return errors.Wrapf(err, "failed to lrange key %s!", key)
+	}
+
+	if err := codec.UnmarshalAll(c.codec, replies(val), results); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+func (c *redisUniversalClient) SAdd(key string, members ...interface{}) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	data, err := c.marshalValues(members)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to sadd key %s!", key)
+	}
+
+	val, err := c.r.SAdd(key, data...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to sadd key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) SRem(key string, members ...interface{}) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	data, err := c.marshalValues(members)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to srem key %s!", key)
+	}
+
+	val, err := c.r.SRem(key, data...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to srem key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) SMembers(key string, results interface{}) error {
+	if err := check(c); err != nil {
+		return err
+	}
+
+	val, err := c.r.SMembers(key).Result()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get members of key %s!", key)
+	}
+
+	if err := codec.UnmarshalAll(c.codec, replies(val), results); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+func (c *redisUniversalClient) SIsMember(key string, member interface{}) (bool, error) {
+	if err := check(c); err != nil {
+		return false, err
+	}
+
+	data, err := c.codec.Marshal(member)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to marshal member of key %s!", key)
+	}
+
+	val, err := c.r.SIsMember(key, data).Result()
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to check member of key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) ZAdd(key string, members ...redis.Z) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.ZAdd(key, members...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to zadd cache with key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) ZRem(key string, members ...interface{}) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.ZRem(key, members...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to zrem cache with key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) ZRangeByScore(key string, by redis.ZRangeBy) ([]redis.Z, error) {
+	if err := check(c); err != nil {
+		return nil, err
 	}
 
-	if err := data.(encoding.BinaryUnmarshaler).UnmarshalBinary([]byte(val)); err != nil {
+	val, err := c.r.ZRangeByScoreWithScores(key, by).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run zrangebyscore command")
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) ZRevRange(key string, start, stop int64) ([]redis.Z, error) {
+	if err := check(c); err != nil {
+		return nil, err
+	}
+
+	val, err := c.r.ZRevRangeWithScores(key, start, stop).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run zrevrange command")
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) ZIncrBy(key string, increment float64, member string) (float64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.ZIncrBy(key, increment, member).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to zincrby cache with key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) GeoAdd(key string, locations ...*redis.GeoLocation) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.GeoAdd(key, locations...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to geoadd key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) GeoRadius(key string, longitude, latitude float64, query redis.GeoRadiusQuery) ([]redis.GeoLocation, error) {
+	if err := check(c); err != nil {
+		return nil, err
+	}
+
+	val, err := c.r.GeoRadius(key, longitude, latitude, &query).Result()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to georadius key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) GeoSearch(key string, query cache.GeoSearchQuery) ([]redis.GeoLocation, error) {
+	if err := check(c); err != nil {
+		return nil, err
+	}
+
+	cmd, err := cache.NewGeoSearchCmd(key, query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to geosearch key %s!", key)
+	}
+
+	if err := c.r.Process(cmd); err != nil {
+		return nil, errors.Wrapf(err, "failed to geosearch key %s!", key)
+	}
+
+	return cmd.Val(), nil
+}
+
+func (c *redisUniversalClient) GeoDist(key, member1, member2, unit string) (float64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.GeoDist(key, member1, member2, unit).Result()
+	if err == redis.Nil {
+		return 0, errors.Wrapf(err, "member %s or %s of key %s does not exits", member1, member2, key)
+	}
+
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to geodist key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) PFAdd(key string, elements ...interface{}) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	data, err := c.marshalValues(elements)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to pfadd key %s!", key)
+	}
+
+	val, err := c.r.PFAdd(key, data...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to pfadd key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) PFCount(keys ...string) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.PFCount(keys...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to pfcount keys %s!", keys)
+	}
+
+	return val, nil
+}
+
+func (c *redisUniversalClient) PFMerge(dest string, keys ...string) error {
+	if err := check(c); err != nil {
 		return err
 	}
 
+	if err := c.r.PFMerge(dest, keys...).Err(); err != nil {
+		return errors.Wrapf(err, "failed to pfmerge keys %s into key %s!", keys, dest)
+	}
+
 	return nil
 }
 
+func (c *redisUniversalClient) marshalValues(values []interface{}) ([]interface{}, error) {
+	data := make([]interface{}, 0, len(values))
+	for n, value := range values {
+		d, err := c.codec.Marshal(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal value %d", n)
+		}
+		data = append(data, d)
+	}
+
+	return data, nil
+}
+
+// replies converts string replies to the values codec.UnmarshalAll decodes.
+func replies(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for n, value := range values {
+		result[n] = value
+	}
+
+	return result
+}
+
 func (c *redisUniversalClient) Keys(pattern string) ([]string, error) {
 	if err := check(c); err != nil {
 		return []string{}, err
 	}
 
-	return c.r.Keys(pattern).Result()
+	return cache.ScanKeys(c.Scan(pattern, cache.DefaultScanCount))
+}
+
+// Scan iterates the keys matching pattern on every master node.
+func (c *redisUniversalClient) Scan(pattern string, count int64) cache.Iterator {
+	return cache.ScanNodes(cache.ScanMasters(c.r), pattern, count)
 }
 
 func (c *redisUniversalClient) Remove(key string) error {
@@ -130,24 +617,53 @@ func (c *redisUniversalClient) RemoveByPattern(pattern string, countPerLoop int6
 		return err
 	}
 
+	if countPerLoop <= 0 {
+		countPerLoop = cache.DefaultScanCount
+	}
+
+	iter := c.Scan(pattern, countPerLoop)
+	keys := make([]string, 0, countPerLoop)
 	iteration := 1
-	for {
-		keys, _, err := c.r.Scan(0, pattern, countPerLoop).Result()
-		if err != nil {
-			return errors.Wrapf(err, "failed to scan redis pattern %s!", pattern)
-		}
 
-		if len(keys) == 0 {
-			break
+	for iter.Next() {
+		keys = append(keys, iter.Val())
+		if int64(len(keys)) < countPerLoop {
+			continue
 		}
 
-		if _, err := c.r.Del(keys...).Result(); err != nil {
+		if err := c.unlink(keys); err != nil {
 			return errors.Wrapf(err, "failed iteration-%d to remove key with pattern %s", iteration, pattern)
 		}
 
+		keys = keys[:0]
 		iteration++
 	}
 
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	if len(keys) > 0 {
+		if err := c.unlink(keys); err != nil {
+			return errors.Wrapf(err, "failed iteration-%d to remove key with pattern %s", iteration, pattern)
+		}
+	}
+
+	return nil
+}
+
+// unlink removes keys in the background of redis.
+func (c *redisUniversalClient) unlink(keys []string) error {
+	// - one command per key, keys of a batch can live in different slots
+	pipe := c.r.Pipeline()
+	for _, key := range keys {
+		pipe.Unlink(key)
+	}
+
+	if _, err := pipe.Exec(); err != nil {
+		return errors.Wrapf(err, "failed to unlink keys %s!", keys)
+	}
+
 	return nil
 }
 
@@ -176,6 +692,19 @@ func (c *redisUniversalClient) FlushAll() error {
 }
 
 func (c *redisUniversalClient) Close() error {
+	c.mu.Lock()
+	subscriptions := make([]cache.PubSub, 0, len(c.subscriptions))
+	for p := range c.subscriptions {
+		subscriptions = append(subscriptions, p)
+	}
+	c.mu.Unlock()
+
+	for _, p := range subscriptions {
+		if err := p.Close(); err != nil {
+			log.Printf("failed to close pubsub: %s", err)
+		}
+	}
+
 	if err := c.r.Close(); err != nil {
 		return errors.Wrap(err, "failed to close redis client")
 	}
@@ -237,7 +766,12 @@ func (c *redisUniversalClient) HMSetWithExpiration(key string, value map[string]
 		return err
 	}
 
-	if _, err := c.r.HMSet(key, value).Result(); err != nil {
+	fields, err := c.marshalFields(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to HMSet cache with key %s!", key)
+	}
+
+	if _, err := c.r.HMSet(key, fields).Result(); err != nil {
 		return errors.Wrapf(err, "failed to HMSet cache with key %s!", key)
 	}
 
@@ -253,7 +787,12 @@ func (c *redisUniversalClient) HMSet(key string, value map[string]interface{}) e
 		return err
 	}
 
-	if _, err := c.r.HMSet(key, value).Result(); err != nil {
+	fields, err := c.marshalFields(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to HMSet cache with key %s!", key)
+	}
+
+	if _, err := c.r.HMSet(key, fields).Result(); err != nil {
 		return errors.Wrapf(err, "failed to HMSet cache with key %s!", key)
 	}
 	return nil
@@ -264,7 +803,12 @@ func (c *redisUniversalClient) HSetWithExpiration(key, field string, value inter
 		return err
 	}
 
-	if _, err := c.r.HSet(key, field, value).Result(); err != nil {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal field %s of key %s!", field, key)
+	}
+
+	if _, err := c.r.HSet(key, field, data).Result(); err != nil {
 		return errors.Wrapf(err, "failed to HSet cache with key %s!", key)
 	}
 	if _, err := c.r.Expire(key, ttl).Result(); err != nil {
@@ -279,7 +823,12 @@ func (c *redisUniversalClient) HSet(key, field string, value interface{}) error
 		return err
 	}
 
-	if _, err := c.r.HSet(key, field, value).Result(); err != nil {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal field %s of key %s!", field, key)
+	}
+
+	if _, err := c.r.HSet(key, field, data).Result(); err != nil {
 		return errors.Wrapf(err, "failed to HSet cache with key %s!", key)
 	}
 	return nil
@@ -320,15 +869,11 @@ func (c *redisUniversalClient) HGetAll(key string) (map[string]string, error) {
 }
 
 func (c *redisUniversalClient) HGet(key, field string, response interface{}) error {
-	if _, ok := response.(encoding.BinaryUnmarshaler); !ok {
-		return errors.New(fmt.Sprintf("failed to get cache with key %s!: redis: can't unmarshal (implement encoding.BinaryUnmarshaler)", key))
-	}
-
 	if err := check(c); err != nil {
 		return err
 	}
 
-	val, err := c.r.HGet(key, field).Result()
+	val, err := c.r.HGet(key, field).Bytes()
 	if err == redis.Nil {
 		return errors.Wrapf(err, "key %s does not exits", key)
 	}
@@ -337,8 +882,8 @@ func (c *redisUniversalClient) HGet(key, field string, response interface{}) err
 		return errors.Wrapf(err, "failed to get key %s!", key)
 	}
 
-	if err := response.(encoding.BinaryUnmarshaler).UnmarshalBinary([]byte(val)); err != nil {
-		return err
+	if err := c.codec.Unmarshal(val, response); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal field %s of key %s!", field, key)
 	}
 
 	return nil
@@ -361,14 +906,114 @@ func (c *redisUniversalClient) MGet(key []string) ([]interface{}, error) {
 	return val, nil
 }
 
+func (c *redisUniversalClient) MGetInto(keys []string, results interface{}) error {
+	values, err := c.MGet(keys)
+	if err != nil {
+		return err
+	}
+
+	if err := codec.UnmarshalAll(c.codec, values, results); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal keys %s!", keys)
+	}
+
+	return nil
+}
+
+func (c *redisUniversalClient) marshalFields(value map[string]interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{}, len(value))
+	for field, v := range value {
+		data, err := c.codec.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal field %s", field)
+		}
+		fields[field] = data
+	}
+
+	return fields, nil
+}
+
+// Cmdable exposes the go-redis client to packages built on raw commands, see cache.Cmdable.
+func (c *redisUniversalClient) Cmdable() redis.Cmdable {
+	return c.r
+}
+
 func (c *redisUniversalClient) Client() cache.Cache {
 	return c
 }
 
 func (c *redisUniversalClient) Pipeline() cache.Pipe {
-	return &pipe{instance: c.r.Pipeline()}
+	return cache.NewRedisPipe(c.r.Pipeline(), c.codec)
+}
+
+func (c *redisUniversalClient) TxPipeline() cache.Pipe {
+	return cache.NewRedisPipe(c.r.TxPipeline(), c.codec)
+}
+
+func (c *redisUniversalClient) Watch(fn func(tx cache.Tx) error, keys ...string) error {
+	if err := check(c); err != nil {
+		return err
+	}
+
+	return c.r.Watch(func(tx *redis.Tx) error {
+		return fn(cache.NewRedisTx(tx, c.codec))
+	}, keys...)
+}
+
+func (c *redisUniversalClient) Subscribe(channels ...string) (cache.PubSub, error) {
+	if err := check(c); err != nil {
+		return nil, err
+	}
+
+	return c.subscribe(c.r.Subscribe(channels...), channels), nil
 }
 
-func (c *redisUniversalClient) Subscribe(channel string) (cache.PubSub, error) {
-	return nil, errors.New("pubsub not implemented in universal client")
+func (c *redisUniversalClient) PSubscribe(patterns ...string) (cache.PubSub, error) {
+	if err := check(c); err != nil {
+		return nil, err
+	}
+
+	return c.subscribe(c.r.PSubscribe(patterns...), nil), nil
+}
+
+// subscribe keeps p until it is closed, Close closes the subscriptions left.
+func (c *redisUniversalClient) subscribe(p *redis.PubSub, channels []string) cache.PubSub {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var subscription cache.PubSub
+	subscription = cache.NewRedisPubSub(c.r, p, channels, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		delete(c.subscriptions, subscription)
+	})
+
+	c.subscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+func (c *redisUniversalClient) Publish(channel, message string) error {
+	if err := check(c); err != nil {
+		return err
+	}
+
+	if err := c.r.Publish(channel, message).Err(); err != nil {
+		return errors.Wrapf(err, "failed to publish message to cn %s", channel)
+	}
+
+	return nil
+}
+
+func (c *redisUniversalClient) RegisterScript(name, src string) {
+	c.scripts.Register(name, src)
+}
+
+func (c *redisUniversalClient) RunScript(name string, keys []string, args ...interface{}) *cache.ScriptResult {
+	if err := check(c); err != nil {
+		r := cache.NewScriptResult(c.codec)
+		r.Resolve(nil, err)
+		return r
+	}
+
+	return c.scripts.Run(c.r, c.codec, name, keys, args...)
 }
@@ -1,9 +1,9 @@
 package redis
 
 import (
-	"encoding"
 	"fmt"
 	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/codec"
 	"github.com/go-redis/redis"
 	"github.com/pkg/errors"
 	"log"
@@ -23,12 +23,16 @@ type (
 		ReadTimeout  time.Duration
 		WriteTimeout time.Duration
 		MaxConnAge   time.Duration
+		// Codec encodes the values of Set, HSet, HMSet and the Pipe, defaults to codec.Binary.
+		Codec codec.Codec
 	}
 
 	redisClient struct {
-		r        *redis.Client
-		mu       sync.Mutex
-		channels map[string]cache.PubSub
+		r             *redis.Client
+		codec         codec.Codec
+		mu            sync.Mutex
+		subscriptions map[cache.PubSub]struct{}
+		scripts       cache.Scripts
 	}
 )
 
@@ -52,7 +56,7 @@ func New(option *Option) (cache.Cache, error) {
 		return nil, errors.Wrap(err, "Failed to connect to redis!")
 	}
 
-	return &redisClient{r: client, channels: make(map[string]cache.PubSub)}, nil
+	return &redisClient{r: client, codec: codec.Default(option.Codec), subscriptions: make(map[cache.PubSub]struct{})}, nil
 }
 
 func (c *redisClient) Ping() error {
@@ -67,7 +71,12 @@ func (c *redisClient) SetWithExpiration(key string, value interface{}, duration
 		return err
 	}
 
-	if _, err := c.r.Set(key, value, duration).Result(); err != nil {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal cache with key %s!", key)
+	}
+
+	if _, err := c.r.Set(key, data, duration).Result(); err != nil {
 		return errors.Wrapf(err, "failed to set cache with key %s!", key)
 	}
 
@@ -83,37 +92,511 @@ func (c *redisClient) Set(key string, value interface{}) error {
 }
 
 func (c *redisClient) Get(key string, data interface{}) error {
-	if _, ok := data.(encoding.BinaryUnmarshaler); !ok {
-		return errors.New(fmt.Sprintf("failed to get cache with key %s!: redis: can't unmarshal (implement encoding.BinaryUnmarshaler)", key))
+	if err := check(c); err != nil {
+		return err
+	}
+
+	val, err := c.r.Get(key).Bytes()
+
+	if err == redis.Nil {
+		return errors.Wrapf(err, "key %s does not exits", key)
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to get key %s!", key)
+	}
+
+	if err := c.codec.Unmarshal(val, data); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+func (c *redisClient) Incr(key string) (int64, error) {
+	return c.IncrBy(key, 1)
+}
+
+func (c *redisClient) Decr(key string) (int64, error) {
+	return c.IncrBy(key, -1)
+}
+
+func (c *redisClient) IncrBy(key string, value int64) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.IncrBy(key, value).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to increment key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) SetNX(key string, value interface{}, ttl time.Duration) (bool, error) {
+	if err := check(c); err != nil {
+		return false, err
+	}
+
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to marshal cache with key %s!", key)
+	}
+
+	ok, err := c.r.SetNX(key, data, ttl).Result()
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to setnx cache with key %s!", key)
+	}
+
+	return ok, nil
+}
+
+func (c *redisClient) Expire(key string, ttl time.Duration) (bool, error) {
+	if err := check(c); err != nil {
+		return false, err
+	}
+
+	ok, err := c.r.PExpire(key, ttl).Result()
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to expire key %s!", key)
+	}
+
+	return ok, nil
+}
+
+func (c *redisClient) TTL(key string) (time.Duration, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.PTTL(key).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get ttl of key %s!", key)
+	}
+
+	return cache.RedisTTL(val), nil
+}
+
+func (c *redisClient) Exists(keys ...string) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.Exists(keys...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to check keys %s!", keys)
 	}
 
+	return val, nil
+}
+
+func (c *redisClient) GetSet(key string, value interface{}, object interface{}) error {
 	if err := check(c); err != nil {
 		return err
 	}
 
-	val, err := c.r.Get(key).Result()
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal cache with key %s!", key)
+	}
 
+	val, err := c.r.GetSet(key, data).Bytes()
 	if err == redis.Nil {
 		return errors.Wrapf(err, "key %s does not exits", key)
 	}
 
 	if err != nil {
-		return errors.Wrapf(err, "failed to get key %s!", key)
+		return errors.Wrapf(err, "failed to getset key %s!", key)
+	}
+
+	if err := c.codec.Unmarshal(val, object); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+func (c *redisClient) LPush(key string, values ...interface{}) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	data, err := c.marshalValues(values)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to lpush key %s!", key)
+	}
+
+	val, err := c.r.LPush(key, data...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to lpush key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) RPush(key string, values ...interface{}) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	data, err := c.marshalValues(values)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to rpush key %s!", key)
+	}
+
+	val, err := c.r.RPush(key, data...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to rpush key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) LPop(key string, object interface{}) error {
+	if err := check(c); err != nil {
+		return err
+	}
+
+	return c.pop(key, c.r.LPop(key), object)
+}
+
+func (c *redisClient) RPop(key string, object interface{}) error {
+	if err := check(c); err != nil {
+		return err
+	}
+
+	return c.pop(key, c.r.RPop(key), object)
+}
+
+func (c *redisClient) pop(key string, cmd *redis.StringCmd, object interface{}) error {
+	val, err := cmd.Bytes()
+	if err == redis.Nil {
+		return errors.Wrapf(err, "key %s does not exits", key)
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to pop key %s!", key)
+	}
+
+	if err := c.codec.Unmarshal(val, object); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+func (c *redisClient) BLPop(timeout time.Duration, object interface{}, keys ...string) (string, error) {
+	if err := check(c); err != nil {
+		return "", err
+	}
+
+	val, err := c.r.BLPop(timeout, keys...).Result()
+	if err == redis.Nil {
+		return "", errors.Wrapf(err, "keys %s are empty", keys)
+	}
+
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to blpop keys %s!", keys)
+	}
+
+	if err := c.codec.Unmarshal([]byte(val[1]), object); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal cache with key %s!", val[0])
+	}
+
+	return val[0], nil
+}
+
+func (c *redisClient) LRange(key string, start, stop int64, results interface{}) error {
+	if err := check(c); err != nil {
+		return err
+	}
+
+	val, err := c.r.LRange(key, start, stop).Result()
+	if err != nil {
+		return errors.Wrapf(err, "failed to lrange key %s!", key)
+	}
+
+	if err := codec.UnmarshalAll(c.codec, replies(val), results); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+func (c *redisClient) SAdd(key string, members ...interface{}) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	data, err := c.marshalValues(members)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to sadd key %s!", key)
+	}
+
+	val, err := c.r.SAdd(key, data...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to sadd key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) SRem(key string, members ...interface{}) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	data, err := c.marshalValues(members)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to srem key %s!", key)
+	}
+
+	val, err := c.r.SRem(key, data...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to srem key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) SMembers(key string, results interface{}) error {
+	if err := check(c); err != nil {
+		return err
+	}
+
+	val, err := c.r.SMembers(key).Result()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get members of key %s!", key)
+	}
+
+	if err := codec.UnmarshalAll(c.codec, replies(val), results); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+func (c *redisClient) SIsMember(key string, member interface{}) (bool, error) {
+	if err := check(c); err != nil {
+		return false, err
+	}
+
+	data, err := c.codec.Marshal(member)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to marshal member of key %s!", key)
+	}
+
+	val, err := c.r.SIsMember(key, data).Result()
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to check member of key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) ZAdd(key string, members ...redis.Z) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.ZAdd(key, members...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to zadd cache with key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) ZRem(key string, members ...interface{}) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.ZRem(key, members...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to zrem cache with key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) ZRangeByScore(key string, by redis.ZRangeBy) ([]redis.Z, error) {
+	if err := check(c); err != nil {
+		return nil, err
+	}
+
+	val, err := c.r.ZRangeByScoreWithScores(key, by).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run zrangebyscore command")
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) ZRevRange(key string, start, stop int64) ([]redis.Z, error) {
+	if err := check(c); err != nil {
+		return nil, err
+	}
+
+	val, err := c.r.ZRevRangeWithScores(key, start, stop).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run zrevrange command")
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) ZIncrBy(key string, increment float64, member string) (float64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.ZIncrBy(key, increment, member).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to zincrby cache with key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) GeoAdd(key string, locations ...*redis.GeoLocation) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.GeoAdd(key, locations...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to geoadd key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) GeoRadius(key string, longitude, latitude float64, query redis.GeoRadiusQuery) ([]redis.GeoLocation, error) {
+	if err := check(c); err != nil {
+		return nil, err
+	}
+
+	val, err := c.r.GeoRadius(key, longitude, latitude, &query).Result()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to georadius key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) GeoSearch(key string, query cache.GeoSearchQuery) ([]redis.GeoLocation, error) {
+	if err := check(c); err != nil {
+		return nil, err
+	}
+
+	cmd, err := cache.NewGeoSearchCmd(key, query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to geosearch key %s!", key)
+	}
+
+	if err := c.r.Process(cmd); err != nil {
+		return nil, errors.Wrapf(err, "failed to geosearch key %s!", key)
+	}
+
+	return cmd.Val(), nil
+}
+
+func (c *redisClient) GeoDist(key, member1, member2, unit string) (float64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	val, err := c.r.GeoDist(key, member1, member2, unit).Result()
+	if err == redis.Nil {
+		return 0, errors.Wrapf(err, "member %s or %s of key %s does not exits", member1, member2, key)
+	}
+
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to geodist key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) PFAdd(key string, elements ...interface{}) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
+	}
+
+	data, err := c.marshalValues(elements)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to pfadd key %s!", key)
+	}
+
+	val, err := c.r.PFAdd(key, data...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to pfadd key %s!", key)
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) PFCount(keys ...string) (int64, error) {
+	if err := check(c); err != nil {
+		return 0, err
 	}
 
-	if err := data.(encoding.BinaryUnmarshaler).UnmarshalBinary([]byte(val)); err != nil {
+	val, err := c.r.PFCount(keys...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to pfcount keys %s!", keys)
+	}
+
+	return val, nil
+}
+
+func (c *redisClient) PFMerge(dest string, keys ...string) error {
+	if err := check(c); err != nil {
 		return err
 	}
 
+	if err := c.r.PFMerge(dest, keys...).Err(); err != nil {
+		return errors.Wrapf(err, "failed to pfmerge keys %s into key %s!", keys, dest)
+	}
+
 	return nil
 }
 
+func (c *redisClient) marshalValues(values []interface{}) ([]interface{}, error) {
+	data := make([]interface{}, 0, len(values))
+	for n, value := range values {
+		d, err := c.codec.Marshal(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal value %d", n)
+		}
+		data = append(data, d)
+	}
+
+	return data, nil
+}
+
+// replies converts string replies to the values codec.UnmarshalAll decodes.
+func replies(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for n, value := range values {
+		result[n] = value
+	}
+
+	return result
+}
+
 func (c *redisClient) Keys(pattern string) ([]string, error) {
 	if err := check(c); err != nil {
 		return []string{}, err
 	}
 
-	return c.r.Keys(pattern).Result()
+	return cache.ScanKeys(c.Scan(pattern, cache.DefaultScanCount))
+}
+
+// Scan iterates the keys matching pattern.
+func (c *redisClient) Scan(pattern string, count int64) cache.Iterator {
+	return cache.ScanNodes(cache.ScanMasters(c.r), pattern, count)
 }
 
 func (c *redisClient) Remove(key string) error {
@@ -133,24 +616,47 @@ func (c *redisClient) RemoveByPattern(pattern string, countPerLoop int64) error
 		return err
 	}
 
+	if countPerLoop <= 0 {
+		countPerLoop = cache.DefaultScanCount
+	}
+
+	iter := c.Scan(pattern, countPerLoop)
+	keys := make([]string, 0, countPerLoop)
 	iteration := 1
-	for {
-		keys, _, err := c.r.Scan(0, pattern, countPerLoop).Result()
-		if err != nil {
-			return errors.Wrapf(err, "failed to scan redis pattern %s!", pattern)
-		}
 
-		if len(keys) == 0 {
-			break
+	for iter.Next() {
+		keys = append(keys, iter.Val())
+		if int64(len(keys)) < countPerLoop {
+			continue
 		}
 
-		if _, err := c.r.Del(keys...).Result(); err != nil {
+		if err := c.unlink(keys); err != nil {
 			return errors.Wrapf(err, "failed iteration-%d to remove key with pattern %s", iteration, pattern)
 		}
 
+		keys = keys[:0]
 		iteration++
 	}
 
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	if len(keys) > 0 {
+		if err := c.unlink(keys); err != nil {
+			return errors.Wrapf(err, "failed iteration-%d to remove key with pattern %s", iteration, pattern)
+		}
+	}
+
+	return nil
+}
+
+// unlink removes keys in the background of redis.
+func (c *redisClient) unlink(keys []string) error {
+	if _, err := c.r.Unlink(keys...).Result(); err != nil {
+		return errors.Wrapf(err, "failed to unlink keys %s!", keys)
+	}
+
 	return nil
 }
 
@@ -179,9 +685,16 @@ func (c *redisClient) FlushAll() error {
 }
 
 func (c *redisClient) Close() error {
-	for channel, c := range c.channels {
-		if err := c.Close(); err != nil {
-			log.Printf("failed to close pubsub cn %s", channel)
+	c.mu.Lock()
+	subscriptions := make([]cache.PubSub, 0, len(c.subscriptions))
+	for p := range c.subscriptions {
+		subscriptions = append(subscriptions, p)
+	}
+	c.mu.Unlock()
+
+	for _, p := range subscriptions {
+		if err := p.Close(); err != nil {
+			log.Printf("failed to close pubsub: %s", err)
 		}
 	}
 
@@ -246,7 +759,12 @@ func (c *redisClient) HMSetWithExpiration(key string, value map[string]interface
 		return err
 	}
 
-	if _, err := c.r.HMSet(key, value).Result(); err != nil {
+	fields, err := c.marshalFields(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to HMSet cache with key %s!", key)
+	}
+
+	if _, err := c.r.HMSet(key, fields).Result(); err != nil {
 		return errors.Wrapf(err, "failed to HMSet cache with key %s!", key)
 	}
 
@@ -262,7 +780,12 @@ func (c *redisClient) HMSet(key string, value map[string]interface{}) error {
 		return err
 	}
 
-	if _, err := c.r.HMSet(key, value).Result(); err != nil {
+	fields, err := c.marshalFields(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to HMSet cache with key %s!", key)
+	}
+
+	if _, err := c.r.HMSet(key, fields).Result(); err != nil {
 		return errors.Wrapf(err, "failed to HMSet cache with key %s!", key)
 	}
 	return nil
@@ -273,7 +796,12 @@ func (c *redisClient) HSetWithExpiration(key, field string, value interface{}, t
 		return err
 	}
 
-	if _, err := c.r.HSet(key, field, value).Result(); err != nil {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal field %s of key %s!", field, key)
+	}
+
+	if _, err := c.r.HSet(key, field, data).Result(); err != nil {
 		return errors.Wrapf(err, "failed to HSet cache with key %s!", key)
 	}
 	if _, err := c.r.Expire(key, ttl).Result(); err != nil {
@@ -288,7 +816,12 @@ func (c *redisClient) HSet(key, field string, value interface{}) error {
 		return err
 	}
 
-	if _, err := c.r.HSet(key, field, value).Result(); err != nil {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal field %s of key %s!", field, key)
+	}
+
+	if _, err := c.r.HSet(key, field, data).Result(); err != nil {
 		return errors.Wrapf(err, "failed to HSet cache with key %s!", key)
 	}
 	return nil
@@ -329,15 +862,11 @@ func (c *redisClient) HGetAll(key string) (map[string]string, error) {
 }
 
 func (c *redisClient) HGet(key, field string, response interface{}) error {
-	if _, ok := response.(encoding.BinaryUnmarshaler); !ok {
-		return errors.New(fmt.Sprintf("failed to get cache with key %s!: redis: can't unmarshal (implement encoding.BinaryUnmarshaler)", key))
-	}
-
 	if err := check(c); err != nil {
 		return err
 	}
 
-	val, err := c.r.HGet(key, field).Result()
+	val, err := c.r.HGet(key, field).Bytes()
 	if err == redis.Nil {
 		return errors.Wrapf(err, "key %s does not exits", key)
 	}
@@ -346,8 +875,8 @@ func (c *redisClient) HGet(key, field string, response interface{}) error {
 		return errors.Wrapf(err, "failed to get key %s!", key)
 	}
 
-	if err := response.(encoding.BinaryUnmarshaler).UnmarshalBinary([]byte(val)); err != nil {
-		return err
+	if err := c.codec.Unmarshal(val, response); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal field %s of key %s!", field, key)
 	}
 
 	return nil
@@ -370,25 +899,114 @@ func (c *redisClient) MGet(key []string) ([]interface{}, error) {
 	return val, nil
 }
 
+func (c *redisClient) MGetInto(keys []string, results interface{}) error {
+	values, err := c.MGet(keys)
+	if err != nil {
+		return err
+	}
+
+	if err := codec.UnmarshalAll(c.codec, values, results); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal keys %s!", keys)
+	}
+
+	return nil
+}
+
+func (c *redisClient) marshalFields(value map[string]interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{}, len(value))
+	for field, v := range value {
+		data, err := c.codec.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal field %s", field)
+		}
+		fields[field] = data
+	}
+
+	return fields, nil
+}
+
+// Cmdable exposes the go-redis client to packages built on raw commands, see cache.Cmdable.
+func (c *redisClient) Cmdable() redis.Cmdable {
+	return c.r
+}
+
 func (c *redisClient) Client() cache.Cache {
 	return c
 }
 
 func (c *redisClient) Pipeline() cache.Pipe {
-	return &pipe{instance: c.r.Pipeline()}
+	return cache.NewRedisPipe(c.r.Pipeline(), c.codec)
 }
 
-func (c *redisClient) Subscribe(channel string) (cache.PubSub, error) {
+func (c *redisClient) TxPipeline() cache.Pipe {
+	return cache.NewRedisPipe(c.r.TxPipeline(), c.codec)
+}
+
+func (c *redisClient) Watch(fn func(tx cache.Tx) error, keys ...string) error {
+	if err := check(c); err != nil {
+		return err
+	}
+
+	return c.r.Watch(func(tx *redis.Tx) error {
+		return fn(cache.NewRedisTx(tx, c.codec))
+	}, keys...)
+}
+
+func (c *redisClient) Subscribe(channels ...string) (cache.PubSub, error) {
+	if err := check(c); err != nil {
+		return nil, err
+	}
+
+	return c.subscribe(c.r.Subscribe(channels...), channels), nil
+}
+
+func (c *redisClient) PSubscribe(patterns ...string) (cache.PubSub, error) {
+	if err := check(c); err != nil {
+		return nil, err
+	}
+
+	return c.subscribe(c.r.PSubscribe(patterns...), nil), nil
+}
+
+// subscribe keeps p until it is closed, Close closes the subscriptions left.
+func (c *redisClient) subscribe(p *redis.PubSub, channels []string) cache.PubSub {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	for c, p := range c.channels {
-		if c == channel {
-			return p, nil
-		}
+	var subscription cache.PubSub
+	subscription = cache.NewRedisPubSub(c.r, p, channels, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		delete(c.subscriptions, subscription)
+	})
+
+	c.subscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+func (c *redisClient) Publish(channel, message string) error {
+	if err := check(c); err != nil {
+		return err
+	}
+
+	if err := c.r.Publish(channel, message).Err(); err != nil {
+		return errors.Wrapf(err, "failed to publish message to cn %s", channel)
+	}
+
+	return nil
+}
+
+func (c *redisClient) RegisterScript(name, src string) {
+	c.scripts.Register(name, src)
+}
+
+func (c *redisClient) RunScript(name string, keys []string, args ...interface{}) *cache.ScriptResult {
+	if err := check(c); err != nil {
+		r := cache.NewScriptResult(c.codec)
+		r.Resolve(nil, err)
+		return r
 	}
 
-	p := c.r.Subscribe(channel)
-	c.channels[channel] = &pubsub{r: c.r, p: p, cn: channel}
-	return c.channels[channel], nil
+	return c.scripts.Run(c.r, c.codec, name, keys, args...)
 }
@@ -0,0 +1,305 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/codec"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNotExecuted is the error of a result whose pipeline wasn't executed yet.
+	ErrNotExecuted = errors.New("cache: pipeline not executed")
+	// ErrTxFailed is returned by the Exec of a transaction when a watched key changed.
+	ErrTxFailed = redis.TxFailedErr
+)
+
+// The results of the commands queued on a Pipe, they are set by Exec. Resolve is called by Pipe
+// implementations.
+type (
+	result struct {
+		done bool
+		err  error
+	}
+
+	StatusResult struct {
+		result
+	}
+
+	IntResult struct {
+		result
+		val int64
+	}
+
+	BoolResult struct {
+		result
+		val bool
+	}
+
+	FloatResult struct {
+		result
+		val float64
+	}
+
+	DurationResult struct {
+		result
+		val time.Duration
+	}
+
+	// ValueResult is a value encoded by the codec of the cache, Scan decodes it.
+	ValueResult struct {
+		result
+		codec codec.Codec
+		data  []byte
+	}
+
+	// ValuesResult are values encoded by the codec of the cache, nil for missing ones, Scan decodes them.
+	ValuesResult struct {
+		result
+		codec codec.Codec
+		vals  []interface{}
+	}
+
+	StringMapResult struct {
+		result
+		val map[string]string
+	}
+
+	ZResult struct {
+		result
+		val []redis.Z
+	}
+
+	// ScriptResult is the reply of a Lua script, a redis.Nil error for a nil reply.
+	ScriptResult struct {
+		result
+		codec codec.Codec
+		val   interface{}
+	}
+)
+
+// Err returns the error of the command, ErrNotExecuted before Exec.
+func (r *result) Err() error {
+	if !r.done {
+		return ErrNotExecuted
+	}
+
+	return r.err
+}
+
+func (r *result) resolve(err error) {
+	r.done = true
+	r.err = err
+}
+
+func (r *StatusResult) Resolve(err error) {
+	r.resolve(err)
+}
+
+func (r *IntResult) Resolve(val int64, err error) {
+	r.val = val
+	r.resolve(err)
+}
+
+func (r *IntResult) Val() int64 {
+	return r.val
+}
+
+func (r *IntResult) Result() (int64, error) {
+	return r.val, r.Err()
+}
+
+func (r *BoolResult) Resolve(val bool, err error) {
+	r.val = val
+	r.resolve(err)
+}
+
+func (r *BoolResult) Val() bool {
+	return r.val
+}
+
+func (r *BoolResult) Result() (bool, error) {
+	return r.val, r.Err()
+}
+
+func (r *FloatResult) Resolve(val float64, err error) {
+	r.val = val
+	r.resolve(err)
+}
+
+func (r *FloatResult) Val() float64 {
+	return r.val
+}
+
+func (r *FloatResult) Result() (float64, error) {
+	return r.val, r.Err()
+}
+
+func (r *DurationResult) Resolve(val time.Duration, err error) {
+	r.val = val
+	r.resolve(err)
+}
+
+func (r *DurationResult) Val() time.Duration {
+	return r.val
+}
+
+func (r *DurationResult) Result() (time.Duration, error) {
+	return r.val, r.Err()
+}
+
+func NewValueResult(c codec.Codec) *ValueResult {
+	return &ValueResult{codec: codec.Default(c)}
+}
+
+func (r *ValueResult) Resolve(data []byte, err error) {
+	r.data = data
+	r.resolve(err)
+}
+
+// Scan decodes the value into object, it returns the error of the command, redis.Nil for a missing key.
+func (r *ValueResult) Scan(object interface{}) error {
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	if err := r.codec.Unmarshal(r.data, object); err != nil {
+		return errors.Wrap(err, "failed to unmarshal object")
+	}
+
+	return nil
+}
+
+func NewValuesResult(c codec.Codec) *ValuesResult {
+	return &ValuesResult{codec: codec.Default(c)}
+}
+
+func (r *ValuesResult) Resolve(vals []interface{}, err error) {
+	r.vals = vals
+	r.resolve(err)
+}
+
+// Val returns the raw values, strings or nil.
+func (r *ValuesResult) Val() []interface{} {
+	return r.vals
+}
+
+// Scan decodes the values into the slice results points to, see codec.UnmarshalAll.
+func (r *ValuesResult) Scan(results interface{}) error {
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	if err := codec.UnmarshalAll(r.codec, r.vals, results); err != nil {
+		return errors.Wrap(err, "failed to unmarshal objects")
+	}
+
+	return nil
+}
+
+func (r *StringMapResult) Resolve(val map[string]string, err error) {
+	r.val = val
+	r.resolve(err)
+}
+
+func (r *StringMapResult) Val() map[string]string {
+	return r.val
+}
+
+func (r *StringMapResult) Result() (map[string]string, error) {
+	return r.val, r.Err()
+}
+
+func (r *ZResult) Resolve(val []redis.Z, err error) {
+	r.val = val
+	r.resolve(err)
+}
+
+func (r *ZResult) Val() []redis.Z {
+	return r.val
+}
+
+func (r *ZResult) Result() ([]redis.Z, error) {
+	return r.val, r.Err()
+}
+
+func NewScriptResult(c codec.Codec) *ScriptResult {
+	return &ScriptResult{codec: codec.Default(c)}
+}
+
+func (r *ScriptResult) Resolve(val interface{}, err error) {
+	r.val = val
+	r.resolve(err)
+}
+
+// Val returns the raw reply: an int64, a string, a []interface{} of them or nil.
+func (r *ScriptResult) Val() interface{} {
+	return r.val
+}
+
+func (r *ScriptResult) Result() (interface{}, error) {
+	return r.val, r.Err()
+}
+
+// Int returns an integer reply, Lua numbers are truncated to integers by redis.
+func (r *ScriptResult) Int() (int64, error) {
+	if err := r.Err(); err != nil {
+		return 0, err
+	}
+
+	val, ok := r.val.(int64)
+	if !ok {
+		return 0, errors.Errorf("unexpected reply type %T, expected an integer", r.val)
+	}
+
+	return val, nil
+}
+
+// Bool returns a boolean reply, Lua true is the integer 1 and false is nil, so redis.Nil is false.
+func (r *ScriptResult) Bool() (bool, error) {
+	if errors.Cause(r.Err()) == redis.Nil {
+		return false, nil
+	}
+
+	val, err := r.Int()
+	return val == 1, err
+}
+
+// Text returns a string reply.
+func (r *ScriptResult) Text() (string, error) {
+	if err := r.Err(); err != nil {
+		return "", err
+	}
+
+	val, ok := r.val.(string)
+	if !ok {
+		return "", errors.Errorf("unexpected reply type %T, expected a string", r.val)
+	}
+
+	return val, nil
+}
+
+// Scan decodes a string reply into object, or an array reply into the slice object points to like
+// ValuesResult, with the codec of the cache. Values written by the script itself must be encoded
+// like the ones of Set to be decoded.
+func (r *ScriptResult) Scan(object interface{}) error {
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	switch val := r.val.(type) {
+	case string:
+		if err := r.codec.Unmarshal([]byte(val), object); err != nil {
+			return errors.Wrap(err, "failed to unmarshal object")
+		}
+	case []interface{}:
+		if err := codec.UnmarshalAll(r.codec, val, object); err != nil {
+			return errors.Wrap(err, "failed to unmarshal objects")
+		}
+	default:
+		return errors.Errorf("unexpected reply type %T, expected a string or an array", r.val)
+	}
+
+	return nil
+}
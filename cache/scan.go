@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"sync"
+
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultScanCount is the SCAN count hint of Keys and RemoveByPattern without one.
+	DefaultScanCount = 1000
+)
+
+type (
+	// Iterator walks the keys returned by Scan, a key may be returned more than once.
+	//
+	//	iter := c.Scan("hotel:*", 100)
+	//	for iter.Next() {
+	//		key := iter.Val()
+	//	}
+	//	if err := iter.Err(); err != nil {
+	//	}
+	Iterator interface {
+		Next() bool
+		Val() string
+		Err() error
+	}
+
+	nodesIterator struct {
+		nodes   func() ([]redis.Cmdable, error)
+		pattern string
+		count   int64
+
+		once    sync.Once
+		clients []redis.Cmdable
+		current *redis.ScanIterator
+		err     error
+	}
+
+	keysIterator struct {
+		keys []string
+		key  string
+		err  error
+	}
+)
+
+// ScanNodes returns an Iterator running SCAN on each of the nodes in turn, nodes is only called
+// by the first Next.
+func ScanNodes(nodes func() ([]redis.Cmdable, error), pattern string, count int64) Iterator {
+	if count <= 0 {
+		count = DefaultScanCount
+	}
+
+	return &nodesIterator{nodes: nodes, pattern: pattern, count: count}
+}
+
+// ScanMasters returns the nodes of ScanNodes for client, every master of a cluster or client itself.
+func ScanMasters(client redis.Cmdable) func() ([]redis.Cmdable, error) {
+	return func() ([]redis.Cmdable, error) {
+		cluster, ok := client.(*redis.ClusterClient)
+		if !ok {
+			return []redis.Cmdable{client}, nil
+		}
+
+		var (
+			mu      sync.Mutex
+			masters []redis.Cmdable
+		)
+
+		err := cluster.ForEachMaster(func(master *redis.Client) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			masters = append(masters, master)
+			return nil
+		})
+
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list master nodes")
+		}
+
+		return masters, nil
+	}
+}
+
+func (i *nodesIterator) Next() bool {
+	i.once.Do(func() {
+		i.clients, i.err = i.nodes()
+	})
+
+	for i.err == nil {
+		if i.current != nil {
+			if i.current.Next() {
+				return true
+			}
+
+			if err := i.current.Err(); err != nil {
+				i.err = errors.Wrapf(err, "failed to scan redis pattern %s!", i.pattern)
+				return false
+			}
+		}
+
+		if len(i.clients) == 0 {
+			return false
+		}
+
+		i.current = i.clients[0].Scan(0, i.pattern, i.count).Iterator()
+		i.clients = i.clients[1:]
+	}
+
+	return false
+}
+
+func (i *nodesIterator) Val() string {
+	if i.current == nil {
+		return ""
+	}
+
+	return i.current.Val()
+}
+
+func (i *nodesIterator) Err() error {
+	return i.err
+}
+
+// NewKeysIterator returns an Iterator over keys, for caches that list their keys at once. Err
+// returns err once keys are exhausted.
+func NewKeysIterator(keys []string, err error) Iterator {
+	return &keysIterator{keys: keys, err: err}
+}
+
+func (i *keysIterator) Next() bool {
+	if len(i.keys) == 0 {
+		return false
+	}
+
+	i.key, i.keys = i.keys[0], i.keys[1:]
+	return true
+}
+
+func (i *keysIterator) Val() string {
+	return i.key
+}
+
+func (i *keysIterator) Err() error {
+	return i.err
+}
+
+// ScanKeys collects the distinct keys of iter.
+func ScanKeys(iter Iterator) ([]string, error) {
+	keys := make([]string, 0)
+	seen := make(map[string]struct{})
+
+	for iter.Next() {
+		key := iter.Val()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	return keys, iter.Err()
+}
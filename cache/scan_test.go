@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+)
+
+func Test_ScanNodes_walks_every_node(t *testing.T) {
+	nodes := make([]redis.Cmdable, 0)
+	expected := make([]string, 0)
+
+	for n := 0; n < 2; n++ {
+		server, err := miniredis.Run()
+		if err != nil {
+			t.Fatalf("should not error %s", err)
+		}
+		defer server.Close()
+
+		for k := 0; k < 25; k++ {
+			key := fmt.Sprintf("hotel:%d:%d", n, k)
+			_ = server.Set(key, "x")
+			expected = append(expected, key)
+		}
+		_ = server.Set(fmt.Sprintf("room:%d", n), "x")
+
+		nodes = append(nodes, redis.NewClient(&redis.Options{Addr: server.Addr()}))
+	}
+
+	keys, err := ScanKeys(ScanNodes(func() ([]redis.Cmdable, error) {
+		return nodes, nil
+	}, "hotel:*", 10))
+
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	sort.Strings(keys)
+	sort.Strings(expected)
+
+	if fmt.Sprint(keys) != fmt.Sprint(expected) {
+		t.Errorf("unexpected keys %v", keys)
+	}
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"sync"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/codec"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+// Scripts is the registry of Lua scripts of the caches backed by go-redis, its zero value is ready
+// to use.
+type Scripts struct {
+	mu      sync.RWMutex
+	scripts map[string]*redis.Script
+}
+
+// Register adds the script src as name, replacing the script previously registered as name.
+func (s *Scripts) Register(name, src string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.scripts == nil {
+		s.scripts = make(map[string]*redis.Script)
+	}
+	s.scripts[name] = redis.NewScript(src)
+}
+
+// Run runs the script registered as name with EVALSHA on client. A node answering NOSCRIPT is sent
+// the script with EVAL, which loads it there for the next runs. The reply is decoded with c.
+func (s *Scripts) Run(client redis.Cmdable, c codec.Codec, name string, keys []string, args ...interface{}) *ScriptResult {
+	r := NewScriptResult(c)
+
+	s.mu.RLock()
+	script, ok := s.scripts[name]
+	s.mu.RUnlock()
+
+	if !ok {
+		r.Resolve(nil, errors.Errorf("script %s is not registered!", name))
+		return r
+	}
+
+	val, err := script.Run(client, keys, args...).Result()
+	if err != nil && err != redis.Nil {
+		err = errors.Wrapf(err, "failed to run script %s", name)
+	}
+	r.Resolve(val, err)
+
+	return r
+}
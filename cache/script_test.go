@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+func Test_Scripts_run_with_NOSCRIPT_fallback_and_decode_replies(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+	defer server.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	scripts := &Scripts{}
+	scripts.Register("swap", `
+		local old = redis.call("GET", KEYS[1])
+		redis.call("SET", KEYS[1], ARGV[1])
+		return old
+	`)
+	scripts.Register("rooms", `return {ARGV[1], ARGV[2]}`)
+	scripts.Register("count", `return redis.call("INCRBY", KEYS[1], ARGV[1])`)
+
+	if err := scripts.Run(client, nil, "swap", []string{"hotel"}, "Ayana").Err(); errors.Cause(err) != redis.Nil {
+		t.Errorf("should be redis.Nil for a nil reply, got %v", err)
+	}
+
+	name := ""
+	if err := scripts.Run(client, nil, "swap", []string{"hotel"}, "Mulia").Scan(&name); err != nil || name != "Ayana" {
+		t.Errorf("unexpected name %s %v", name, err)
+	}
+
+	var rooms []string
+	if err := scripts.Run(client, nil, "rooms", nil, "deluxe", "suite").Scan(&rooms); err != nil || len(rooms) != 2 || rooms[1] != "suite" {
+		t.Errorf("unexpected rooms %v %v", rooms, err)
+	}
+
+	if count, err := scripts.Run(client, nil, "count", []string{"count"}, 2).Int(); err != nil || count != 2 {
+		t.Errorf("unexpected count %d %v", count, err)
+	}
+
+	if err := scripts.Run(client, nil, "missing", nil).Err(); err == nil {
+		t.Errorf("should not run a script that isn't registered")
+	}
+}
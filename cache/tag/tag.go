@@ -0,0 +1,150 @@
+// Package tag adds tag-based invalidation to a cache.Cache: keys are set with tags, and every key
+// of a tag is removed at once by InvalidateTag. The keys of a tag are kept in a redis set, named
+// with a {hash tag} so it lives in a single slot on redis-cluster. Tagging runs a Lua script, so
+// the cache must be a redis one.
+package tag
+
+import (
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultPrefix    = "tag:"
+	DefaultBatchSize = 1000
+
+	addScript = "tag:add"
+)
+
+var (
+	// - the set only outlives its keys: its ttl is extended, never shortened, and dropped for good
+	//   once it holds a key without expiration, ARGV[2] is 0 for such a key
+	addSource = `
+local ttl = redis.call("pttl", KEYS[1])
+redis.call("sadd", KEYS[1], ARGV[1])
+local expiration = tonumber(ARGV[2])
+if expiration == 0 then
+	if ttl >= 0 then
+		redis.call("persist", KEYS[1])
+	end
+elseif ttl == -2 or (ttl >= 0 and ttl < expiration) then
+	redis.call("pexpire", KEYS[1], expiration)
+end
+return 1`
+)
+
+type (
+	Option struct {
+		// Prefix is prepended to the set of each tag, defaults to "tag:".
+		Prefix string
+		// TTL is how long a tag set is kept at least after a SetWithTags, it is raised to the ttl of
+		// the key and never shortens the ttl the set already has. A set holding a key without
+		// expiration is kept until its tag is invalidated, as are all sets when TTL is 0.
+		TTL time.Duration
+		// BatchSize is the number of keys removed per pipeline by InvalidateTag.
+		BatchSize int
+	}
+
+	Cache interface {
+		cache.Cache
+		// SetWithTags sets key like SetWithExpiration and adds it to the set of every tag.
+		SetWithTags(key string, value interface{}, ttl time.Duration, tags ...string) error
+		// InvalidateTag removes every key set with tag.
+		InvalidateTag(tag string) error
+	}
+
+	tagged struct {
+		cache.Cache
+		prefix    string
+		ttl       time.Duration
+		batchSize int
+	}
+)
+
+func New(c cache.Cache, option *Option) (Cache, error) {
+	if c == nil {
+		return nil, errors.New("cache is required!")
+	}
+
+	opt := Option{}
+	if option != nil {
+		opt = *option
+	}
+
+	if opt.Prefix == "" {
+		opt.Prefix = DefaultPrefix
+	}
+
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = DefaultBatchSize
+	}
+
+	c.RegisterScript(addScript, addSource)
+
+	return &tagged{Cache: c, prefix: opt.Prefix, ttl: opt.TTL, batchSize: opt.BatchSize}, nil
+}
+
+// key returns the set of tag.
+func (t *tagged) key(tag string) string {
+	return t.prefix + "{" + tag + "}"
+}
+
+func (t *tagged) SetWithTags(key string, value interface{}, ttl time.Duration, tags ...string) error {
+	expiration := t.ttl
+	if ttl <= 0 {
+		expiration = 0
+	} else if expiration > 0 && ttl > expiration {
+		expiration = ttl
+	}
+
+	// - keys are tagged first, a tag set holding a key that failed to be set is harmless
+	for _, tag := range tags {
+		err := t.Cache.RunScript(addScript, []string{t.key(tag)}, key, int64(expiration/time.Millisecond)).Err()
+		if err != nil {
+			return errors.Wrapf(err, "failed to tag key %s with %s!", key, tag)
+		}
+	}
+
+	if err := t.Cache.SetWithExpiration(key, value, ttl); err != nil {
+		return errors.Wrapf(err, "failed to set cache with key %s and tags %s!", key, tags)
+	}
+
+	return nil
+}
+
+func (t *tagged) InvalidateTag(tag string) error {
+	keys := make([]string, 0)
+	if err := t.Cache.SMembers(t.key(tag), &keys); err != nil {
+		return errors.Wrapf(err, "failed to get keys of tag %s!", tag)
+	}
+
+	// - only the keys read are removed from the set, keys tagged meanwhile are kept
+	for len(keys) > 0 {
+		n := t.batchSize
+		if n > len(keys) {
+			n = len(keys)
+		}
+
+		members := make([]interface{}, n)
+		p := t.Cache.Pipeline()
+		for i, key := range keys[:n] {
+			p.Remove(key)
+			members[i] = key
+		}
+		p.SRem(t.key(tag), members...)
+
+		if err := p.Exec(); err != nil {
+			return errors.Wrapf(err, "failed to invalidate tag %s!", tag)
+		}
+
+		keys = keys[n:]
+	}
+
+	return nil
+}
+
+func (t *tagged) Client() cache.Cache {
+	return t
+}
@@ -0,0 +1,74 @@
+package tag
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/internal/cachetest"
+)
+
+func Test_InvalidateTag_removes_tagged_keys(t *testing.T) {
+	remote, server := cachetest.NewRedis(t)
+	defer server.Close()
+
+	c, _ := New(remote, &Option{TTL: time.Hour, BatchSize: 1})
+
+	_ = c.SetWithTags("hotel:1:detail", "Ayana", time.Minute, "hotel:1")
+	_ = c.SetWithTags("hotel:1:rooms", []string{"deluxe"}, 2*time.Hour, "hotel:1", "rooms")
+	_ = c.SetWithTags("hotel:2:detail", "Mulia", time.Minute, "hotel:2")
+
+	if ttl, _ := c.TTL("tag:{hotel:1}"); ttl <= time.Hour {
+		t.Errorf("should raise the tag ttl to the longest key, got %s", ttl)
+	}
+
+	if err := c.InvalidateTag("hotel:1"); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if n, _ := c.Exists("hotel:1:detail", "hotel:1:rooms", "tag:{hotel:1}"); n != 0 {
+		t.Errorf("should remove the keys of the tag, %d left", n)
+	}
+
+	if n, _ := c.Exists("hotel:2:detail"); n != 1 {
+		t.Errorf("should keep keys of other tags")
+	}
+
+	if err := c.InvalidateTag("missing"); err != nil {
+		t.Errorf("should not error on a missing tag %s", err)
+	}
+}
+
+func Test_SetWithTags_only_extends_the_tag_ttl(t *testing.T) {
+	remote, server := cachetest.NewRedis(t)
+	defer server.Close()
+
+	c, _ := New(remote, &Option{TTL: time.Minute})
+
+	_ = c.SetWithTags("hotel:1:rooms", "deluxe", 2*time.Hour, "hotel:1")
+	_ = c.SetWithTags("hotel:1:detail", "Ayana", time.Minute, "hotel:1")
+
+	if ttl, _ := c.TTL("tag:{hotel:1}"); ttl <= time.Hour {
+		t.Errorf("should keep the ttl of the longest key, got %s", ttl)
+	}
+
+	_ = c.SetWithTags("hotel:2:detail", "Mulia", 0, "hotel:2")
+	_ = c.SetWithTags("hotel:2:rooms", "suite", time.Minute, "hotel:2")
+
+	if ttl, _ := c.TTL("tag:{hotel:2}"); ttl != cache.NoExpiration {
+		t.Errorf("should not expire a tag of a key without expiration, got %s", ttl)
+	}
+
+	_ = c.SetWithTags("hotel:3:rooms", "suite", time.Minute, "hotel:3")
+	_ = c.SetWithTags("hotel:3:detail", "Raffles", 0, "hotel:3")
+
+	if ttl, _ := c.TTL("tag:{hotel:3}"); ttl != cache.NoExpiration {
+		t.Errorf("should persist a tag once it holds a key without expiration, got %s", ttl)
+	}
+
+	server.FastForward(3 * time.Hour)
+
+	if n, _ := c.Exists("tag:{hotel:1}", "tag:{hotel:2}", "tag:{hotel:3}", "hotel:2:detail"); n != 3 {
+		t.Errorf("should only expire the tag of expired keys, %d left", n)
+	}
+}
@@ -0,0 +1,113 @@
+package tiered
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type (
+	// lru is a bounded least recently used map whose entries also expire after ttl.
+	lru struct {
+		mu      sync.Mutex
+		size    int
+		ttl     time.Duration
+		items   map[string]*list.Element
+		order   *list.List
+		version uint64
+	}
+
+	item struct {
+		key       string
+		value     []byte
+		expiresAt time.Time
+	}
+)
+
+func newLRU(size int, ttl time.Duration) *lru {
+	return &lru{size: size, ttl: ttl, items: make(map[string]*list.Element), order: list.New()}
+}
+
+func (l *lru) get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	it := element.Value.(*item)
+	if time.Now().After(it.expiresAt) {
+		l.removeElement(element)
+		return nil, false
+	}
+
+	l.order.MoveToFront(element)
+	return it.value, true
+}
+
+// set stores value unless an invalidation happened after version was read, so a value fetched
+// before a concurrent write can't outlive it.
+func (l *lru) set(key string, value []byte, version uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if version != l.version {
+		return
+	}
+
+	expiresAt := time.Now().Add(l.ttl)
+
+	if element, ok := l.items[key]; ok {
+		it := element.Value.(*item)
+		it.value, it.expiresAt = value, expiresAt
+		l.order.MoveToFront(element)
+		return
+	}
+
+	l.items[key] = l.order.PushFront(&item{key: key, value: value, expiresAt: expiresAt})
+
+	for l.order.Len() > l.size {
+		l.removeElement(l.order.Back())
+	}
+}
+
+// snapshot returns the version to pass to set.
+func (l *lru) snapshot() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.version
+}
+
+func (l *lru) remove(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.version++
+	if element, ok := l.items[key]; ok {
+		l.removeElement(element)
+	}
+}
+
+func (l *lru) purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.version++
+	l.items = make(map[string]*list.Element)
+	l.order.Init()
+}
+
+func (l *lru) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.order.Len()
+}
+
+func (l *lru) removeElement(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.items, element.Value.(*item).key)
+}
@@ -0,0 +1,429 @@
+// Package tiered layers a bounded in-process LRU over a cache.Cache. Writes through an instance
+// are broadcast over pub/sub so every instance evicts the key from its LRU.
+package tiered
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/codec"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultSize    = 10000
+	DefaultTTL     = time.Minute
+	DefaultChannel = "cache:invalidate"
+
+	removeOperation = "del"
+	purgeOperation  = "flush"
+)
+
+type (
+	Option struct {
+		// Size is the maximum number of keys kept in-process.
+		Size int
+		// TTL bounds how long a key is served in-process, writes that bypass this package are
+		// only seen after it expires. Keys expiring remotely sooner than TTL aren't kept in-process,
+		// as nothing invalidates them when they expire.
+		TTL time.Duration
+		// Channel is the pub/sub channel invalidations are broadcast on.
+		Channel string
+		// Codec keeps the in-process copy of values, it should match the codec of the remote cache.
+		Codec codec.Codec
+	}
+
+	tiered struct {
+		cache.Cache
+		id     string
+		local  *lru
+		codec  codec.Codec
+		pubsub cache.PubSub
+	}
+
+	pipe struct {
+		cache.Pipe
+		t    *tiered
+		keys []string
+	}
+
+	tieredTx struct {
+		cache.Tx
+		t *tiered
+	}
+)
+
+// New returns a cache.Cache reading through an LRU in front of remote, remote must support Subscribe.
+func New(remote cache.Cache, option *Option) (cache.Cache, error) {
+	if remote == nil {
+		return nil, errors.New("remote cache is required!")
+	}
+
+	opt := Option{}
+	if option != nil {
+		opt = *option
+	}
+
+	if opt.Size <= 0 {
+		opt.Size = DefaultSize
+	}
+
+	if opt.TTL <= 0 {
+		opt.TTL = DefaultTTL
+	}
+
+	if opt.Channel == "" {
+		opt.Channel = DefaultChannel
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, errors.Wrap(err, "failed to generate instance id")
+	}
+
+	pubsub, err := remote.Subscribe(opt.Channel)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to subscribe invalidation channel %s", opt.Channel)
+	}
+
+	if err := pubsub.Receive(); err != nil {
+		return nil, errors.Wrapf(err, "failed to subscribe invalidation channel %s", opt.Channel)
+	}
+
+	t := &tiered{
+		Cache:  remote,
+		id:     hex.EncodeToString(id),
+		local:  newLRU(opt.Size, opt.TTL),
+		codec:  codec.Default(opt.Codec),
+		pubsub: pubsub,
+	}
+
+	go t.listen(pubsub.Channel())
+
+	return t, nil
+}
+
+func (t *tiered) listen(messages <-chan *redis.Message) {
+	for message := range messages {
+		parts := strings.SplitN(message.Payload, " ", 3)
+		if len(parts) < 2 || parts[0] == t.id {
+			continue
+		}
+
+		switch parts[1] {
+		case purgeOperation:
+			t.local.purge()
+		case removeOperation:
+			if len(parts) == 3 {
+				t.local.remove(parts[2])
+			}
+		}
+	}
+}
+
+func (t *tiered) invalidate(key string) error {
+	t.local.remove(key)
+
+	if err := t.pubsub.Publish(strings.Join([]string{t.id, removeOperation, key}, " ")); err != nil {
+		return errors.Wrapf(err, "failed to broadcast invalidation of key %s", key)
+	}
+
+	return nil
+}
+
+func (t *tiered) purge() error {
+	t.local.purge()
+
+	if err := t.pubsub.Publish(strings.Join([]string{t.id, purgeOperation}, " ")); err != nil {
+		return errors.Wrap(err, "failed to broadcast purge")
+	}
+
+	return nil
+}
+
+func (t *tiered) Get(key string, object interface{}) error {
+	if data, ok := t.local.get(key); ok {
+		if err := t.codec.Unmarshal(data, object); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal local key %s", key)
+		}
+		return nil
+	}
+
+	version := t.local.snapshot()
+
+	if err := t.Cache.Get(key, object); err != nil {
+		return err
+	}
+
+	if !t.outlivesLocal(key) {
+		return nil
+	}
+
+	// - values the codec can't encode are only served from the remote cache
+	if data, err := t.encode(object); err == nil {
+		t.local.set(key, data, version)
+	}
+
+	return nil
+}
+
+// outlivesLocal reports whether key lives remotely at least as long as its in-process copy would,
+// a key whose ttl can't be read isn't kept in-process.
+func (t *tiered) outlivesLocal(key string) bool {
+	ttl, err := t.Cache.TTL(key)
+	if err != nil {
+		return false
+	}
+
+	return ttl == cache.NoExpiration || ttl >= t.local.ttl
+}
+
+// encode marshals the value object points to, falling back to object for pointer receivers.
+func (t *tiered) encode(object interface{}) ([]byte, error) {
+	if data, err := t.codec.Marshal(reflect.Indirect(reflect.ValueOf(object)).Interface()); err == nil {
+		return data, nil
+	}
+
+	return t.codec.Marshal(object)
+}
+
+func (t *tiered) SetWithExpiration(key string, value interface{}, ttl time.Duration) error {
+	if err := t.Cache.SetWithExpiration(key, value, ttl); err != nil {
+		return err
+	}
+
+	return t.invalidate(key)
+}
+
+func (t *tiered) Set(key string, value interface{}) error {
+	return t.SetWithExpiration(key, value, 0)
+}
+
+func (t *tiered) Incr(key string) (int64, error) {
+	return t.IncrBy(key, 1)
+}
+
+func (t *tiered) Decr(key string) (int64, error) {
+	return t.IncrBy(key, -1)
+}
+
+func (t *tiered) IncrBy(key string, value int64) (int64, error) {
+	val, err := t.Cache.IncrBy(key, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return val, t.invalidate(key)
+}
+
+func (t *tiered) SetNX(key string, value interface{}, ttl time.Duration) (bool, error) {
+	ok, err := t.Cache.SetNX(key, value, ttl)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	return ok, t.invalidate(key)
+}
+
+func (t *tiered) Expire(key string, ttl time.Duration) (bool, error) {
+	ok, err := t.Cache.Expire(key, ttl)
+	if err != nil {
+		return false, err
+	}
+
+	return ok, t.invalidate(key)
+}
+
+func (t *tiered) GetSet(key string, value interface{}, object interface{}) error {
+	err := t.Cache.GetSet(key, value, object)
+	if err != nil && errors.Cause(err) != redis.Nil {
+		return err
+	}
+
+	if e := t.invalidate(key); e != nil {
+		return e
+	}
+
+	return err
+}
+
+func (t *tiered) SetZSetWithExpiration(key string, ttl time.Duration, data ...redis.Z) error {
+	if err := t.Cache.SetZSetWithExpiration(key, ttl, data...); err != nil {
+		return err
+	}
+
+	return t.invalidate(key)
+}
+
+func (t *tiered) SetZSet(key string, data ...redis.Z) error {
+	if err := t.Cache.SetZSet(key, data...); err != nil {
+		return err
+	}
+
+	return t.invalidate(key)
+}
+
+func (t *tiered) HMSetWithExpiration(key string, value map[string]interface{}, ttl time.Duration) error {
+	if err := t.Cache.HMSetWithExpiration(key, value, ttl); err != nil {
+		return err
+	}
+
+	return t.invalidate(key)
+}
+
+func (t *tiered) HMSet(key string, value map[string]interface{}) error {
+	if err := t.Cache.HMSet(key, value); err != nil {
+		return err
+	}
+
+	return t.invalidate(key)
+}
+
+func (t *tiered) HSetWithExpiration(key, field string, value interface{}, ttl time.Duration) error {
+	if err := t.Cache.HSetWithExpiration(key, field, value, ttl); err != nil {
+		return err
+	}
+
+	return t.invalidate(key)
+}
+
+func (t *tiered) HSet(key, field string, value interface{}) error {
+	if err := t.Cache.HSet(key, field, value); err != nil {
+		return err
+	}
+
+	return t.invalidate(key)
+}
+
+func (t *tiered) Remove(key string) error {
+	if err := t.Cache.Remove(key); err != nil {
+		return err
+	}
+
+	return t.invalidate(key)
+}
+
+func (t *tiered) RemoveByPattern(pattern string, countPerLoop int64) error {
+	if err := t.Cache.RemoveByPattern(pattern, countPerLoop); err != nil {
+		return err
+	}
+
+	return t.purge()
+}
+
+func (t *tiered) FlushDatabase() error {
+	if err := t.Cache.FlushDatabase(); err != nil {
+		return err
+	}
+
+	return t.purge()
+}
+
+func (t *tiered) FlushAll() error {
+	if err := t.Cache.FlushAll(); err != nil {
+		return err
+	}
+
+	return t.purge()
+}
+
+// RunScript invalidates keys once the script ran, as it may have written any of them.
+func (t *tiered) RunScript(name string, keys []string, args ...interface{}) *cache.ScriptResult {
+	r := t.Cache.RunScript(name, keys, args...)
+	if err := r.Err(); err != nil && errors.Cause(err) != redis.Nil {
+		return r
+	}
+
+	for _, key := range keys {
+		if err := t.invalidate(key); err != nil {
+			r.Resolve(r.Val(), err)
+			break
+		}
+	}
+
+	return r
+}
+
+func (t *tiered) Client() cache.Cache {
+	return t
+}
+
+func (t *tiered) Pipeline() cache.Pipe {
+	return &pipe{Pipe: t.Cache.Pipeline(), t: t}
+}
+
+func (t *tiered) TxPipeline() cache.Pipe {
+	return &pipe{Pipe: t.Cache.TxPipeline(), t: t}
+}
+
+// Watch reads the watched keys from the remote cache, the pipeline of its tx invalidates like Pipeline.
+func (t *tiered) Watch(fn func(tx cache.Tx) error, keys ...string) error {
+	return t.Cache.Watch(func(tx cache.Tx) error {
+		return fn(&tieredTx{Tx: tx, t: t})
+	}, keys...)
+}
+
+func (tx *tieredTx) Pipeline() cache.Pipe {
+	return &pipe{Pipe: tx.Tx.Pipeline(), t: tx.t}
+}
+
+func (p *pipe) Set(key string, value interface{}) *cache.StatusResult {
+	return p.SetWithExpiration(key, value, 0)
+}
+
+func (p *pipe) SetWithExpiration(key string, value interface{}, expired time.Duration) *cache.StatusResult {
+	p.keys = append(p.keys, key)
+	return p.Pipe.SetWithExpiration(key, value, expired)
+}
+
+func (p *pipe) Remove(key string) *cache.IntResult {
+	p.keys = append(p.keys, key)
+	return p.Pipe.Remove(key)
+}
+
+func (p *pipe) Incr(key string) *cache.IntResult {
+	return p.IncrBy(key, 1)
+}
+
+func (p *pipe) Decr(key string) *cache.IntResult {
+	return p.IncrBy(key, -1)
+}
+
+func (p *pipe) IncrBy(key string, value int64) *cache.IntResult {
+	p.keys = append(p.keys, key)
+	return p.Pipe.IncrBy(key, value)
+}
+
+func (p *pipe) SetNX(key string, value interface{}, ttl time.Duration) *cache.BoolResult {
+	p.keys = append(p.keys, key)
+	return p.Pipe.SetNX(key, value, ttl)
+}
+
+func (p *pipe) Expire(key string, ttl time.Duration) *cache.BoolResult {
+	p.keys = append(p.keys, key)
+	return p.Pipe.Expire(key, ttl)
+}
+
+func (p *pipe) GetSet(key string, value interface{}) *cache.ValueResult {
+	p.keys = append(p.keys, key)
+	return p.Pipe.GetSet(key, value)
+}
+
+// Exec invalidates the keys written by the pipeline once it ran.
+func (p *pipe) Exec() error {
+	err := p.Pipe.Exec()
+
+	for _, key := range p.keys {
+		if e := p.t.invalidate(key); e != nil && err == nil {
+			err = e
+		}
+	}
+	p.keys = nil
+
+	return err
+}
@@ -0,0 +1,208 @@
+package tiered
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/codec"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+type (
+	// remote is a shared map with a pub/sub bus standing in for redis.
+	remote struct {
+		cache.Cache
+		mu          sync.Mutex
+		data        map[string][]byte
+		expiresAt   map[string]time.Time
+		gets        int
+		subscribers []chan *redis.Message
+	}
+
+	client struct {
+		cache.Cache
+		r *remote
+	}
+
+	subscription struct {
+		cache.PubSub
+		r  *remote
+		ch chan *redis.Message
+	}
+)
+
+func (c *client) Get(key string, value interface{}) error {
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+
+	c.r.gets++
+	data, ok := c.r.live(key)
+	if !ok {
+		return errors.Wrapf(redis.Nil, "key %s does not exits", key)
+	}
+	return codec.Binary.Unmarshal(data, value)
+}
+
+func (c *client) TTL(key string) (time.Duration, error) {
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+
+	if _, ok := c.r.live(key); !ok {
+		return cache.NotExists, nil
+	}
+
+	expiresAt, ok := c.r.expiresAt[key]
+	if !ok {
+		return cache.NoExpiration, nil
+	}
+	return time.Until(expiresAt), nil
+}
+
+// live returns the value of key unless it expired, the caller holds the lock.
+func (r *remote) live(key string) ([]byte, bool) {
+	if expiresAt, ok := r.expiresAt[key]; ok && time.Now().After(expiresAt) {
+		return nil, false
+	}
+
+	data, ok := r.data[key]
+	return data, ok
+}
+
+func (c *client) SetWithExpiration(key string, value interface{}, ttl time.Duration) error {
+	data, err := codec.Binary.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+	c.r.data[key] = data
+	if ttl > 0 {
+		c.r.expiresAt[key] = time.Now().Add(ttl)
+	} else {
+		delete(c.r.expiresAt, key)
+	}
+	return nil
+}
+
+func (c *client) Subscribe(channels ...string) (cache.PubSub, error) {
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+
+	ch := make(chan *redis.Message, 10)
+	c.r.subscribers = append(c.r.subscribers, ch)
+	return &subscription{r: c.r, ch: ch}, nil
+}
+
+func (s *subscription) Receive() error {
+	return nil
+}
+
+func (s *subscription) Publish(message string) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	for _, ch := range s.r.subscribers {
+		ch <- &redis.Message{Payload: message}
+	}
+	return nil
+}
+
+func (s *subscription) Channel() <-chan *redis.Message {
+	return s.ch
+}
+
+func (s *subscription) Close() error {
+	return nil
+}
+
+func Test_Get_serves_from_local_cache(t *testing.T) {
+	r := &remote{data: map[string][]byte{"hotel": []byte("Ayana")}, expiresAt: map[string]time.Time{}}
+	c, err := New(&client{r: r}, nil)
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	for n := 0; n < 3; n++ {
+		name := ""
+		if err := c.Get("hotel", &name); err != nil || name != "Ayana" {
+			t.Fatalf("unexpected hotel %s %v", name, err)
+		}
+	}
+
+	if r.gets != 1 {
+		t.Errorf("should only get the remote once, got %d", r.gets)
+	}
+}
+
+func Test_Get_does_not_keep_keys_expiring_remotely_first(t *testing.T) {
+	r := &remote{data: map[string][]byte{}, expiresAt: map[string]time.Time{}}
+	c, _ := New(&client{r: r}, &Option{TTL: time.Minute})
+
+	if err := c.SetWithExpiration("promo", "50%", 20*time.Millisecond); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	promo := ""
+	if err := c.Get("promo", &promo); err != nil || promo != "50%" {
+		t.Fatalf("unexpected promo %s %v", promo, err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if err := c.Get("promo", &promo); errors.Cause(err) != redis.Nil {
+		t.Errorf("should not serve a key expired remotely, got %v", err)
+	}
+}
+
+func Test_Set_invalidates_other_instances(t *testing.T) {
+	r := &remote{data: map[string][]byte{"hotel": []byte("Ayana")}, expiresAt: map[string]time.Time{}}
+	first, _ := New(&client{r: r}, nil)
+	second, _ := New(&client{r: r}, nil)
+
+	name := ""
+	if err := second.Get("hotel", &name); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if err := first.Set("hotel", "Mulia"); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for second.(*tiered).local.len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := second.Get("hotel", &name); err != nil || name != "Mulia" {
+		t.Errorf("should read the new value, got %s %v", name, err)
+	}
+}
+
+func Test_lru_evicts_least_recently_used(t *testing.T) {
+	l := newLRU(2, time.Minute)
+
+	l.set("a", []byte("1"), 0)
+	l.set("b", []byte("2"), 0)
+	l.get("a")
+	l.set("c", []byte("3"), 0)
+
+	if _, ok := l.get("b"); ok {
+		t.Error("b should be evicted")
+	}
+
+	if _, ok := l.get("a"); !ok {
+		t.Error("a should be kept")
+	}
+
+	version := l.snapshot()
+	l.remove("a")
+	l.set("a", []byte("stale"), version)
+
+	if _, ok := l.get("a"); ok {
+		t.Error("should not store a value read before an invalidation")
+	}
+}
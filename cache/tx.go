@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/cache/codec"
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+type (
+	redisTx struct {
+		tx    *redis.Tx
+		codec codec.Codec
+	}
+)
+
+// NewRedisTx returns the Tx of a go-redis WATCH, values are encoded by c like the cache that created it.
+func NewRedisTx(tx *redis.Tx, c codec.Codec) Tx {
+	return &redisTx{tx: tx, codec: codec.Default(c)}
+}
+
+func (t *redisTx) Get(key string, object interface{}) error {
+	val, err := t.tx.Get(key).Bytes()
+	if err != nil {
+		return missing(err, key)
+	}
+
+	if err := t.codec.Unmarshal(val, object); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal cache with key %s!", key)
+	}
+
+	return nil
+}
+
+func (t *redisTx) HGet(key, field string, object interface{}) error {
+	val, err := t.tx.HGet(key, field).Bytes()
+	if err != nil {
+		return missing(err, key)
+	}
+
+	if err := t.codec.Unmarshal(val, object); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal field %s of key %s!", field, key)
+	}
+
+	return nil
+}
+
+func (t *redisTx) HGetAll(key string) (map[string]string, error) {
+	val, err := t.tx.HGetAll(key).Result()
+	if err != nil {
+		return nil, missing(err, key)
+	}
+
+	return val, nil
+}
+
+func (t *redisTx) Exists(keys ...string) (int64, error) {
+	val, err := t.tx.Exists(keys...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to check keys %s!", keys)
+	}
+
+	return val, nil
+}
+
+func (t *redisTx) TTL(key string) (time.Duration, error) {
+	val, err := t.tx.PTTL(key).Result()
+	if err != nil {
+		return 0, missing(err, key)
+	}
+
+	return RedisTTL(val), nil
+}
+
+func (t *redisTx) Pipeline() Pipe {
+	return NewRedisPipe(t.tx.TxPipeline(), t.codec)
+}
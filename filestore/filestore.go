@@ -8,6 +8,7 @@ type (
 		Name, Path  string
 		Content     []byte `swaggertype:"string" format:"base64" example:"U3dhZ2dlciByb2Nrcw=="`
 		ContentType string
+		Metadata    map[string]string
 	}
 
 	Writer interface {
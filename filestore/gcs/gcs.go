@@ -70,6 +70,7 @@ func (w *writer) Write(ctx context.Context, file *filestore.File, maxAge int) er
 	wc := client.Bucket(w.bucket).Object(file.Name).NewWriter(ctx)
 
 	wc.ContentType = file.ContentType
+	wc.Metadata = file.Metadata
 	wc.CacheControl = "public, max-age=" + strconv.Itoa(maxAge)
 
 	if _, err := wc.Write(file.Content); err != nil {
@@ -0,0 +1,281 @@
+package gridfs
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/filestore"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/persistent/mongo"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mgo "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	writer struct {
+		open   func(ctx context.Context) (fileBucket, error)
+		bucket string
+	}
+
+	// fileDocument is a file of the bucket. The content type and cache control are fields of the
+	// file document, next to the metadata of the caller.
+	fileDocument struct {
+		ID           primitive.ObjectID `bson:"_id"`
+		Metadata     map[string]string  `bson:"metadata"`
+		ContentType  string             `bson:"contentType"`
+		CacheControl string             `bson:"cacheControl"`
+	}
+
+	// fileBucket is the GridFS bucket of a call.
+	fileBucket interface {
+		// find returns the revisions of path, newest first; limit 0 returns all of them.
+		find(ctx context.Context, path string, limit int32) ([]fileDocument, error)
+		download(id primitive.ObjectID) ([]byte, error)
+		// upload stores content as a new revision of path with the fields of file.
+		upload(ctx context.Context, path string, content []byte, file fileDocument) error
+		delete(id primitive.ObjectID) error
+	}
+
+	driverBucket struct {
+		bucket *gridfs.Bucket
+		files  *mgo.Collection
+	}
+)
+
+func (w *writer) Open(ctx context.Context, path string, mode filestore.Mode) (*filestore.File, error) {
+	data := make([]byte, 0)
+	contentType := ""
+	metadata := make(map[string]string)
+
+	//if mode is NEW, fill data with empty slice of bytes
+	//else fetch the latest revision from gridfs, a missing file is treated as empty
+	if mode != filestore.NEW {
+		bucket, err := w.open(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "OPEN - could not open gridfs bucket")
+		}
+
+		files, err := bucket.find(ctx, path, 1)
+		if err != nil {
+			return nil, errors.Wrap(err, "OPEN - could not find current file")
+		}
+
+		if len(files) > 0 {
+			data, err = bucket.download(files[0].ID)
+			if err != nil {
+				return nil, errors.Wrap(err, "OPEN - could not read current data")
+			}
+
+			for key, value := range files[0].Metadata {
+				metadata[key] = value
+			}
+			contentType = files[0].ContentType
+		}
+	}
+
+	filename := md5.Sum([]byte(path))
+
+	//fill content with data from gridfs/empty slice of bytes
+	return &filestore.File{
+		Mode:        mode,
+		Path:        path,
+		Name:        fmt.Sprintf("%x", filename),
+		Content:     data,
+		ContentType: contentType,
+		Metadata:    metadata,
+	}, nil
+}
+
+// Write uploads the content as a new revision of file.Path and removes the older revisions,
+// so a path always resolves to a single file like an object in a bucket.
+func (w *writer) Write(ctx context.Context, file *filestore.File, maxAge int) error {
+	bucket, err := w.open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "WRITE - could not open gridfs bucket")
+	}
+
+	previous, err := bucket.find(ctx, file.Path, 0)
+	if err != nil {
+		return errors.Wrapf(err, "WRITE - unable to find previous revision of file %q", file.Path)
+	}
+
+	metadata := make(map[string]string)
+	for key, value := range file.Metadata {
+		metadata[key] = value
+	}
+
+	document := fileDocument{
+		Metadata:     metadata,
+		ContentType:  file.ContentType,
+		CacheControl: "public, max-age=" + strconv.Itoa(maxAge),
+	}
+
+	if err := bucket.upload(ctx, file.Path, file.Content, document); err != nil {
+		return errors.Wrapf(err, "WRITE - unable to write data to bucket %q, file %q", w.bucket, file.Path)
+	}
+
+	for _, f := range previous {
+		if err := bucket.delete(f.ID); err != nil {
+			return errors.Wrapf(err, "WRITE - unable to remove previous revision %s of file %q", f.ID.Hex(), file.Path)
+		}
+	}
+
+	return nil
+}
+
+func (w *writer) Delete(ctx context.Context, file *filestore.File) error {
+	bucket, err := w.open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "DELETE - could not open gridfs bucket")
+	}
+
+	files, err := bucket.find(ctx, file.Path, 0)
+	if err != nil {
+		return errors.Wrapf(err, "DELETE - unable to find file %q", file.Path)
+	}
+
+	if len(files) == 0 {
+		return errors.Wrapf(gridfs.ErrFileNotFound, "DELETE - unable to delete bucket %q, file %q", w.bucket, file.Path)
+	}
+
+	for _, f := range files {
+		if err := bucket.delete(f.ID); err != nil {
+			return errors.Wrapf(err, "DELETE - unable to delete bucket %q, file %q", w.bucket, file.Path)
+		}
+	}
+
+	return nil
+}
+
+// Close is a no-op, the connection is owned by the mongo client.
+func (w *writer) Close(ctx context.Context, file *filestore.File) error {
+	return nil
+}
+
+func (w *writer) Exist(ctx context.Context, path string) (bool, error) {
+	bucket, err := w.open(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "EXIST - could not open gridfs bucket")
+	}
+
+	files, err := bucket.find(ctx, path, 1)
+	if err != nil {
+		return false, errors.Wrapf(err, "EXIST - unable to check file existence %s", path)
+	}
+
+	return len(files) > 0, nil
+}
+
+// openBucket creates a bucket per call, the driver keeps deadlines on the bucket so it can't be shared.
+func openBucket(ctx context.Context, database *mgo.Database, name string) (fileBucket, error) {
+	b, err := gridfs.NewBucket(database, options.GridFSBucket().SetName(name))
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := b.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
+
+		if err := b.SetWriteDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	return &driverBucket{bucket: b, files: database.Collection(name + ".files")}, nil
+}
+
+func (b *driverBucket) find(ctx context.Context, path string, limit int32) ([]fileDocument, error) {
+	opts := options.GridFSFind().SetSort(bson.D{{Key: "uploadDate", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	cursor, err := b.bucket.Find(bson.D{{Key: "filename", Value: path}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]fileDocument, 0)
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func (b *driverBucket) download(id primitive.ObjectID) ([]byte, error) {
+	stream, err := b.bucket.OpenDownloadStream(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not initialize bucket reader")
+	}
+
+	data, err := ioutil.ReadAll(stream)
+	if err != nil {
+		_ = stream.Close()
+		return nil, err
+	}
+
+	if err := stream.Close(); err != nil {
+		return nil, errors.Wrap(err, "could not close bucket reader")
+	}
+
+	return data, nil
+}
+
+// upload stores the content type and cache control on the file document once it is uploaded, the
+// driver only takes the metadata.
+func (b *driverBucket) upload(ctx context.Context, path string, content []byte, file fileDocument) error {
+	opts := options.GridFSUpload().SetMetadata(file.Metadata)
+
+	id, err := b.bucket.UploadFromStream(path, bytes.NewReader(content), opts)
+	if err != nil {
+		return err
+	}
+
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "contentType", Value: file.ContentType},
+		{Key: "cacheControl", Value: file.CacheControl},
+	}}}
+
+	if _, err := b.files.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, update); err != nil {
+		return errors.Wrapf(err, "unable to set the content type of file %s", id.Hex())
+	}
+
+	return nil
+}
+
+func (b *driverBucket) delete(id primitive.ObjectID) error {
+	return b.bucket.Delete(id)
+}
+
+func NewWriter(client mongo.Mongo, database, bucket string) (filestore.Writer, error) {
+	if client == nil || client.Client() == nil {
+		return nil, errors.New("mongo client is required!")
+	}
+
+	if database == "" {
+		return nil, errors.New("database name is required!")
+	}
+
+	if bucket == "" {
+		bucket = options.DefaultName
+	}
+
+	db := client.Client().Database(database)
+
+	return &writer{
+		open: func(ctx context.Context) (fileBucket, error) {
+			return openBucket(ctx, db, bucket)
+		},
+		bucket: bucket,
+	}, nil
+}
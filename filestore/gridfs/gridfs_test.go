@@ -0,0 +1,164 @@
+package gridfs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/filestore"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+)
+
+type (
+	// memoryBucket keeps the revisions of every path, oldest first.
+	memoryBucket struct {
+		revisions map[string][]fileDocument
+		contents  map[primitive.ObjectID][]byte
+	}
+)
+
+func newWriter() (*writer, *memoryBucket) {
+	b := &memoryBucket{revisions: make(map[string][]fileDocument), contents: make(map[primitive.ObjectID][]byte)}
+
+	return &writer{
+		open: func(ctx context.Context) (fileBucket, error) {
+			return b, nil
+		},
+		bucket: "fs",
+	}, b
+}
+
+func (b *memoryBucket) find(ctx context.Context, path string, limit int32) ([]fileDocument, error) {
+	files := make([]fileDocument, 0)
+	revisions := b.revisions[path]
+
+	for n := len(revisions) - 1; n >= 0; n-- {
+		if limit > 0 && len(files) == int(limit) {
+			break
+		}
+		files = append(files, revisions[n])
+	}
+
+	return files, nil
+}
+
+func (b *memoryBucket) download(id primitive.ObjectID) ([]byte, error) {
+	data, ok := b.contents[id]
+	if !ok {
+		return nil, gridfs.ErrFileNotFound
+	}
+
+	return data, nil
+}
+
+func (b *memoryBucket) upload(ctx context.Context, path string, content []byte, file fileDocument) error {
+	file.ID = primitive.NewObjectID()
+	b.revisions[path] = append(b.revisions[path], file)
+	b.contents[file.ID] = append([]byte(nil), content...)
+
+	return nil
+}
+
+func (b *memoryBucket) delete(id primitive.ObjectID) error {
+	if _, ok := b.contents[id]; !ok {
+		return gridfs.ErrFileNotFound
+	}
+	delete(b.contents, id)
+
+	for path, revisions := range b.revisions {
+		kept := revisions[:0]
+		for _, f := range revisions {
+			if f.ID != id {
+				kept = append(kept, f)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(b.revisions, path)
+		} else {
+			b.revisions[path] = kept
+		}
+	}
+
+	return nil
+}
+
+func Test_Write_keeps_a_single_revision_and_caller_metadata(t *testing.T) {
+	ctx := context.Background()
+	w, b := newWriter()
+
+	file, err := w.Open(ctx, "invoices/1.pdf", filestore.NEW)
+	if err != nil || len(file.Content) != 0 {
+		t.Fatalf("should open an empty file, got %v %v", file, err)
+	}
+
+	file.Content = []byte("invoice")
+	file.ContentType = "application/pdf"
+	file.Metadata = map[string]string{"contentType": "scanned", "cacheControl": "mine", "owner": "ayana"}
+
+	if err := w.Write(ctx, file, 60); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	stored := b.revisions["invoices/1.pdf"][0]
+	if stored.ContentType != "application/pdf" || stored.CacheControl != "public, max-age=60" || stored.Metadata["contentType"] != "scanned" {
+		t.Errorf("should keep the reserved values apart from metadata, got %+v", stored)
+	}
+
+	file, err = w.Open(ctx, "invoices/1.pdf", filestore.APPEND)
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if string(file.Content) != "invoice" || file.ContentType != "application/pdf" ||
+		file.Metadata["contentType"] != "scanned" || file.Metadata["cacheControl"] != "mine" || file.Metadata["owner"] != "ayana" {
+		t.Errorf("unexpected file %+v", file)
+	}
+
+	file.Content = append(file.Content, " paid"...)
+	if err := w.Write(ctx, file, 60); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if revisions := b.revisions["invoices/1.pdf"]; len(revisions) != 1 || string(b.contents[revisions[0].ID]) != "invoice paid" {
+		t.Errorf("should keep only the last revision, got %+v", revisions)
+	}
+
+	if file, _ := w.Open(ctx, "invoices/1.pdf", filestore.NEW); len(file.Content) != 0 {
+		t.Errorf("should not read the content when opening NEW")
+	}
+}
+
+func Test_Exist_and_Delete(t *testing.T) {
+	ctx := context.Background()
+	w, _ := newWriter()
+
+	if exist, err := w.Exist(ctx, "invoices/1.pdf"); err != nil || exist {
+		t.Errorf("should not exist, got %v %v", exist, err)
+	}
+
+	file, _ := w.Open(ctx, "invoices/1.pdf", filestore.APPEND)
+	if len(file.Content) != 0 {
+		t.Errorf("should open a missing file as empty")
+	}
+
+	file.Content = []byte("invoice")
+	_ = w.Write(ctx, file, 0)
+
+	if exist, err := w.Exist(ctx, "invoices/1.pdf"); err != nil || !exist {
+		t.Errorf("should exist, got %v %v", exist, err)
+	}
+
+	if err := w.Delete(ctx, file); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if exist, _ := w.Exist(ctx, "invoices/1.pdf"); exist {
+		t.Errorf("should not exist once deleted")
+	}
+
+	if err := w.Delete(ctx, file); errors.Cause(err) != gridfs.ErrFileNotFound {
+		t.Errorf("should not delete a missing file, got %v", err)
+	}
+}
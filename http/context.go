@@ -17,6 +17,8 @@ type (
 		Redirect(int, string) error
 		Error(error)
 		Logger() logs.Logger
+		Request() *http.Request
+		Response() http.ResponseWriter
 
 		Param(string) string
 		ParamNames() []string
@@ -76,6 +78,14 @@ func (c *context) Logger() logs.Logger {
 	return c.server.Logger
 }
 
+func (c *context) Request() *http.Request {
+	return c.ec.Request()
+}
+
+func (c *context) Response() http.ResponseWriter {
+	return c.ec.Response()
+}
+
 func (c *context) Param(name string) string {
 	return c.ec.Param(name)
 }
@@ -0,0 +1,230 @@
+package mongo
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	mgo "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DefaultBulkChunkSize   = 1000
+	DefaultBulkConcurrency = 4
+)
+
+type (
+	// BulkUpsertOption configures BulkUpsert, zero values fall back to the defaults.
+	BulkUpsertOption struct {
+		// ChunkSize is the number of documents sent per bulk write.
+		ChunkSize int
+		// Concurrency is the number of chunks written in parallel.
+		Concurrency int
+		// Replace replaces the whole matched document instead of $set-ing the document fields.
+		Replace bool
+	}
+
+	// BulkUpsertError describes a document that could not be written.
+	BulkUpsertError struct {
+		Index    int
+		Document interface{}
+		Code     int
+		Message  string
+	}
+
+	BulkUpsertResult struct {
+		MatchedCount  int64
+		ModifiedCount int64
+		UpsertedCount int64
+		// UpsertedIDs maps the index of an upserted document to its generated _id.
+		UpsertedIDs map[int]interface{}
+		Errors      []BulkUpsertError
+	}
+)
+
+func (e BulkUpsertError) Error() string {
+	return e.Message
+}
+
+func (i *implementation) BulkUpsertWithContext(ctx context.Context, collection string, keyFields []string, documents interface{}, option *BulkUpsertOption) (*BulkUpsertResult, error) {
+	return BulkUpsertCollection(ctx, i.database.Collection(collection), keyFields, documents, option)
+}
+
+func (i *implementation) BulkUpsert(collection string, keyFields []string, documents interface{}, option *BulkUpsertOption) (*BulkUpsertResult, error) {
+	return i.BulkUpsertWithContext(context.Background(), collection, keyFields, documents, option)
+}
+
+// BulkUpsertCollection upserts every element of documents, a slice of structs or maps, into coll
+// matching existing documents on keyFields. Documents are split into chunks written unordered and in
+// parallel; documents rejected by the server are reported in BulkUpsertResult.Errors while an error is
+// only returned when a whole chunk could not be written.
+func BulkUpsertCollection(ctx context.Context, coll Collection, keyFields []string, documents interface{}, option *BulkUpsertOption) (*BulkUpsertResult, error) {
+	if len(keyFields) == 0 {
+		return nil, errors.New("key fields are required!")
+	}
+
+	values := reflect.ValueOf(documents)
+	if values.Kind() != reflect.Slice && values.Kind() != reflect.Array {
+		return nil, errors.Errorf("documents must be a slice, got %T", documents)
+	}
+
+	opt := BulkUpsertOption{}
+	if option != nil {
+		opt = *option
+	}
+
+	if opt.ChunkSize <= 0 {
+		opt.ChunkSize = DefaultBulkChunkSize
+	}
+
+	if opt.Concurrency <= 0 {
+		opt.Concurrency = DefaultBulkConcurrency
+	}
+
+	result := &BulkUpsertResult{UpsertedIDs: make(map[int]interface{}), Errors: make([]BulkUpsertError, 0)}
+
+	models := make([]mgo.WriteModel, 0, values.Len())
+	indexes := make([]int, 0, values.Len())
+
+	for n := 0; n < values.Len(); n++ {
+		document := values.Index(n).Interface()
+
+		model, err := upsertModel(keyFields, document, opt.Replace)
+		if err != nil {
+			result.Errors = append(result.Errors, BulkUpsertError{Index: n, Document: document, Message: err.Error()})
+			continue
+		}
+
+		models = append(models, model)
+		indexes = append(indexes, n)
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		failure   error
+		semaphore = make(chan struct{}, opt.Concurrency)
+	)
+
+	for start := 0; start < len(models); start += opt.ChunkSize {
+		end := start + opt.ChunkSize
+		if end > len(models) {
+			end = len(models)
+		}
+
+		semaphore <- struct{}{}
+		wg.Add(1)
+
+		go func(chunk []mgo.WriteModel, positions []int) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			rs, err := coll.BulkWrite(ctx, chunk, options.BulkWrite().SetOrdered(false))
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if rs != nil {
+				result.MatchedCount += rs.MatchedCount
+				result.ModifiedCount += rs.ModifiedCount
+				result.UpsertedCount += rs.UpsertedCount
+				for index, id := range rs.UpsertedIDs {
+					result.UpsertedIDs[positions[index]] = id
+				}
+			}
+
+			if err == nil {
+				return
+			}
+
+			exception, ok := err.(mgo.BulkWriteException)
+			if !ok {
+				for _, position := range positions {
+					result.Errors = append(result.Errors, BulkUpsertError{
+						Index: position, Document: values.Index(position).Interface(), Message: err.Error(),
+					})
+				}
+
+				if failure == nil {
+					failure = errors.Wrap(err, "BulkUpsert failed!")
+				}
+				return
+			}
+
+			for _, e := range exception.WriteErrors {
+				position := positions[e.Index]
+				result.Errors = append(result.Errors, BulkUpsertError{
+					Index: position, Document: values.Index(position).Interface(), Code: e.Code, Message: e.Message,
+				})
+			}
+		}(models[start:end], indexes[start:end])
+	}
+
+	wg.Wait()
+
+	// - chunks finish in any order, report the errors in the order of documents
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Index < result.Errors[j].Index
+	})
+
+	return result, failure
+}
+
+func upsertModel(keyFields []string, document interface{}, replace bool) (mgo.WriteModel, error) {
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal document")
+	}
+
+	doc := bson.D{}
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal document")
+	}
+
+	filter := bson.D{}
+	for _, key := range keyFields {
+		value, err := bson.Raw(raw).LookupErr(strings.Split(key, ".")...)
+		if err != nil {
+			return nil, errors.Errorf("document has no key field %s", key)
+		}
+
+		var v interface{}
+		if err := value.Unmarshal(&v); err != nil {
+			return nil, errors.Wrapf(err, "failed to read key field %s", key)
+		}
+
+		filter = append(filter, bson.E{Key: key, Value: v})
+	}
+
+	if replace {
+		return mgo.NewReplaceOneModel().SetFilter(filter).SetReplacement(doc).SetUpsert(true), nil
+	}
+
+	fields := bson.D{}
+	update := bson.D{}
+
+	for _, e := range doc {
+		if e.Key == "_id" {
+			update = append(update, bson.E{Key: "$setOnInsert", Value: bson.D{e}})
+			continue
+		}
+		fields = append(fields, e)
+	}
+
+	if len(fields) > 0 {
+		update = append(bson.D{{Key: "$set", Value: fields}}, update...)
+	}
+
+	if len(update) == 0 {
+		return nil, errors.New("document has no fields to upsert")
+	}
+
+	return mgo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true), nil
+}
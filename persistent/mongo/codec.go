@@ -0,0 +1,366 @@
+package mongo
+
+import (
+	"reflect"
+	"time"
+
+	tikettime "github.com/PAWSOME-INDONESIA/paw-utilities-go/util/tiketTime"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	// Codec registers custom encoders and decoders on the registry of the client created by New.
+	// Codecs are applied in order, so a later codec overrides the types registered by an earlier one.
+	Codec func(*bsoncodec.RegistryBuilder)
+
+	// ObjectIDHex is the hex of an ObjectID kept in a string field. ObjectIDCodec stores it as an
+	// ObjectID, so filters on an ObjectID match the documents written with it.
+	ObjectIDHex string
+
+	timeCodec     struct{}
+	decimalCodec  struct{}
+	nullDecoder   struct{}
+	hexDecoder    struct{}
+	objectIDCodec struct{}
+	hexEncoder    struct{}
+)
+
+var (
+	tTiketTime = reflect.TypeOf(tikettime.Time{})
+	tDecimal   = reflect.TypeOf(decimal.Decimal{})
+	tString    = reflect.TypeOf("")
+	tObjectID  = reflect.TypeOf(primitive.ObjectID{})
+	tHex       = reflect.TypeOf(ObjectIDHex(""))
+
+	scalarKinds = []reflect.Kind{
+		reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+		reflect.String,
+	}
+)
+
+// NewRegistry builds the registry used by New: the default registry with BSON null decoded into
+// empty strings, extended with codecs.
+func NewRegistry(codecs ...Codec) *bsoncodec.Registry {
+	rb := bson.NewRegistryBuilder().
+		RegisterDecoder(tString, decoder{})
+
+	for _, codec := range codecs {
+		if codec != nil {
+			codec(rb)
+		}
+	}
+
+	return rb.Build()
+}
+
+// TimeCodec stores tikettime.Time as a BSON datetime. It decodes datetimes, RFC3339 strings,
+// epoch milliseconds and null.
+func TimeCodec(rb *bsoncodec.RegistryBuilder) {
+	rb.RegisterCodec(tTiketTime, timeCodec{})
+}
+
+// DecimalCodec stores decimal.Decimal money values as BSON decimal128 so no precision is lost.
+// It decodes decimal128, doubles, integers, numeric strings and null.
+func DecimalCodec(rb *bsoncodec.RegistryBuilder) {
+	rb.RegisterCodec(tDecimal, decimalCodec{})
+}
+
+// NullCodec decodes BSON null into the zero value of every scalar kind, bool, numbers and strings,
+// including named types such as `type Status string`.
+func NullCodec(rb *bsoncodec.RegistryBuilder) {
+	for _, kind := range scalarKinds {
+		rb.RegisterDefaultDecoder(kind, nullDecoder{})
+	}
+}
+
+// ObjectIDCodec converts between strings and ObjectIDs: an ObjectID is decoded into a string field
+// as its hex and a hex string is decoded into an ObjectID field. Plain strings are still written as
+// strings, an ObjectIDHex field is written as an ObjectID.
+func ObjectIDCodec(rb *bsoncodec.RegistryBuilder) {
+	rb.
+		RegisterDecoder(tString, hexDecoder{}).
+		RegisterDefaultDecoder(reflect.String, hexDecoder{}).
+		RegisterDecoder(tObjectID, objectIDCodec{}).
+		RegisterEncoder(tHex, hexEncoder{}).
+		RegisterDecoder(tHex, hexDecoder{})
+}
+
+func (timeCodec) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || val.Type() != tTiketTime {
+		return bsoncodec.ValueEncoderError{Name: "TimeCodec.EncodeValue", Types: []reflect.Type{tTiketTime}, Received: val}
+	}
+
+	t := val.Interface().(tikettime.Time)
+	return vw.WriteDateTime(t.Unix()*1e3 + int64(t.Nanosecond())/1e6)
+}
+
+func (timeCodec) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != tTiketTime {
+		return bsoncodec.ValueDecoderError{Name: "TimeCodec.DecodeValue", Types: []reflect.Type{tTiketTime}, Received: val}
+	}
+
+	var t time.Time
+
+	switch vr.Type() {
+	case bsontype.DateTime:
+		dt, err := vr.ReadDateTime()
+		if err != nil {
+			return err
+		}
+		t = millis(dt)
+	case bsontype.Int64:
+		ms, err := vr.ReadInt64()
+		if err != nil {
+			return err
+		}
+		t = millis(ms)
+	case bsontype.String:
+		str, err := vr.ReadString()
+		if err != nil {
+			return err
+		}
+
+		if str != "" {
+			if t, err = time.Parse(time.RFC3339Nano, str); err != nil {
+				return errors.Wrapf(err, "cannot decode %q into a tikettime.Time", str)
+			}
+		}
+	case bsontype.Null:
+		if err := vr.ReadNull(); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("cannot decode %v into a tikettime.Time", vr.Type())
+	}
+
+	val.Set(reflect.ValueOf(tikettime.Time{Time: t}))
+	return nil
+}
+
+func millis(ms int64) time.Time {
+	return time.Unix(ms/1e3, ms%1e3*1e6).UTC()
+}
+
+func (decimalCodec) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || val.Type() != tDecimal {
+		return bsoncodec.ValueEncoderError{Name: "DecimalCodec.EncodeValue", Types: []reflect.Type{tDecimal}, Received: val}
+	}
+
+	d := val.Interface().(decimal.Decimal)
+
+	value, err := primitive.ParseDecimal128(d.String())
+	if err != nil {
+		return errors.Wrapf(err, "cannot encode %s into a decimal128", d)
+	}
+
+	return vw.WriteDecimal128(value)
+}
+
+func (decimalCodec) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != tDecimal {
+		return bsoncodec.ValueDecoderError{Name: "DecimalCodec.DecodeValue", Types: []reflect.Type{tDecimal}, Received: val}
+	}
+
+	var d decimal.Decimal
+
+	switch vr.Type() {
+	case bsontype.Decimal128:
+		value, err := vr.ReadDecimal128()
+		if err != nil {
+			return err
+		}
+
+		if d, err = decimal.NewFromString(value.String()); err != nil {
+			return errors.Wrapf(err, "cannot decode %s into a decimal.Decimal", value)
+		}
+	case bsontype.Double:
+		f, err := vr.ReadDouble()
+		if err != nil {
+			return err
+		}
+		d = decimal.NewFromFloat(f)
+	case bsontype.Int32:
+		i, err := vr.ReadInt32()
+		if err != nil {
+			return err
+		}
+		d = decimal.New(int64(i), 0)
+	case bsontype.Int64:
+		i, err := vr.ReadInt64()
+		if err != nil {
+			return err
+		}
+		d = decimal.New(i, 0)
+	case bsontype.String:
+		str, err := vr.ReadString()
+		if err != nil {
+			return err
+		}
+
+		if str != "" {
+			if d, err = decimal.NewFromString(str); err != nil {
+				return errors.Wrapf(err, "cannot decode %q into a decimal.Decimal", str)
+			}
+		}
+	case bsontype.Null:
+		if err := vr.ReadNull(); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("cannot decode %v into a decimal.Decimal", vr.Type())
+	}
+
+	val.Set(reflect.ValueOf(d))
+	return nil
+}
+
+func (nullDecoder) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() {
+		return errors.New("bad type or not settable")
+	}
+
+	if vr.Type() == bsontype.Null {
+		if err := vr.ReadNull(); err != nil {
+			return err
+		}
+
+		val.Set(reflect.Zero(val.Type()))
+		return nil
+	}
+
+	// - every other BSON type is decoded by the decoder of the registry, the driver's one for the
+	//   kind when that is this decoder
+	dec, err := dc.Registry.LookupDecoder(val.Type())
+	if err != nil {
+		return err
+	}
+
+	if _, ok := dec.(nullDecoder); ok {
+		dec = kindDecoder(val.Kind())
+	}
+
+	return dec.DecodeValue(dc, vr, val)
+}
+
+func kindDecoder(kind reflect.Kind) bsoncodec.ValueDecoder {
+	var dvd bsoncodec.DefaultValueDecoders
+
+	switch kind {
+	case reflect.Bool:
+		return bsoncodec.ValueDecoderFunc(dvd.BooleanDecodeValue)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return bsoncodec.ValueDecoderFunc(dvd.IntDecodeValue)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return bsoncodec.ValueDecoderFunc(dvd.UintDecodeValue)
+	case reflect.Float32, reflect.Float64:
+		return bsoncodec.ValueDecoderFunc(dvd.FloatDecodeValue)
+	default:
+		return bsoncodec.ValueDecoderFunc(dvd.StringDecodeValue)
+	}
+}
+
+func (hexDecoder) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Kind() != reflect.String {
+		return errors.New("bad type or not settable")
+	}
+
+	var str string
+	var err error
+
+	switch vr.Type() {
+	case bsontype.String:
+		if str, err = vr.ReadString(); err != nil {
+			return err
+		}
+	case bsontype.Symbol:
+		if str, err = vr.ReadSymbol(); err != nil {
+			return err
+		}
+	case bsontype.ObjectID:
+		id, err := vr.ReadObjectID()
+		if err != nil {
+			return err
+		}
+		str = id.Hex()
+	case bsontype.Null:
+		if err = vr.ReadNull(); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("cannot decode %v into a string type", vr.Type())
+	}
+
+	val.SetString(str)
+	return nil
+}
+
+func (hexEncoder) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || val.Type() != tHex {
+		return bsoncodec.ValueEncoderError{Name: "ObjectIDCodec.EncodeValue", Types: []reflect.Type{tHex}, Received: val}
+	}
+
+	hex := val.String()
+	if hex == "" {
+		return vw.WriteNull()
+	}
+
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return errors.Wrapf(err, "cannot encode %q into an ObjectID", hex)
+	}
+
+	return vw.WriteObjectID(id)
+}
+
+func (objectIDCodec) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || val.Type() != tObjectID {
+		return bsoncodec.ValueEncoderError{Name: "ObjectIDCodec.EncodeValue", Types: []reflect.Type{tObjectID}, Received: val}
+	}
+
+	return vw.WriteObjectID(val.Interface().(primitive.ObjectID))
+}
+
+func (objectIDCodec) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != tObjectID {
+		return bsoncodec.ValueDecoderError{Name: "ObjectIDCodec.DecodeValue", Types: []reflect.Type{tObjectID}, Received: val}
+	}
+
+	var id primitive.ObjectID
+	var err error
+
+	switch vr.Type() {
+	case bsontype.ObjectID:
+		if id, err = vr.ReadObjectID(); err != nil {
+			return err
+		}
+	case bsontype.String:
+		str, err := vr.ReadString()
+		if err != nil {
+			return err
+		}
+
+		if str != "" {
+			if id, err = primitive.ObjectIDFromHex(str); err != nil {
+				return errors.Wrapf(err, "cannot decode %q into an ObjectID", str)
+			}
+		}
+	case bsontype.Null:
+		if err = vr.ReadNull(); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("cannot decode %v into an ObjectID", vr.Type())
+	}
+
+	val.Set(reflect.ValueOf(id))
+	return nil
+}
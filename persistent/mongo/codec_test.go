@@ -0,0 +1,141 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	tikettime "github.com/PAWSOME-INDONESIA/paw-utilities-go/util/tiketTime"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	booking struct {
+		ID        string             `bson:"_id"`
+		HotelID   primitive.ObjectID `bson:"hotelId"`
+		Status    bookingStatus      `bson:"status"`
+		Nights    int                `bson:"nights"`
+		Paid      bool               `bson:"paid"`
+		Price     decimal.Decimal    `bson:"price"`
+		CheckIn   tikettime.Time     `bson:"checkIn"`
+		CheckOut  *tikettime.Time    `bson:"checkOut"`
+		Promotion string             `bson:"promotion"`
+	}
+
+	bookingStatus string
+)
+
+func Test_NewRegistry_round_trips_builtin_codecs(t *testing.T) {
+	registry := NewRegistry(TimeCodec, DecimalCodec, NullCodec, ObjectIDCodec)
+
+	checkIn := tikettime.Time{Time: time.Date(2020, 2, 3, 14, 0, 0, 0, time.UTC)}
+	in := booking{
+		ID:      primitive.NewObjectID().Hex(),
+		HotelID: primitive.NewObjectID(),
+		Status:  "PAID",
+		Price:   decimal.RequireFromString("1250000.75"),
+		CheckIn: checkIn,
+	}
+
+	raw, err := bson.MarshalWithRegistry(registry, in)
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if kind := bson.Raw(raw).Lookup("price").Type; kind != bson.TypeDecimal128 {
+		t.Errorf("price should be stored as decimal128, got %s", kind)
+	}
+
+	out := booking{}
+	if err := bson.UnmarshalWithRegistry(registry, raw, &out); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if !out.Price.Equal(in.Price) || !out.CheckIn.Equal(checkIn.Time) || out.HotelID != in.HotelID {
+		t.Errorf("unexpected booking %+v", out)
+	}
+}
+
+func Test_NewRegistry_decodes_null_and_object_ids(t *testing.T) {
+	registry := NewRegistry(TimeCodec, DecimalCodec, NullCodec, ObjectIDCodec)
+
+	id := primitive.NewObjectID()
+	raw, err := bson.Marshal(bson.M{
+		"_id":       id,
+		"hotelId":   id.Hex(),
+		"status":    nil,
+		"nights":    nil,
+		"paid":      nil,
+		"price":     "99.5",
+		"checkIn":   "2020-02-03T14:00:00Z",
+		"checkOut":  nil,
+		"promotion": nil,
+	})
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	out := booking{Status: "PAID", Nights: 2, Paid: true}
+	if err := bson.UnmarshalWithRegistry(registry, raw, &out); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if out.ID != id.Hex() || out.HotelID != id {
+		t.Errorf("should convert between string and ObjectID, got %+v", out)
+	}
+
+	if out.Status != "" || out.Nights != 0 || out.Paid || out.CheckOut != nil {
+		t.Errorf("should decode null into zero values, got %+v", out)
+	}
+
+	if out.Price.String() != "99.5" || out.CheckIn.Hour() != 14 {
+		t.Errorf("unexpected booking %+v", out)
+	}
+}
+
+func Test_ObjectIDCodec_encodes_ObjectIDHex_as_ObjectID(t *testing.T) {
+	registry := NewRegistry(NullCodec, ObjectIDCodec)
+
+	type room struct {
+		ID      ObjectIDHex   `bson:"_id"`
+		HotelID ObjectIDHex   `bson:"hotelId"`
+		Status  bookingStatus `bson:"status"`
+	}
+
+	id := primitive.NewObjectID()
+	raw, err := bson.MarshalWithRegistry(registry, room{ID: ObjectIDHex(id.Hex())})
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if value := bson.Raw(raw).Lookup("_id"); value.Type != bson.TypeObjectID || value.ObjectID() != id {
+		t.Errorf("should store an ObjectID, got %s", value)
+	}
+
+	if kind := bson.Raw(raw).Lookup("hotelId").Type; kind != bson.TypeNull {
+		t.Errorf("should store an empty hex as null, got %s", kind)
+	}
+
+	out := room{}
+	if err := bson.UnmarshalWithRegistry(registry, raw, &out); err != nil || out.ID != ObjectIDHex(id.Hex()) {
+		t.Errorf("unexpected room %+v %v", out, err)
+	}
+
+	if _, err := bson.MarshalWithRegistry(registry, room{ID: "hotel"}); err == nil {
+		t.Errorf("should not encode an invalid hex")
+	}
+}
+
+func Test_NullCodec_falls_back_to_registered_decoders(t *testing.T) {
+	// - ObjectIDCodec registered first, its decoder of named strings is replaced by the null decoder
+	registry := NewRegistry(ObjectIDCodec, NullCodec)
+
+	id := primitive.NewObjectID()
+	raw, _ := bson.Marshal(bson.M{"_id": id.Hex(), "status": "PAID"})
+
+	out := booking{}
+	if err := bson.UnmarshalWithRegistry(registry, raw, &out); err != nil || out.ID != id.Hex() || out.Status != "PAID" {
+		t.Errorf("unexpected booking %+v %v", out, err)
+	}
+}
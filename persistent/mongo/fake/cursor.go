@@ -0,0 +1,287 @@
+package fake
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/persistent/mongo"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	mgo "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	cursor struct {
+		documents []bson.D
+		current   bson.D
+		position  int
+		closed    bool
+		err       error
+	}
+
+	indexView struct {
+		store      *store
+		collection string
+	}
+
+	database struct {
+		store *store
+	}
+
+	// collection backs the mongo.Collection handed out by DB(). The driver's Cursor and SingleResult
+	// cannot be built outside the driver, so Find and Aggregate return ErrNotSupported and the
+	// FindOne* methods return an empty SingleResult; use the mongo.Mongo methods for reads instead.
+	collection struct {
+		store *store
+		name  string
+	}
+)
+
+func newCursor(documents []bson.D) mongo.Cursor {
+	return &cursor{documents: documents}
+}
+
+func (c *cursor) Next(ctx context.Context) bool {
+	if err := ctx.Err(); err != nil {
+		c.err = err
+		return false
+	}
+
+	if c.closed || c.position >= len(c.documents) {
+		return false
+	}
+
+	c.current = c.documents[c.position]
+	c.position++
+	return true
+}
+
+func (c *cursor) Close(ctx context.Context) error {
+	c.closed = true
+	return nil
+}
+
+func (c *cursor) Decode(val interface{}) error {
+	if c.current == nil {
+		return errors.New("cursor has no current document")
+	}
+
+	return decode(c.current, val)
+}
+
+func (c *cursor) Err() error {
+	return c.err
+}
+
+func decode(document bson.D, val interface{}) error {
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return err
+	}
+
+	return bson.Unmarshal(raw, val)
+}
+
+// decodeAll mirrors mgo.Cursor.All, decoding every document into the slice results points to.
+func decodeAll(documents []bson.D, results interface{}) error {
+	value := reflect.ValueOf(results)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Slice {
+		return errors.New("results argument must be a pointer to a slice")
+	}
+
+	slice := value.Elem()
+	slice.Set(slice.Slice(0, 0))
+
+	for _, document := range documents {
+		item := reflect.New(slice.Type().Elem())
+		if err := decode(document, item.Interface()); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, item.Elem()))
+	}
+
+	return nil
+}
+
+func (i *indexView) List(ctx context.Context, opts ...*options.ListIndexesOptions) (mongo.Cursor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return newCursor(i.store.listIndexes(i.collection)), nil
+}
+
+func (i *indexView) CreateMany(ctx context.Context, models []mgo.IndexModel, opts ...*options.CreateIndexesOptions) ([]string, error) {
+	names := make([]string, 0, len(models))
+	for _, model := range models {
+		name, err := i.CreateOne(ctx, model, opts...)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+func (i *indexView) CreateOne(ctx context.Context, model mgo.IndexModel, opts ...*options.CreateIndexesOptions) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	return i.store.createIndex(i.collection, model)
+}
+
+func (i *indexView) DropOne(ctx context.Context, name string, opts ...*options.DropIndexesOptions) (bson.Raw, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if name == "*" {
+		return i.DropAll(ctx, opts...)
+	}
+
+	return nil, i.store.dropIndex(i.collection, name)
+}
+
+func (i *indexView) DropAll(ctx context.Context, opts ...*options.DropIndexesOptions) (bson.Raw, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	i.store.mu.Lock()
+	delete(i.store.indexes, i.collection)
+	i.store.mu.Unlock()
+
+	return nil, nil
+}
+
+func (d *database) Collection(name string, opts ...*options.CollectionOptions) mongo.Collection {
+	return &collection{store: d.store, name: name}
+}
+
+func (c *collection) Indexes() mongo.IndexView {
+	return &indexView{store: c.store, collection: c.name}
+}
+
+func (c *collection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mgo.Cursor, error) {
+	return nil, ErrNotSupported
+}
+
+func (c *collection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mgo.Cursor, error) {
+	return nil, ErrNotSupported
+}
+
+func (c *collection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mgo.SingleResult {
+	return &mgo.SingleResult{}
+}
+
+func (c *collection) BulkWrite(ctx context.Context, models []mgo.WriteModel, opts ...*options.BulkWriteOptions) (*mgo.BulkWriteResult, error) {
+	o := options.MergeBulkWriteOptions(opts...)
+	return c.store.bulkWrite(ctx, c.name, models, o.Ordered == nil || *o.Ordered)
+}
+
+func (c *collection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return c.store.count(ctx, c.name, filter, opts...)
+}
+
+func (c *collection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mgo.DeleteResult, error) {
+	return c.delete(ctx, filter, false)
+}
+
+func (c *collection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mgo.DeleteResult, error) {
+	return c.delete(ctx, filter, true)
+}
+
+func (c *collection) delete(ctx context.Context, filter interface{}, many bool) (*mgo.DeleteResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	deleted, err := c.store.remove(c.name, filter, many)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mgo.DeleteResult{DeletedCount: deleted}, nil
+}
+
+func (c *collection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mgo.UpdateResult, error) {
+	return c.update(ctx, filter, update, true, opts...)
+}
+
+func (c *collection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mgo.UpdateResult, error) {
+	return c.update(ctx, filter, update, false, opts...)
+}
+
+func (c *collection) update(ctx context.Context, filter, update interface{}, many bool, opts ...*options.UpdateOptions) (*mgo.UpdateResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	o := options.MergeUpdateOptions(opts...)
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	w, err := c.store.update(c.name, filter, update, isTrue(o.Upsert), many, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mgo.UpdateResult{
+		MatchedCount:  w.matched,
+		ModifiedCount: w.modified,
+		UpsertedCount: w.upserted,
+		UpsertedID:    w.upsertedID,
+	}, nil
+}
+
+func (c *collection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mgo.InsertManyResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	ids := make([]interface{}, 0, len(documents))
+	for index, document := range documents {
+		id, err := c.store.insert(c.name, document)
+		if err != nil {
+			return &mgo.InsertManyResult{InsertedIDs: ids},
+				mgo.BulkWriteException{WriteErrors: bulkWriteErrors(mgo.WriteErrors{writeError(index, err)}, nil)}
+		}
+		ids = append(ids, id)
+	}
+
+	return &mgo.InsertManyResult{InsertedIDs: ids}, nil
+}
+
+func (c *collection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mgo.InsertOneResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	id, err := c.store.insert(c.name, document)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mgo.InsertOneResult{InsertedID: id}, nil
+}
+
+func (c *collection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) *mgo.SingleResult {
+	return &mgo.SingleResult{}
+}
+
+func (c *collection) FindOneAndDelete(ctx context.Context, filter interface{}, opts ...*options.FindOneAndDeleteOptions) *mgo.SingleResult {
+	return &mgo.SingleResult{}
+}
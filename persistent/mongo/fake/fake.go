@@ -0,0 +1,572 @@
+// Package fake provides an in-memory implementation of mongo.Mongo so code depending on
+// mongo can be unit tested without a running MongoDB.
+//
+// Documents are normalized through bson marshalling, so anything the driver can encode can be stored
+// and decoded back. Filters support the comparison, logical, element, array and $regex query operators,
+// updates support the field and array update operators, and aggregations support the
+// $match, $sort, $skip, $limit, $project, $count and $unwind stages.
+package fake
+
+import (
+	"context"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/persistent/mongo"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mgo "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	// ErrNotSupported is returned by operations that need a real server, such as the
+	// cursor returning methods of the Collection handed out by DB().
+	ErrNotSupported = errors.New("operation is not supported by the fake mongo")
+)
+
+type (
+	implementation struct {
+		store *store
+	}
+)
+
+// New creates an empty in-memory mongo.Mongo.
+func New() mongo.Mongo {
+	return &implementation{store: newStore()}
+}
+
+func (i *implementation) Ping() error {
+	return nil
+}
+
+// Client returns nil, there is no driver client behind the fake.
+func (i *implementation) Client() *mgo.Client {
+	return nil
+}
+
+func (i *implementation) DB() mongo.Database {
+	return &database{store: i.store}
+}
+
+func (i *implementation) Indexes(collection string) mongo.IndexView {
+	return &indexView{store: i.store, collection: collection}
+}
+
+func (i *implementation) AggregateWithContext(ctx context.Context,
+	collection string, pipeline interface{}, callback mongo.FindCallback, options ...*options.AggregateOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	stages, err := toArray(pipeline)
+	if err != nil {
+		return err
+	}
+
+	documents, err := aggregate(i.store.all(collection), stages)
+	if err != nil {
+		return err
+	}
+
+	return callback(newCursor(documents), nil)
+}
+
+func (i *implementation) FindAllWithContext(ctx context.Context, collection string, filter interface{}, results interface{}, opts ...*options.FindOptions) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "failed to find all with context")
+	}
+
+	documents, err := i.store.find(collection, findQuery(filter, opts...))
+	if err != nil {
+		return errors.Wrap(err, "failed to find all with context")
+	}
+
+	if err := decodeAll(documents, results); err != nil {
+		return errors.Wrap(err, "failed to decode all")
+	}
+
+	return nil
+}
+
+func (i *implementation) FindAll(collection string, filter interface{}, results interface{}, options ...*options.FindOptions) error {
+	return i.FindAllWithContext(context.Background(), collection, filter, results, options...)
+}
+
+func (i *implementation) FindOneWithContext(ctx context.Context, collection string, filter, object interface{}, opts ...*options.FindOneOptions) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "FindOne failed!")
+	}
+
+	o := options.MergeFindOneOptions(opts...)
+	limit := int64(1)
+
+	documents, err := i.store.find(collection, query{
+		filter: filter, sort: o.Sort, projection: o.Projection, skip: o.Skip, limit: &limit,
+	})
+	if err != nil {
+		return errors.Wrap(err, "FindOne failed!")
+	}
+
+	if len(documents) == 0 {
+		return errors.Wrap(mgo.ErrNoDocuments, "FindOne failed!")
+	}
+
+	if err := decode(documents[0], object); err != nil {
+		return errors.Wrap(err, "FindOne decode failed!")
+	}
+
+	return nil
+}
+
+func (i *implementation) FindOne(collection string, filter interface{}, object interface{}, options ...*options.FindOneOptions) error {
+	return i.FindOneWithContext(context.Background(), collection, filter, object, options...)
+}
+
+func (i *implementation) FindWithContext(ctx context.Context,
+	collection string, filter interface{}, callback mongo.FindCallback, opts ...*options.FindOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	documents, err := i.store.find(collection, findQuery(filter, opts...))
+	if err != nil {
+		return err
+	}
+
+	return callback(newCursor(documents), nil)
+}
+
+func (i *implementation) Find(collection string, filter interface{}, callback mongo.FindCallback, options ...*options.FindOptions) error {
+	return i.FindWithContext(context.Background(), collection, filter, callback, options...)
+}
+
+func (i *implementation) FindOneAndDeleteWithContext(ctx context.Context, collection string, filter interface{}, opts ...*options.FindOneAndDeleteOptions) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "FindOneAndDeleteWithContext failed!")
+	}
+
+	o := options.MergeFindOneAndDeleteOptions(opts...)
+
+	i.store.mu.Lock()
+	defer i.store.mu.Unlock()
+
+	position, err := i.store.first(collection, filter, o.Sort)
+	if err != nil {
+		return errors.Wrap(err, "FindOneAndDeleteWithContext failed!")
+	}
+
+	if position < 0 {
+		return errors.Wrap(mgo.ErrNoDocuments, "FindOneAndDeleteWithContext failed!")
+	}
+
+	i.store.removeAt(collection, position)
+	return nil
+}
+
+func (i *implementation) FindOneAndDelete(collection string, filter interface{}, options ...*options.FindOneAndDeleteOptions) error {
+	return i.FindOneAndDeleteWithContext(context.Background(), collection, filter, options...)
+}
+
+func (i *implementation) FindOneAndUpdateWithContext(ctx context.Context, collection string, filter, object interface{}, opts ...*options.FindOneAndUpdateOptions) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "FindOneAndUpdateWithContext failed!")
+	}
+
+	o := options.MergeFindOneAndUpdateOptions(opts...)
+	upsert := o.Upsert != nil && *o.Upsert
+
+	i.store.mu.Lock()
+	defer i.store.mu.Unlock()
+
+	position, err := i.store.first(collection, filter, o.Sort)
+	if err != nil {
+		return errors.Wrap(err, "FindOneAndUpdateWithContext failed!")
+	}
+
+	if position < 0 {
+		if !upsert {
+			return errors.Wrap(mgo.ErrNoDocuments, "FindOneAndUpdateWithContext failed!")
+		}
+
+		if _, err := i.store.update(collection, filter, object, true, false, false); err != nil {
+			return errors.Wrap(err, "FindOneAndUpdateWithContext failed!")
+		}
+
+		// - like the server, an upsert returns no document unless the updated one is requested
+		if o.ReturnDocument == nil || *o.ReturnDocument != options.After {
+			return errors.Wrap(mgo.ErrNoDocuments, "FindOneAndUpdateWithContext failed!")
+		}
+
+		return nil
+	}
+
+	id, _ := get(i.store.collections[collection][position], []string{"_id"})
+	if _, err := i.store.update(collection, bson.D{{Key: "_id", Value: id}}, object, false, false, false); err != nil {
+		return errors.Wrap(err, "FindOneAndUpdateWithContext failed!")
+	}
+
+	return nil
+}
+
+func (i *implementation) FindOneAndUpdate(collection string, filter, object interface{}, options ...*options.FindOneAndUpdateOptions) error {
+	return i.FindOneAndUpdateWithContext(context.Background(), collection, filter, object, options...)
+}
+
+func (i *implementation) InsertWithContext(ctx context.Context, collection string, object interface{}, options ...*options.InsertOneOptions) (*primitive.ObjectID, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Wrap(err, "InsertOneWithContext failed!")
+	}
+
+	i.store.mu.Lock()
+	inserted, err := i.store.insert(collection, object)
+	i.store.mu.Unlock()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "InsertOneWithContext failed!")
+	}
+
+	id, ok := inserted.(primitive.ObjectID)
+
+	if !ok {
+		return nil, errors.New("InsertWithContext failed to cast ObjectID")
+	}
+
+	return &id, nil
+}
+
+func (i *implementation) Insert(collection string, object interface{}, options ...*options.InsertOneOptions) (*primitive.ObjectID, error) {
+	return i.InsertWithContext(context.Background(), collection, object, options...)
+}
+
+func (i *implementation) InsertManyWithContext(ctx context.Context, collection string, documents []interface{}, opts ...*options.InsertManyOptions) ([]primitive.ObjectID, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Wrap(err, "InsertManyWithContext failed!")
+	}
+
+	o := options.MergeInsertManyOptions(opts...)
+	ordered := o.Ordered == nil || *o.Ordered
+
+	i.store.mu.Lock()
+	inserted := make([]interface{}, 0, len(documents))
+	writeErrors := make(mgo.WriteErrors, 0)
+
+	for index, document := range documents {
+		id, err := i.store.insert(collection, document)
+		if err != nil {
+			writeErrors = append(writeErrors, writeError(index, err))
+			if ordered {
+				break
+			}
+			continue
+		}
+		inserted = append(inserted, id)
+	}
+	i.store.mu.Unlock()
+
+	if len(writeErrors) > 0 {
+		return nil, errors.Wrap(mgo.BulkWriteException{WriteErrors: bulkWriteErrors(writeErrors, nil)}, "InsertManyWithContext failed!")
+	}
+
+	ids := make([]primitive.ObjectID, 0)
+
+	var err error
+	for _, id := range inserted {
+		i, ok := id.(primitive.ObjectID)
+
+		if !ok {
+			err = errors.Errorf("InsertWithContext failed to cast ObjectID %s", i)
+			break
+		}
+
+		ids = append(ids, i)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (i *implementation) InsertMany(collection string, documents []interface{}, options ...*options.InsertManyOptions) ([]primitive.ObjectID, error) {
+	return i.InsertManyWithContext(context.Background(), collection, documents, options...)
+}
+
+func (i *implementation) UpdateWithContext(ctx context.Context, collection string, filter, object interface{}, opts ...*options.UpdateOptions) error {
+	if err := i.updateWithContext(ctx, collection, filter, object, false, opts...); err != nil {
+		return errors.Wrap(err, "UpdateWithContext failed!")
+	}
+
+	return nil
+}
+
+func (i *implementation) Update(collection string, filter, object interface{}, options ...*options.UpdateOptions) error {
+	return i.UpdateWithContext(context.Background(), collection, filter, object, options...)
+}
+
+func (i *implementation) UpdateManyWithContext(ctx context.Context, collection string, filter, object interface{}, opts ...*options.UpdateOptions) error {
+	if err := i.updateWithContext(ctx, collection, filter, object, true, opts...); err != nil {
+		return errors.Wrap(err, "UpdateManyWithContext failed!")
+	}
+
+	return nil
+}
+
+func (i *implementation) UpdateMany(collection string, filter, object interface{}, options ...*options.UpdateOptions) error {
+	return i.UpdateManyWithContext(context.Background(), collection, filter, object, options...)
+}
+
+func (i *implementation) updateWithContext(ctx context.Context, collection string, filter, object interface{}, many bool, opts ...*options.UpdateOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	o := options.MergeUpdateOptions(opts...)
+
+	i.store.mu.Lock()
+	defer i.store.mu.Unlock()
+
+	_, err := i.store.update(collection, filter, object, o.Upsert != nil && *o.Upsert, many, false)
+	return err
+}
+
+func (i *implementation) DeleteManyWithContext(ctx context.Context, collection string, filter interface{}, options ...*options.DeleteOptions) error {
+	if err := i.deleteWithContext(ctx, collection, filter, true); err != nil {
+		return errors.Wrap(err, "DeleteManyWithContext failed!")
+	}
+
+	return nil
+}
+
+func (i *implementation) DeleteMany(collection string, filter interface{}, options ...*options.DeleteOptions) error {
+	return i.DeleteManyWithContext(context.Background(), collection, filter, options...)
+}
+
+func (i *implementation) DeleteWithContext(ctx context.Context, collection string, filter interface{}, options ...*options.DeleteOptions) error {
+	if err := i.deleteWithContext(ctx, collection, filter, false); err != nil {
+		return errors.Wrap(err, "DeleteWithContext failed!")
+	}
+
+	return nil
+}
+
+func (i *implementation) Delete(collection string, filter interface{}, options ...*options.DeleteOptions) error {
+	return i.DeleteWithContext(context.Background(), collection, filter, options...)
+}
+
+func (i *implementation) deleteWithContext(ctx context.Context, collection string, filter interface{}, many bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	i.store.mu.Lock()
+	defer i.store.mu.Unlock()
+
+	_, err := i.store.remove(collection, filter, many)
+	return err
+}
+
+func (i *implementation) CountWithFilterAndContext(ctx context.Context, collection string, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	total, err := i.store.count(ctx, collection, filter, opts...)
+
+	if err != nil {
+		return 0, errors.Wrapf(err, "count collection %s failed", collection)
+	}
+
+	return total, nil
+}
+
+func (i *implementation) CountWithFilter(collection string, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return i.CountWithFilterAndContext(context.Background(), collection, filter, opts...)
+}
+
+func (i *implementation) CountWithContext(ctx context.Context, collection string, opts ...*options.CountOptions) (int64, error) {
+	return i.CountWithFilterAndContext(ctx, collection, bson.D{}, opts...)
+}
+
+func (i *implementation) Count(collection string, opts ...*options.CountOptions) (int64, error) {
+	return i.CountWithContext(context.Background(), collection, opts...)
+}
+
+func (i *implementation) BulkDocumentWithContext(ctx context.Context, collection string, data []mgo.WriteModel) error {
+	_, err := i.store.bulkWrite(ctx, collection, data, true)
+	return err
+}
+
+func (i *implementation) BulkDocument(collection string, data []mgo.WriteModel) error {
+	return i.BulkDocumentWithContext(context.Background(), collection, data)
+}
+
+func (i *implementation) BulkUpsertWithContext(ctx context.Context, collection string, keyFields []string, documents interface{}, option *mongo.BulkUpsertOption) (*mongo.BulkUpsertResult, error) {
+	return mongo.BulkUpsertCollection(ctx, i.DB().Collection(collection), keyFields, documents, option)
+}
+
+func (i *implementation) BulkUpsert(collection string, keyFields []string, documents interface{}, option *mongo.BulkUpsertOption) (*mongo.BulkUpsertResult, error) {
+	return i.BulkUpsertWithContext(context.Background(), collection, keyFields, documents, option)
+}
+
+// ApplySchemaWithContext only generates and keeps the validator, the fake doesn't validate writes against it.
+func (i *implementation) ApplySchemaWithContext(ctx context.Context, collection string, document interface{}, option *mongo.SchemaOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	command, err := mongo.SchemaCommand(collection, document, option)
+	if err != nil {
+		return err
+	}
+
+	i.store.mu.Lock()
+	i.store.validators[collection] = command
+	i.store.mu.Unlock()
+
+	return nil
+}
+
+func (i *implementation) ApplySchema(collection string, document interface{}, option *mongo.SchemaOption) error {
+	return i.ApplySchemaWithContext(context.Background(), collection, document, option)
+}
+
+func findQuery(filter interface{}, opts ...*options.FindOptions) query {
+	o := options.MergeFindOptions(opts...)
+	return query{filter: filter, sort: o.Sort, projection: o.Projection, skip: o.Skip, limit: o.Limit}
+}
+
+// first returns the position of the first document matching filter in sort order or -1; callers hold the lock.
+func (s *store) first(collection string, filter, sort interface{}) (int, error) {
+	positions, documents, err := s.match(collection, filter)
+	if err != nil || len(positions) == 0 {
+		return -1, err
+	}
+
+	if sort == nil {
+		return positions[0], nil
+	}
+
+	spec, err := toDocument(sort)
+	if err != nil {
+		return -1, errors.Wrap(err, "invalid sort")
+	}
+
+	picked := 0
+	for n := 1; n < len(documents); n++ {
+		if less(documents[n], documents[picked], spec) {
+			picked = n
+		}
+	}
+
+	return positions[picked], nil
+}
+
+func (s *store) count(ctx context.Context, collection string, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	o := options.MergeCountOptions(opts...)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, documents, err := s.match(collection, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(paginate(documents, o.Skip, o.Limit))), nil
+}
+
+// bulkWrite runs models in order; an ordered write stops at the first failure while an unordered
+// one keeps going and reports every failed model.
+func (s *store) bulkWrite(ctx context.Context, collection string, models []mgo.WriteModel, ordered bool) (*mgo.BulkWriteResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(models) == 0 {
+		return nil, mgo.ErrEmptySlice
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := &mgo.BulkWriteResult{UpsertedIDs: make(map[int64]interface{})}
+	writeErrors := make(mgo.WriteErrors, 0)
+
+	for index, model := range models {
+		var w *writeResult
+		var err error
+
+		switch m := model.(type) {
+		case *mgo.InsertOneModel:
+			if _, err = s.insert(collection, m.Document); err == nil {
+				result.InsertedCount++
+			}
+		case *mgo.UpdateOneModel:
+			w, err = s.update(collection, m.Filter, m.Update, isTrue(m.Upsert), false, false)
+		case *mgo.UpdateManyModel:
+			w, err = s.update(collection, m.Filter, m.Update, isTrue(m.Upsert), true, false)
+		case *mgo.ReplaceOneModel:
+			w, err = s.update(collection, m.Filter, m.Replacement, isTrue(m.Upsert), false, true)
+		case *mgo.DeleteOneModel:
+			var deleted int64
+			deleted, err = s.remove(collection, m.Filter, false)
+			result.DeletedCount += deleted
+		case *mgo.DeleteManyModel:
+			var deleted int64
+			deleted, err = s.remove(collection, m.Filter, true)
+			result.DeletedCount += deleted
+		default:
+			err = errors.Errorf("unsupported write model %T", model)
+		}
+
+		if err != nil {
+			writeErrors = append(writeErrors, writeError(index, err))
+			if ordered {
+				break
+			}
+			continue
+		}
+
+		if w != nil {
+			result.MatchedCount += w.matched
+			result.ModifiedCount += w.modified
+			result.UpsertedCount += w.upserted
+			if w.upserted > 0 {
+				result.UpsertedIDs[int64(index)] = w.upsertedID
+			}
+		}
+	}
+
+	if len(writeErrors) > 0 {
+		return result, mgo.BulkWriteException{WriteErrors: bulkWriteErrors(writeErrors, models)}
+	}
+
+	return result, nil
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+func writeError(index int, err error) mgo.WriteError {
+	if exception, ok := err.(mgo.WriteException); ok && len(exception.WriteErrors) > 0 {
+		e := exception.WriteErrors[0]
+		e.Index = index
+		return e
+	}
+
+	return mgo.WriteError{Index: index, Message: err.Error()}
+}
+
+func bulkWriteErrors(writeErrors mgo.WriteErrors, models []mgo.WriteModel) []mgo.BulkWriteError {
+	bulkErrors := make([]mgo.BulkWriteError, 0, len(writeErrors))
+	for _, e := range writeErrors {
+		bulkError := mgo.BulkWriteError{WriteError: e}
+		if e.Index < len(models) {
+			bulkError.Request = models[e.Index]
+		}
+		bulkErrors = append(bulkErrors, bulkError)
+	}
+	return bulkErrors
+}
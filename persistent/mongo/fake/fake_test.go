@@ -0,0 +1,276 @@
+package fake
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/persistent/mongo"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mgo "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	hotel struct {
+		ID        primitive.ObjectID `bson:"_id,omitempty"`
+		Name      string             `bson:"name"`
+		City      string             `bson:"city"`
+		Rating    int                `bson:"rating"`
+		Tags      []string           `bson:"tags"`
+		Amenities []string           `bson:"amenities"`
+	}
+)
+
+func seed(t *testing.T) mongo.Mongo {
+	db := New()
+
+	hotels := []interface{}{
+		hotel{Name: "Ayana", City: "Bali", Rating: 5, Tags: []string{"beach", "spa"}},
+		hotel{Name: "Mulia", City: "Jakarta", Rating: 5, Tags: []string{"city"}},
+		hotel{Name: "Ibis", City: "Jakarta", Rating: 3, Tags: []string{"city", "budget"}},
+		hotel{Name: "Amaris", City: "Bandung", Rating: 2},
+	}
+
+	if _, err := db.InsertMany("hotels", hotels); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	return db
+}
+
+func Test_FindAll_with_operators_returns_sorted_matches(t *testing.T) {
+	db := seed(t)
+	results := make([]hotel, 0)
+
+	filter := bson.M{"rating": bson.M{"$gte": 3}, "$or": bson.A{bson.M{"city": "Jakarta"}, bson.M{"tags": "spa"}}}
+	if err := db.FindAll("hotels", filter, &results, options.Find().SetSort(bson.D{{Key: "name", Value: -1}})); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if len(results) != 3 || results[0].Name != "Mulia" || results[2].Name != "Ayana" {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
+
+func Test_Find_with_limit_and_skip_iterates_cursor(t *testing.T) {
+	db := seed(t)
+	names := make([]string, 0)
+
+	callback := func(cursor mongo.Cursor, err error) error {
+		if err != nil {
+			return err
+		}
+
+		for cursor.Next(context.Background()) {
+			h := hotel{}
+			if err := cursor.Decode(&h); err != nil {
+				return err
+			}
+			names = append(names, h.Name)
+		}
+		return nil
+	}
+
+	opts := options.Find().SetSort(bson.M{"rating": 1}).SetSkip(1).SetLimit(2)
+	if err := db.Find("hotels", bson.M{}, callback, opts); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if len(names) != 2 || names[0] != "Ibis" {
+		t.Errorf("unexpected names %v", names)
+	}
+}
+
+func Test_FindOne_not_found_returns_ErrNoDocuments(t *testing.T) {
+	db := seed(t)
+
+	err := db.FindOne("hotels", bson.M{"city": "Surabaya"}, &hotel{})
+	if errors.Cause(err) != mgo.ErrNoDocuments {
+		t.Errorf("should return ErrNoDocuments, got %v", err)
+	}
+}
+
+func Test_Update_operators_modify_document(t *testing.T) {
+	db := seed(t)
+
+	update := bson.M{
+		"$inc":      bson.M{"rating": 1},
+		"$push":     bson.M{"tags": "renovated"},
+		"$set":      bson.M{"city": "Cimahi"},
+		"$addToSet": bson.M{"amenities": bson.M{"$each": bson.A{"pool", "spa", "pool"}}},
+	}
+
+	if err := db.Update("hotels", bson.M{"name": "Amaris"}, update); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	h := hotel{}
+	if err := db.FindOne("hotels", bson.M{"name": "Amaris"}, &h); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if h.Rating != 3 || h.City != "Cimahi" || len(h.Tags) != 1 || len(h.Amenities) != 2 {
+		t.Errorf("unexpected document %+v", h)
+	}
+
+	conflict := bson.M{"$push": bson.M{"tags": "pool"}, "$addToSet": bson.M{"tags.0": "spa"}}
+	if err := db.Update("hotels", bson.M{"name": "Amaris"}, conflict); err == nil {
+		t.Errorf("should reject operators updating the same path")
+	}
+}
+
+func Test_Update_with_upsert_inserts_document(t *testing.T) {
+	db := seed(t)
+
+	opts := options.Update().SetUpsert(true)
+	if err := db.Update("hotels", bson.M{"name": "Padma"}, bson.M{"$set": bson.M{"city": "Bali"}}, opts); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	total, err := db.CountWithFilter("hotels", bson.M{"city": "Bali"})
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if total != 2 {
+		t.Errorf("should count 2 hotels in Bali, got %d", total)
+	}
+}
+
+func Test_Insert_duplicate_id_returns_error(t *testing.T) {
+	db := New()
+
+	id, err := db.Insert("hotels", hotel{Name: "Ayana"})
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if _, err := db.Insert("hotels", hotel{ID: *id, Name: "Ayana"}); err == nil {
+		t.Error("should error on duplicate _id")
+	}
+}
+
+func Test_BulkDocument_applies_models_in_order(t *testing.T) {
+	db := seed(t)
+
+	models := []mgo.WriteModel{
+		mgo.NewInsertOneModel().SetDocument(hotel{Name: "Padma", City: "Bandung", Rating: 5}),
+		mgo.NewUpdateManyModel().SetFilter(bson.M{"city": "Bandung"}).SetUpdate(bson.M{"$set": bson.M{"rating": 4}}),
+		mgo.NewDeleteOneModel().SetFilter(bson.M{"name": "Ibis"}),
+	}
+
+	if err := db.BulkDocument("hotels", models); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	total, _ := db.CountWithFilter("hotels", bson.M{"rating": 4})
+	if total != 2 {
+		t.Errorf("should update 2 hotels, got %d", total)
+	}
+
+	total, _ = db.Count("hotels")
+	if total != 4 {
+		t.Errorf("should have 4 hotels, got %d", total)
+	}
+}
+
+func Test_FindOneAndDelete_removes_first_sorted_match(t *testing.T) {
+	db := seed(t)
+
+	opts := options.FindOneAndDelete().SetSort(bson.M{"rating": 1})
+	if err := db.FindOneAndDelete("hotels", bson.M{"city": "Jakarta"}, opts); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if err := db.FindOne("hotels", bson.M{"name": "Ibis"}, &hotel{}); err == nil {
+		t.Error("Ibis should be deleted")
+	}
+}
+
+func Test_Aggregate_runs_pipeline(t *testing.T) {
+	db := seed(t)
+	result := bson.M{}
+
+	pipeline := mgo.Pipeline{
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$match", Value: bson.M{"tags": "city"}}},
+		{{Key: "$count", Value: "total"}},
+	}
+
+	callback := func(cursor mongo.Cursor, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if cursor.Next(context.Background()) {
+			return cursor.Decode(&result)
+		}
+		return nil
+	}
+
+	if err := db.AggregateWithContext(context.Background(), "hotels", pipeline, callback); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if result["total"] != int32(2) {
+		t.Errorf("should count 2, got %v", result["total"])
+	}
+}
+
+func Test_BulkUpsert_reports_counts_and_document_errors(t *testing.T) {
+	db := seed(t)
+
+	documents := []bson.M{
+		{"name": "Ayana", "city": "Bali", "rating": 4},
+		{"name": "Padma", "city": "Bali", "rating": 5},
+		{"city": "Bali"},
+	}
+
+	result, err := db.BulkUpsert("hotels", []string{"name"}, documents, &mongo.BulkUpsertOption{ChunkSize: 1})
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if result.MatchedCount != 1 || result.UpsertedCount != 1 {
+		t.Errorf("unexpected counts %+v", result)
+	}
+
+	if len(result.Errors) != 1 || result.Errors[0].Index != 2 {
+		t.Errorf("should report missing key field of document 2, got %+v", result.Errors)
+	}
+
+	if _, ok := result.UpsertedIDs[1]; !ok {
+		t.Errorf("should return upserted id of document 1")
+	}
+}
+
+func Test_BulkUpsert_reports_errors_of_every_chunk_in_order(t *testing.T) {
+	db := seed(t)
+
+	documents := []bson.M{
+		{"name": "Padma", "city": "Bali"},
+		{"city": "Bali"},
+		{"_id": primitive.NewObjectID(), "name": "Ayana", "city": "Bali"},
+		{"name": "Hermitage", "city": "Jakarta"},
+		{"_id": primitive.NewObjectID(), "name": "Mulia", "city": "Bali"},
+		{"city": "Jakarta"},
+	}
+
+	for run := 0; run < 10; run++ {
+		result, err := db.BulkUpsert("hotels", []string{"name"}, documents, &mongo.BulkUpsertOption{ChunkSize: 1, Replace: true})
+		if err != nil {
+			t.Fatalf("should not error %s", err)
+		}
+
+		indexes := make([]int, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			indexes = append(indexes, e.Index)
+		}
+
+		if len(indexes) != 4 || indexes[0] != 1 || indexes[1] != 2 || indexes[2] != 4 || indexes[3] != 5 {
+			t.Fatalf("should report errors in the order of documents, got %v", indexes)
+		}
+	}
+}
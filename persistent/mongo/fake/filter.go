@@ -0,0 +1,283 @@
+package fake
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// match reports whether document satisfies the query filter.
+func match(document bson.D, filter bson.D) (bool, error) {
+	for _, e := range filter {
+		ok, err := matchElement(document, e)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func matchElement(document bson.D, e bson.E) (bool, error) {
+	switch e.Key {
+	case "$and", "$or", "$nor":
+		clauses, ok := e.Value.(bson.A)
+		if !ok || len(clauses) == 0 {
+			return false, errors.Errorf("%s must be a nonempty array", e.Key)
+		}
+
+		for _, clause := range clauses {
+			filter, ok := clause.(bson.D)
+			if !ok {
+				return false, errors.Errorf("%s entries must be documents", e.Key)
+			}
+
+			matched, err := match(document, filter)
+			if err != nil {
+				return false, err
+			}
+
+			switch {
+			case e.Key == "$and" && !matched:
+				return false, nil
+			case e.Key == "$or" && matched:
+				return true, nil
+			case e.Key == "$nor" && matched:
+				return false, nil
+			}
+		}
+
+		return e.Key != "$or", nil
+	case "$comment":
+		return true, nil
+	}
+
+	if strings.HasPrefix(e.Key, "$") {
+		return false, errors.Errorf("unknown top level operator %s", e.Key)
+	}
+
+	return matchCondition(lookup(document, split(e.Key)), e.Value)
+}
+
+// matchCondition evaluates a field condition, either a literal value or an operator document,
+// against every value found at the field path.
+func matchCondition(values []interface{}, condition interface{}) (bool, error) {
+	operators, ok := condition.(bson.D)
+	if !ok || !isOperatorDocument(operators) {
+		return matchEqual(values, condition), nil
+	}
+
+	for _, op := range operators {
+		ok, err := matchOperator(values, op, operators)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func matchOperator(values []interface{}, op bson.E, operators bson.D) (bool, error) {
+	switch op.Key {
+	case "$eq":
+		return matchEqual(values, op.Value), nil
+	case "$ne":
+		return !matchEqual(values, op.Value), nil
+	case "$gt", "$gte", "$lt", "$lte":
+		for _, value := range expand(values) {
+			if typeOrder(value) != typeOrder(op.Value) {
+				continue
+			}
+
+			c := compare(value, op.Value)
+			if (op.Key == "$gt" && c > 0) || (op.Key == "$gte" && c >= 0) ||
+				(op.Key == "$lt" && c < 0) || (op.Key == "$lte" && c <= 0) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$in", "$nin":
+		candidates, ok := op.Value.(bson.A)
+		if !ok {
+			return false, errors.Errorf("%s needs an array", op.Key)
+		}
+
+		found := false
+		for _, candidate := range candidates {
+			if matchEqual(values, candidate) {
+				found = true
+				break
+			}
+		}
+		return found == (op.Key == "$in"), nil
+	case "$exists":
+		return (len(values) > 0) == truthy(op.Value), nil
+	case "$regex":
+		pattern, err := regex(op.Value, operators)
+		if err != nil {
+			return false, err
+		}
+		return matchRegex(values, pattern), nil
+	case "$options":
+		if _, ok := operators.Map()["$regex"]; !ok {
+			return false, errors.New("$options needs a $regex")
+		}
+		return true, nil
+	case "$not":
+		if r, ok := op.Value.(primitive.Regex); ok {
+			pattern, err := regex(r, nil)
+			if err != nil {
+				return false, err
+			}
+			return !matchRegex(values, pattern), nil
+		}
+
+		if !isOperatorDocument(op.Value) {
+			return false, errors.New("$not needs a regex or a document")
+		}
+
+		ok, err := matchCondition(values, op.Value)
+		return !ok, err
+	case "$size":
+		size, ok := toFloat(op.Value)
+		if !ok {
+			return false, errors.New("$size needs a number")
+		}
+
+		for _, value := range values {
+			if array, ok := value.(bson.A); ok && float64(len(array)) == size {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$all":
+		candidates, ok := op.Value.(bson.A)
+		if !ok {
+			return false, errors.New("$all needs an array")
+		}
+
+		if len(candidates) == 0 {
+			return false, nil
+		}
+
+		for _, candidate := range candidates {
+			if !matchEqual(values, candidate) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "$elemMatch":
+		condition, ok := op.Value.(bson.D)
+		if !ok {
+			return false, errors.New("$elemMatch needs an Object")
+		}
+
+		for _, value := range values {
+			array, ok := value.(bson.A)
+			if !ok {
+				continue
+			}
+
+			for _, item := range array {
+				var matched bool
+				var err error
+
+				if document, ok := item.(bson.D); ok && !isOperatorDocument(condition) {
+					matched, err = match(document, condition)
+				} else {
+					matched, err = matchCondition([]interface{}{item}, condition)
+				}
+
+				if err != nil {
+					return false, err
+				}
+
+				if matched {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	}
+
+	return false, errors.Errorf("unknown operator %s", op.Key)
+}
+
+// expand flattens array values so comparisons also consider their elements.
+func expand(values []interface{}) []interface{} {
+	expanded := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		expanded = append(expanded, value)
+		if array, ok := value.(bson.A); ok {
+			expanded = append(expanded, array...)
+		}
+	}
+	return expanded
+}
+
+func matchEqual(values []interface{}, expected interface{}) bool {
+	if r, ok := expected.(primitive.Regex); ok {
+		pattern, err := regex(r, nil)
+		return err == nil && matchRegex(values, pattern)
+	}
+
+	if expected == nil && len(values) == 0 {
+		return true
+	}
+
+	for _, value := range expand(values) {
+		if equal(value, expected) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchRegex(values []interface{}, pattern *regexp.Regexp) bool {
+	for _, value := range expand(values) {
+		if s, ok := value.(string); ok && pattern.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func regex(value interface{}, operators bson.D) (*regexp.Regexp, error) {
+	var pattern, flags string
+
+	switch v := value.(type) {
+	case string:
+		pattern = v
+	case primitive.Regex:
+		pattern, flags = v.Pattern, v.Options
+	default:
+		return nil, errors.New("$regex has to be a string")
+	}
+
+	if option, ok := operators.Map()["$options"].(string); ok {
+		flags = option
+	}
+
+	prefix := ""
+	for _, flag := range flags {
+		switch flag {
+		case 'i', 'm', 's':
+			prefix += string(flag)
+		}
+	}
+
+	if prefix != "" {
+		pattern = "(?" + prefix + ")" + pattern
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid regex %s", pattern)
+	}
+
+	return compiled, nil
+}
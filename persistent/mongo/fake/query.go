@@ -0,0 +1,296 @@
+package fake
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// sortDocuments orders documents by a sort specification such as bson.D{{"name", 1}, {"age", -1}}.
+func sortDocuments(documents []bson.D, spec bson.D) {
+	values := make(bson.A, len(documents))
+	for i, document := range documents {
+		values[i] = document
+	}
+
+	sortValues(values, spec)
+
+	for i, value := range values {
+		documents[i] = value.(bson.D)
+	}
+}
+
+// sortValues sorts values in place; an empty key in spec compares the value itself.
+func sortValues(values bson.A, spec bson.D) {
+	sort.SliceStable(values, func(i, j int) bool {
+		return less(values[i], values[j], spec)
+	})
+}
+
+func less(a, b interface{}, spec bson.D) bool {
+	for _, e := range spec {
+		direction := 1
+		if f, ok := toFloat(e.Value); ok && f < 0 {
+			direction = -1
+		}
+
+		c := compare(sortKey(a, e.Key, direction), sortKey(b, e.Key, direction))
+		if c != 0 {
+			return c*direction < 0
+		}
+	}
+	return false
+}
+
+// sortKey picks the value used for ordering; for arrays mongo uses the smallest element
+// ascending and the largest element descending.
+func sortKey(value interface{}, key string, direction int) interface{} {
+	candidates := []interface{}{value}
+	if key != "" {
+		candidates = lookup(value, split(key))
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	candidates = expandArrays(candidates)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	picked := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if c := compare(candidate, picked); c*direction < 0 {
+			picked = candidate
+		}
+	}
+
+	return picked
+}
+
+func expandArrays(values []interface{}) []interface{} {
+	expanded := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		if array, ok := value.(bson.A); ok {
+			expanded = append(expanded, array...)
+			continue
+		}
+		expanded = append(expanded, value)
+	}
+	return expanded
+}
+
+// project applies an inclusion or exclusion projection to a copy of document.
+func project(document bson.D, projection bson.D) (bson.D, error) {
+	if len(projection) == 0 {
+		return document, nil
+	}
+
+	inclusion := false
+	includeID := true
+
+	for _, e := range projection {
+		if e.Key == "_id" {
+			includeID = truthy(e.Value)
+			continue
+		}
+
+		if isOperatorDocument(e.Value) {
+			return nil, errors.Errorf("projection operator on %s is not supported", e.Key)
+		}
+
+		inclusion = truthy(e.Value)
+	}
+
+	if !inclusion {
+		result := copyDocument(document)
+		for _, e := range projection {
+			if !truthy(e.Value) {
+				result = unset(result, split(e.Key))
+			}
+		}
+		return result, nil
+	}
+
+	result := bson.D{}
+	if id, ok := get(document, []string{"_id"}); ok && includeID {
+		result = append(result, bson.E{Key: "_id", Value: id})
+	}
+
+	for _, e := range projection {
+		if e.Key == "_id" || !truthy(e.Value) {
+			continue
+		}
+
+		path := split(e.Key)
+		value, ok := get(document, path)
+		if !ok {
+			continue
+		}
+
+		var err error
+		if result, err = set(result, path, copyValue(value)); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// paginate applies skip then limit; a zero limit means no limit like the driver.
+func paginate(documents []bson.D, skip, limit *int64) []bson.D {
+	if skip != nil && *skip > 0 {
+		if *skip >= int64(len(documents)) {
+			return []bson.D{}
+		}
+		documents = documents[*skip:]
+	}
+
+	if limit != nil && *limit != 0 {
+		n := *limit
+		if n < 0 {
+			n = -n
+		}
+		if n < int64(len(documents)) {
+			documents = documents[:n]
+		}
+	}
+
+	return documents
+}
+
+// aggregate runs the supported subset of pipeline stages over documents.
+func aggregate(documents []bson.D, pipeline bson.A) ([]bson.D, error) {
+	for _, s := range pipeline {
+		stage, ok := s.(bson.D)
+		if !ok || len(stage) != 1 {
+			return nil, errors.New("a pipeline stage specification object must contain exactly one field")
+		}
+
+		var err error
+		if documents, err = aggregateStage(documents, stage[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	return documents, nil
+}
+
+func aggregateStage(documents []bson.D, stage bson.E) ([]bson.D, error) {
+	switch stage.Key {
+	case "$match":
+		filter, ok := stage.Value.(bson.D)
+		if !ok {
+			return nil, errors.New("the match filter must be an expression in an object")
+		}
+
+		matched := make([]bson.D, 0)
+		for _, document := range documents {
+			ok, err := match(document, filter)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				matched = append(matched, document)
+			}
+		}
+		return matched, nil
+	case "$sort":
+		spec, ok := stage.Value.(bson.D)
+		if !ok {
+			return nil, errors.New("the $sort key specification must be an object")
+		}
+		sorted := append([]bson.D{}, documents...)
+		sortDocuments(sorted, spec)
+		return sorted, nil
+	case "$skip", "$limit":
+		n, ok := toFloat(stage.Value)
+		if !ok || n < 0 {
+			return nil, errors.Errorf("%s needs a non negative number", stage.Key)
+		}
+
+		value := int64(n)
+		if stage.Key == "$skip" {
+			return paginate(documents, &value, nil), nil
+		}
+
+		if value == 0 {
+			return nil, errors.New("the limit must be positive")
+		}
+		return paginate(documents, nil, &value), nil
+	case "$project":
+		spec, ok := stage.Value.(bson.D)
+		if !ok {
+			return nil, errors.New("$project specification must be an object")
+		}
+
+		projected := make([]bson.D, 0, len(documents))
+		for _, document := range documents {
+			p, err := project(document, spec)
+			if err != nil {
+				return nil, err
+			}
+			projected = append(projected, p)
+		}
+		return projected, nil
+	case "$count":
+		field, ok := stage.Value.(string)
+		if !ok || field == "" {
+			return nil, errors.New("the count field must be a non-empty string")
+		}
+
+		if len(documents) == 0 {
+			return []bson.D{}, nil
+		}
+		return []bson.D{{{Key: field, Value: int32(len(documents))}}}, nil
+	case "$unwind":
+		return unwind(documents, stage.Value)
+	}
+
+	return nil, errors.Errorf("unsupported pipeline stage %s", stage.Key)
+}
+
+func unwind(documents []bson.D, spec interface{}) ([]bson.D, error) {
+	var path string
+	preserve := false
+
+	switch v := spec.(type) {
+	case string:
+		path = v
+	case bson.D:
+		m := v.Map()
+		path, _ = m["path"].(string)
+		preserve = truthy(m["preserveNullAndEmptyArrays"])
+	}
+
+	if len(path) < 2 || path[0] != '$' {
+		return nil, errors.New("$unwind path must be prefixed with a '$'")
+	}
+
+	field := split(path[1:])
+	unwound := make([]bson.D, 0, len(documents))
+
+	for _, document := range documents {
+		value, ok := get(document, field)
+		array, isArray := value.(bson.A)
+
+		switch {
+		case isArray && len(array) > 0:
+			for _, item := range array {
+				d, err := set(copyDocument(document), field, copyValue(item))
+				if err != nil {
+					return nil, err
+				}
+				unwound = append(unwound, d)
+			}
+		case ok && value != nil && !isArray:
+			unwound = append(unwound, document)
+		case preserve:
+			unwound = append(unwound, document)
+		}
+	}
+
+	return unwound, nil
+}
@@ -0,0 +1,379 @@
+package fake
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mgo "go.mongodb.org/mongo-driver/mongo"
+)
+
+const duplicateKeyCode = 11000
+
+type (
+	// store keeps every collection as an ordered slice of normalized documents.
+	store struct {
+		mu          sync.RWMutex
+		collections map[string][]bson.D
+		indexes     map[string][]bson.D
+		validators  map[string]bson.D
+	}
+
+	query struct {
+		filter     interface{}
+		sort       interface{}
+		projection interface{}
+		skip       *int64
+		limit      *int64
+	}
+
+	writeResult struct {
+		matched, modified, upserted int64
+		upsertedID                  interface{}
+	}
+)
+
+func newStore() *store {
+	return &store{
+		collections: make(map[string][]bson.D),
+		indexes:     make(map[string][]bson.D),
+		validators:  make(map[string]bson.D),
+	}
+}
+
+// find returns copies of the documents matching q, sorted, paginated and projected.
+func (s *store) find(collection string, q query) ([]bson.D, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, documents, err := s.match(collection, q.filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return finish(documents, q)
+}
+
+func finish(documents []bson.D, q query) ([]bson.D, error) {
+	if q.sort != nil {
+		spec, err := toDocument(q.sort)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid sort")
+		}
+		sortDocuments(documents, spec)
+	}
+
+	documents = paginate(documents, q.skip, q.limit)
+
+	projection, err := toDocument(q.projection)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid projection")
+	}
+
+	results := make([]bson.D, 0, len(documents))
+	for _, document := range documents {
+		p, err := project(copyDocument(document), projection)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, p)
+	}
+
+	return results, nil
+}
+
+// match returns the positions and documents of collection matching filter; callers hold the lock.
+func (s *store) match(collection string, filter interface{}) ([]int, []bson.D, error) {
+	f, err := toDocument(filter)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid filter")
+	}
+
+	positions := make([]int, 0)
+	documents := make([]bson.D, 0)
+
+	for i, document := range s.collections[collection] {
+		ok, err := match(document, f)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if ok {
+			positions = append(positions, i)
+			documents = append(documents, document)
+		}
+	}
+
+	return positions, documents, nil
+}
+
+func (s *store) all(collection string) []bson.D {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	documents := make([]bson.D, 0, len(s.collections[collection]))
+	for _, document := range s.collections[collection] {
+		documents = append(documents, copyDocument(document))
+	}
+
+	return documents
+}
+
+// insert stores object, generating an ObjectID when _id is missing; callers hold the lock.
+func (s *store) insert(collection string, object interface{}) (interface{}, error) {
+	document, err := toDocument(object)
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := get(document, []string{"_id"})
+	if !ok {
+		id = primitive.NewObjectID()
+		document = append(bson.D{{Key: "_id", Value: id}}, document...)
+	}
+
+	if err := s.checkUnique(collection, document, -1); err != nil {
+		return nil, err
+	}
+
+	s.collections[collection] = append(s.collections[collection], document)
+	return id, nil
+}
+
+// update applies update (or replacement when replace is true) to the first or every matching
+// document, inserting a new one when upsert is set and nothing matched; callers hold the lock.
+func (s *store) update(collection string, filter, update interface{}, upsert, many, replace bool) (*writeResult, error) {
+	changes, err := toDocument(update)
+	if err != nil {
+		return nil, err
+	}
+
+	positions, _, err := s.match(collection, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(positions) == 0 {
+		if !upsert {
+			return &writeResult{}, nil
+		}
+
+		f, err := toDocument(filter)
+		if err != nil {
+			return nil, err
+		}
+
+		seed, err := upsertSeed(f)
+		if err != nil {
+			return nil, err
+		}
+
+		document, err := modify(seed, changes, replace, true)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := s.insert(collection, document)
+		if err != nil {
+			return nil, err
+		}
+
+		return &writeResult{upserted: 1, upsertedID: id}, nil
+	}
+
+	if !many {
+		positions = positions[:1]
+	}
+
+	result := &writeResult{}
+	documents := s.collections[collection]
+
+	for _, position := range positions {
+		modified, err := modify(documents[position], changes, replace, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.checkUnique(collection, modified, position); err != nil {
+			return nil, err
+		}
+
+		result.matched++
+		if !reflect.DeepEqual(modified, documents[position]) {
+			result.modified++
+			documents[position] = modified
+		}
+	}
+
+	return result, nil
+}
+
+func modify(document, changes bson.D, replace, inserting bool) (bson.D, error) {
+	if replace {
+		return applyReplacement(document, changes)
+	}
+	return applyUpdate(document, changes, inserting)
+}
+
+// remove deletes the first or every document matching filter; callers hold the lock.
+func (s *store) remove(collection string, filter interface{}, many bool) (int64, error) {
+	positions, _, err := s.match(collection, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(positions) == 0 {
+		return 0, nil
+	}
+
+	if !many {
+		positions = positions[:1]
+	}
+
+	s.removeAt(collection, positions...)
+	return int64(len(positions)), nil
+}
+
+func (s *store) removeAt(collection string, positions ...int) {
+	removed := make(map[int]bool, len(positions))
+	for _, position := range positions {
+		removed[position] = true
+	}
+
+	remaining := make([]bson.D, 0, len(s.collections[collection]))
+	for i, document := range s.collections[collection] {
+		if !removed[i] {
+			remaining = append(remaining, document)
+		}
+	}
+
+	s.collections[collection] = remaining
+}
+
+// checkUnique enforces _id and unique index constraints for document, ignoring the document at skip.
+func (s *store) checkUnique(collection string, document bson.D, skip int) error {
+	indexes := append([]bson.D{{{Key: "key", Value: bson.D{{Key: "_id", Value: int32(1)}}}, {Key: "name", Value: "_id_"}, {Key: "unique", Value: true}}},
+		s.indexes[collection]...)
+
+	for _, index := range indexes {
+		spec := index.Map()
+		if !truthy(spec["unique"]) {
+			continue
+		}
+
+		keys, _ := spec["key"].(bson.D)
+		value := indexValue(document, keys)
+
+		for i, existing := range s.collections[collection] {
+			if i == skip {
+				continue
+			}
+
+			if equal(indexValue(existing, keys), value) {
+				return mgo.WriteException{WriteErrors: mgo.WriteErrors{{
+					Code: duplicateKeyCode,
+					Message: fmt.Sprintf("E11000 duplicate key error collection: %s index: %s dup key: %v",
+						collection, spec["name"], value),
+				}}}
+			}
+		}
+	}
+
+	return nil
+}
+
+func indexValue(document bson.D, keys bson.D) bson.A {
+	value := make(bson.A, 0, len(keys))
+	for _, key := range keys {
+		v, _ := get(document, split(key.Key))
+		value = append(value, v)
+	}
+	return value
+}
+
+func (s *store) createIndex(collection string, model mgo.IndexModel) (string, error) {
+	keys, err := toDocument(model.Keys)
+	if err != nil {
+		return "", err
+	}
+
+	if len(keys) == 0 {
+		return "", errors.New("index keys cannot be empty")
+	}
+
+	name := indexName(keys)
+	if model.Options != nil && model.Options.Name != nil {
+		name = *model.Options.Name
+	}
+
+	spec := bson.D{{Key: "v", Value: int32(2)}, {Key: "key", Value: keys}, {Key: "name", Value: name}}
+	if model.Options != nil && model.Options.Unique != nil && *model.Options.Unique {
+		spec = append(spec, bson.E{Key: "unique", Value: true})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, index := range s.indexes[collection] {
+		if index.Map()["name"] == name {
+			return name, nil
+		}
+	}
+
+	if truthy(spec.Map()["unique"]) {
+		documents := s.collections[collection]
+		for i := range documents {
+			for j := i + 1; j < len(documents); j++ {
+				if equal(indexValue(documents[i], keys), indexValue(documents[j], keys)) {
+					return "", errors.Errorf("E11000 duplicate key error collection: %s index: %s", collection, name)
+				}
+			}
+		}
+	}
+
+	s.indexes[collection] = append(s.indexes[collection], spec)
+	return name, nil
+}
+
+func (s *store) dropIndex(collection, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, index := range s.indexes[collection] {
+		if index.Map()["name"] == name {
+			s.indexes[collection] = append(s.indexes[collection][:i:i], s.indexes[collection][i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.Errorf("index not found with name [%s]", name)
+}
+
+func (s *store) listIndexes(collection string) []bson.D {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	indexes := []bson.D{{
+		{Key: "v", Value: int32(2)},
+		{Key: "key", Value: bson.D{{Key: "_id", Value: int32(1)}}},
+		{Key: "name", Value: "_id_"},
+	}}
+
+	for _, index := range s.indexes[collection] {
+		indexes = append(indexes, copyDocument(index))
+	}
+
+	return indexes
+}
+
+func indexName(keys bson.D) string {
+	parts := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
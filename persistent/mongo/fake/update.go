@@ -0,0 +1,358 @@
+package fake
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// applyUpdate returns a copy of document with the update operators applied.
+// inserting is true when the document is being created by an upsert so $setOnInsert takes effect.
+func applyUpdate(document bson.D, update bson.D, inserting bool) (bson.D, error) {
+	if !isOperatorDocument(update) {
+		return nil, errors.New("update document must contain key beginning with '$'")
+	}
+
+	result := copyDocument(document)
+	updated := make([]string, 0)
+
+	for _, op := range update {
+		fields, ok := op.Value.(bson.D)
+		if !ok {
+			return nil, errors.Errorf("modifier %s expects a document", op.Key)
+		}
+
+		for _, field := range fields {
+			paths := []string{field.Key}
+			if target, ok := field.Value.(string); ok && op.Key == "$rename" {
+				paths = append(paths, target)
+			}
+
+			for _, path := range paths {
+				for _, other := range updated {
+					if conflicts(path, other) {
+						return nil, errors.Errorf("Updating the path '%s' would create a conflict at '%s'", path, other)
+					}
+				}
+				updated = append(updated, path)
+			}
+
+			if field.Key == "_id" && op.Key != "$setOnInsert" && !inserting {
+				return nil, errors.New("performing an update on the path '_id' would modify the immutable field '_id'")
+			}
+
+			var err error
+			if result, err = applyOperator(result, op.Key, field, inserting); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// conflicts reports whether updating both paths touches the same field, like mongo rejects "a"
+// with "a" or "a.b".
+func conflicts(path, other string) bool {
+	a, b := split(path), split(other)
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	for n := range a {
+		if a[n] != b[n] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// applyReplacement keeps the _id of document and replaces every other field.
+func applyReplacement(document bson.D, replacement bson.D) (bson.D, error) {
+	if isOperatorDocument(replacement) {
+		return nil, errors.New("replacement document cannot contain keys beginning with '$'")
+	}
+
+	result := bson.D{}
+	if id, ok := get(document, []string{"_id"}); ok {
+		result = append(result, bson.E{Key: "_id", Value: id})
+	}
+
+	for _, e := range copyDocument(replacement) {
+		if e.Key == "_id" {
+			if len(result) > 0 && !equal(result[0].Value, e.Value) {
+				return nil, errors.New("the _id field cannot be changed")
+			}
+			continue
+		}
+		result = append(result, e)
+	}
+
+	return result, nil
+}
+
+func applyOperator(document bson.D, operator string, field bson.E, inserting bool) (bson.D, error) {
+	path := split(field.Key)
+	current, exists := get(document, path)
+
+	switch operator {
+	case "$set":
+		return set(document, path, field.Value)
+	case "$setOnInsert":
+		if !inserting {
+			return document, nil
+		}
+		return set(document, path, field.Value)
+	case "$unset":
+		return unset(document, path), nil
+	case "$inc", "$mul":
+		if !isNumber(field.Value) {
+			return nil, errors.Errorf("cannot %s with non-numeric argument", strings.TrimPrefix(operator, "$"))
+		}
+
+		if !exists {
+			if operator == "$mul" {
+				zero, _ := arithmetic(field.Value, field.Value, func(x, y float64) float64 { return 0 })
+				return set(document, path, zero)
+			}
+			return set(document, path, field.Value)
+		}
+
+		value, err := arithmetic(current, field.Value, func(x, y float64) float64 {
+			if operator == "$mul" {
+				return x * y
+			}
+			return x + y
+		})
+		if err != nil {
+			return nil, err
+		}
+		return set(document, path, value)
+	case "$min", "$max":
+		if exists {
+			c := compare(field.Value, current)
+			if (operator == "$min" && c >= 0) || (operator == "$max" && c <= 0) {
+				return document, nil
+			}
+		}
+		return set(document, path, field.Value)
+	case "$rename":
+		target, ok := field.Value.(string)
+		if !ok {
+			return nil, errors.New("the 'to' field for $rename must be a string")
+		}
+
+		if !exists {
+			return document, nil
+		}
+		return set(unset(document, path), split(target), current)
+	case "$currentDate":
+		var now interface{} = primitive.NewDateTimeFromTime(time.Now())
+		if spec, ok := field.Value.(bson.D); ok && spec.Map()["$type"] == "timestamp" {
+			now = primitive.Timestamp{T: uint32(time.Now().Unix()), I: 1}
+		}
+		return set(document, path, now)
+	case "$push", "$addToSet":
+		array, err := arrayAt(current, exists, field.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		items := bson.A{field.Value}
+		if spec, ok := field.Value.(bson.D); ok && isOperatorDocument(spec) {
+			each, ok := spec.Map()["$each"].(bson.A)
+			if !ok {
+				return nil, errors.Errorf("%s modifier needs $each", operator)
+			}
+			items = each
+		}
+
+		for _, item := range items {
+			if operator == "$addToSet" && contains(array, item) {
+				continue
+			}
+			array = append(array, item)
+		}
+
+		if spec, ok := field.Value.(bson.D); ok && isOperatorDocument(spec) && operator == "$push" {
+			if array, err = pushModifiers(array, spec); err != nil {
+				return nil, err
+			}
+		}
+		return set(document, path, array)
+	case "$pop":
+		if !exists {
+			return document, nil
+		}
+
+		array, err := arrayAt(current, exists, field.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(array) == 0 {
+			return document, nil
+		}
+
+		if direction, _ := toFloat(field.Value); direction < 0 {
+			array = array[1:]
+		} else {
+			array = array[:len(array)-1]
+		}
+		return set(document, path, array)
+	case "$pull", "$pullAll":
+		if !exists {
+			return document, nil
+		}
+
+		array, err := arrayAt(current, exists, field.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		remaining := bson.A{}
+		for _, item := range array {
+			var remove bool
+
+			if operator == "$pullAll" {
+				candidates, ok := field.Value.(bson.A)
+				if !ok {
+					return nil, errors.New("$pullAll requires an array argument")
+				}
+				remove = contains(candidates, item)
+			} else if remove, err = pulled(item, field.Value); err != nil {
+				return nil, err
+			}
+
+			if !remove {
+				remaining = append(remaining, item)
+			}
+		}
+		return set(document, path, remaining)
+	}
+
+	return nil, errors.Errorf("unknown modifier %s", operator)
+}
+
+func arrayAt(current interface{}, exists bool, field string) (bson.A, error) {
+	if !exists || current == nil {
+		return bson.A{}, nil
+	}
+
+	array, ok := current.(bson.A)
+	if !ok {
+		return nil, errors.Errorf("the field '%s' must be an array", field)
+	}
+
+	return append(bson.A{}, array...), nil
+}
+
+func pushModifiers(array bson.A, spec bson.D) (bson.A, error) {
+	m := spec.Map()
+
+	if order, ok := m["$sort"]; ok {
+		var sort bson.D
+		if document, ok := order.(bson.D); ok {
+			sort = document
+		} else {
+			sort = bson.D{{Key: "", Value: order}}
+		}
+		sortValues(array, sort)
+	}
+
+	if limit, ok := m["$slice"]; ok {
+		n, ok := toFloat(limit)
+		if !ok {
+			return nil, errors.New("$slice must be a numeric value")
+		}
+
+		size := int(math.Abs(n))
+		if size < len(array) {
+			if n < 0 {
+				array = array[len(array)-size:]
+			} else {
+				array = array[:size]
+			}
+		}
+	}
+
+	return array, nil
+}
+
+func pulled(item interface{}, condition interface{}) (bool, error) {
+	spec, ok := condition.(bson.D)
+	if !ok {
+		return matchEqual([]interface{}{item}, condition), nil
+	}
+
+	if isOperatorDocument(spec) {
+		return matchCondition([]interface{}{item}, spec)
+	}
+
+	if document, ok := item.(bson.D); ok {
+		return match(document, spec)
+	}
+
+	return false, nil
+}
+
+func contains(array bson.A, value interface{}) bool {
+	for _, item := range array {
+		if equal(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// upsertSeed builds the document inserted by an upsert from the equality conditions of filter.
+func upsertSeed(filter bson.D) (bson.D, error) {
+	seed := bson.D{}
+
+	for _, e := range filter {
+		switch {
+		case e.Key == "$and":
+			clauses, _ := e.Value.(bson.A)
+			for _, clause := range clauses {
+				document, ok := clause.(bson.D)
+				if !ok {
+					continue
+				}
+
+				nested, err := upsertSeed(document)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, n := range nested {
+					if seed, err = set(seed, split(n.Key), n.Value); err != nil {
+						return nil, err
+					}
+				}
+			}
+		case strings.HasPrefix(e.Key, "$"):
+			continue
+		default:
+			value := e.Value
+			if operators, ok := value.(bson.D); ok && isOperatorDocument(operators) {
+				eq, ok := operators.Map()["$eq"]
+				if !ok {
+					continue
+				}
+				value = eq
+			}
+
+			var err error
+			if seed, err = set(seed, split(e.Key), value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return seed, nil
+}
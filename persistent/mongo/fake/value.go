@@ -0,0 +1,457 @@
+package fake
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// toDocument normalizes any bson marshalable value (struct, bson.M, bson.D, bson.Raw ...)
+// into a bson.D whose nested documents are bson.D and nested arrays are bson.A,
+// the same shape the driver produces when decoding into an empty interface.
+func toDocument(value interface{}) (bson.D, error) {
+	if value == nil {
+		return bson.D{}, nil
+	}
+
+	raw, err := bson.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal document")
+	}
+
+	document := bson.D{}
+	if err := bson.Unmarshal(raw, &document); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal document")
+	}
+
+	return document, nil
+}
+
+// toArray normalizes slices such as mongo.Pipeline, []bson.M or bson.A into a bson.A.
+func toArray(value interface{}) (bson.A, error) {
+	wrapper, err := toDocument(bson.M{"v": value})
+	if err != nil {
+		return nil, err
+	}
+
+	array, ok := wrapper[0].Value.(bson.A)
+	if !ok {
+		return nil, errors.Errorf("value of type %T is not an array", value)
+	}
+
+	return array, nil
+}
+
+func copyDocument(document bson.D) bson.D {
+	return copyValue(document).(bson.D)
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.D:
+		c := make(bson.D, len(v))
+		for i, e := range v {
+			c[i] = bson.E{Key: e.Key, Value: copyValue(e.Value)}
+		}
+		return c
+	case bson.A:
+		c := make(bson.A, len(v))
+		for i, e := range v {
+			c[i] = copyValue(e)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+func isOperatorDocument(value interface{}) bool {
+	document, ok := value.(bson.D)
+	if !ok || len(document) == 0 {
+		return false
+	}
+
+	return strings.HasPrefix(document[0].Key, "$")
+}
+
+func split(path string) []string {
+	return strings.Split(path, ".")
+}
+
+// lookup returns every value reachable through path, walking into arrays the way
+// mongo does for dotted paths. A missing field yields an empty result.
+func lookup(value interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		return []interface{}{value}
+	}
+
+	switch v := value.(type) {
+	case bson.D:
+		for _, e := range v {
+			if e.Key == path[0] {
+				return lookup(e.Value, path[1:])
+			}
+		}
+	case bson.A:
+		if index, err := strconv.Atoi(path[0]); err == nil {
+			if index >= 0 && index < len(v) {
+				return lookup(v[index], path[1:])
+			}
+			return nil
+		}
+
+		values := make([]interface{}, 0)
+		for _, item := range v {
+			if _, ok := item.(bson.D); ok {
+				values = append(values, lookup(item, path)...)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
+
+// get returns the value stored exactly at path without array expansion.
+func get(document bson.D, path []string) (interface{}, bool) {
+	var current interface{} = document
+
+	for _, key := range path {
+		switch v := current.(type) {
+		case bson.D:
+			found := false
+			for _, e := range v {
+				if e.Key == key {
+					current, found = e.Value, true
+					break
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		case bson.A:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			current = v[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// set writes value at path, creating intermediate documents when needed.
+func set(document bson.D, path []string, value interface{}) (bson.D, error) {
+	result, err := setValue(document, path, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(bson.D), nil
+}
+
+func setValue(current interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	switch v := current.(type) {
+	case bson.D:
+		for i, e := range v {
+			if e.Key == path[0] {
+				child, err := setValue(e.Value, path[1:], value)
+				if err != nil {
+					return nil, err
+				}
+				v[i].Value = child
+				return v, nil
+			}
+		}
+
+		child, err := setValue(bson.D{}, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		return append(v, bson.E{Key: path[0], Value: child}), nil
+	case bson.A:
+		index, err := strconv.Atoi(path[0])
+		if err != nil || index < 0 {
+			return nil, errors.Errorf("cannot create field %s in array", path[0])
+		}
+
+		for len(v) <= index {
+			v = append(v, nil)
+		}
+
+		child, err := setValue(v[index], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		v[index] = child
+		return v, nil
+	case nil:
+		return setValue(bson.D{}, path, value)
+	default:
+		return nil, errors.Errorf("cannot create field %s in element of type %T", path[0], current)
+	}
+}
+
+// unset removes the field at path, ignoring missing fields.
+func unset(document bson.D, path []string) bson.D {
+	if len(path) == 1 {
+		for i, e := range document {
+			if e.Key == path[0] {
+				return append(document[:i:i], document[i+1:]...)
+			}
+		}
+		return document
+	}
+
+	for i, e := range document {
+		if e.Key != path[0] {
+			continue
+		}
+
+		switch v := e.Value.(type) {
+		case bson.D:
+			document[i].Value = unset(v, path[1:])
+		case bson.A:
+			if index, err := strconv.Atoi(path[1]); err == nil && index >= 0 && index < len(v) {
+				if len(path) == 2 {
+					v[index] = nil
+				} else if child, ok := v[index].(bson.D); ok {
+					v[index] = unset(child, path[2:])
+				}
+			}
+		}
+	}
+
+	return document
+}
+
+// typeOrder follows the mongo BSON comparison order so values of different types sort consistently.
+func typeOrder(value interface{}) int {
+	switch value.(type) {
+	case nil, primitive.Null, primitive.Undefined:
+		return 1
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return 2
+	case string, primitive.Symbol:
+		return 3
+	case bson.D, bson.M:
+		return 4
+	case bson.A:
+		return 5
+	case primitive.Binary, []byte:
+		return 6
+	case primitive.ObjectID:
+		return 7
+	case bool:
+		return 8
+	case primitive.DateTime, time.Time:
+		return 9
+	case primitive.Timestamp:
+		return 10
+	case primitive.Regex:
+		return 11
+	default:
+		return 12
+	}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func toTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case primitive.DateTime:
+		return time.Unix(int64(v)/1000, int64(v)%1000*int64(time.Millisecond))
+	case time.Time:
+		return v
+	}
+	return time.Time{}
+}
+
+// compare orders two values, returning -1, 0 or 1.
+func compare(a, b interface{}) int {
+	ta, tb := typeOrder(a), typeOrder(b)
+	if ta != tb {
+		if ta < tb {
+			return -1
+		}
+		return 1
+	}
+
+	switch ta {
+	case 1:
+		return 0
+	case 2:
+		fa, _ := toFloat(a)
+		fb, _ := toFloat(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	case 3:
+		return strings.Compare(toString(a), toString(b))
+	case 4:
+		da, db := a.(bson.D), b.(bson.D)
+		for i := 0; i < len(da) && i < len(db); i++ {
+			if c := strings.Compare(da[i].Key, db[i].Key); c != 0 {
+				return c
+			}
+			if c := compare(da[i].Value, db[i].Value); c != 0 {
+				return c
+			}
+		}
+		return compareInt(len(da), len(db))
+	case 5:
+		aa, ab := a.(bson.A), b.(bson.A)
+		for i := 0; i < len(aa) && i < len(ab); i++ {
+			if c := compare(aa[i], ab[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInt(len(aa), len(ab))
+	case 6:
+		return bytes.Compare(toBytes(a), toBytes(b))
+	case 7:
+		ia, ib := a.(primitive.ObjectID), b.(primitive.ObjectID)
+		return bytes.Compare(ia[:], ib[:])
+	case 8:
+		ba, bb := a.(bool), b.(bool)
+		switch {
+		case ba == bb:
+			return 0
+		case !ba:
+			return -1
+		}
+		return 1
+	case 9:
+		ta, tb := toTime(a), toTime(b)
+		switch {
+		case ta.Before(tb):
+			return -1
+		case ta.After(tb):
+			return 1
+		}
+		return 0
+	case 10:
+		sa, sb := a.(primitive.Timestamp), b.(primitive.Timestamp)
+		if sa.T != sb.T {
+			return compareInt(int(sa.T), int(sb.T))
+		}
+		return compareInt(int(sa.I), int(sb.I))
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return 0
+	}
+	return -1
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func toString(value interface{}) string {
+	if symbol, ok := value.(primitive.Symbol); ok {
+		return string(symbol)
+	}
+	return value.(string)
+}
+
+func toBytes(value interface{}) []byte {
+	if binary, ok := value.(primitive.Binary); ok {
+		return binary.Data
+	}
+	return value.([]byte)
+}
+
+func equal(a, b interface{}) bool {
+	return typeOrder(a) == typeOrder(b) && compare(a, b) == 0
+}
+
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	}
+
+	if f, ok := toFloat(value); ok {
+		return f != 0
+	}
+
+	return true
+}
+
+func isNumber(value interface{}) bool {
+	_, ok := toFloat(value)
+	return ok
+}
+
+// arithmetic applies op keeping integer types when both operands are integers.
+func arithmetic(a, b interface{}, op func(x, y float64) float64) (interface{}, error) {
+	fa, ok := toFloat(a)
+	if !ok {
+		return nil, errors.Errorf("cannot apply arithmetic to non-numeric value %v", a)
+	}
+
+	fb, ok := toFloat(b)
+	if !ok {
+		return nil, errors.Errorf("cannot apply arithmetic with non-numeric argument %v", b)
+	}
+
+	result := op(fa, fb)
+
+	if isInteger(a) && isInteger(b) {
+		_, a32 := a.(int32)
+		_, b32 := b.(int32)
+		if a32 && b32 && result >= math.MinInt32 && result <= math.MaxInt32 {
+			return int32(result), nil
+		}
+		return int64(result), nil
+	}
+
+	return result, nil
+}
+
+func isInteger(value interface{}) bool {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,445 @@
+package mongo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DefaultCreatedAtField = "createdAt"
+	DefaultUpdatedAtField = "updatedAt"
+	DefaultDeletedAtField = "deletedAt"
+)
+
+type (
+	// DocumentHook configures the automatic document fields maintained by WithDocumentHook.
+	DocumentHook struct {
+		// Timestamps fills CreatedAtField on insert/upsert and UpdatedAtField on every write.
+		Timestamps bool
+		// SoftDelete turns Delete into setting DeletedAtField and hides deleted documents from finds,
+		// counts and updates.
+		SoftDelete bool
+
+		CreatedAtField string
+		UpdatedAtField string
+		DeletedAtField string
+
+		// Collections limits the hook to the given collections, empty applies it to every collection.
+		Collections []string
+	}
+
+	hooked struct {
+		Mongo
+		hook        DocumentHook
+		collections map[string]bool
+	}
+
+	hookContextKey string
+)
+
+const (
+	includeDeletedKey hookContextKey = "includeDeleted"
+	hardDeleteKey     hookContextKey = "hardDelete"
+)
+
+// WithDeleted returns a context that makes finds, counts and updates also see soft deleted documents.
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey, true)
+}
+
+// WithHardDelete returns a context that makes deletes remove documents even in soft delete mode.
+func WithHardDelete(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hardDeleteKey, true)
+}
+
+// WithDocumentHook wraps m so timestamps and soft delete are handled for the configured collections.
+// Bulk writes and aggregations are passed through untouched, an aggregation filters deleted
+// documents itself.
+func WithDocumentHook(m Mongo, hook *DocumentHook) (Mongo, error) {
+	if m == nil {
+		return nil, errors.New("mongo is required!")
+	}
+
+	if hook == nil {
+		return nil, errors.New("hook is required!")
+	}
+
+	h := *hook
+
+	if h.CreatedAtField == "" {
+		h.CreatedAtField = DefaultCreatedAtField
+	}
+
+	if h.UpdatedAtField == "" {
+		h.UpdatedAtField = DefaultUpdatedAtField
+	}
+
+	if h.DeletedAtField == "" {
+		h.DeletedAtField = DefaultDeletedAtField
+	}
+
+	collections := make(map[string]bool)
+	for _, collection := range h.Collections {
+		collections[collection] = true
+	}
+
+	return &hooked{Mongo: m, hook: h, collections: collections}, nil
+}
+
+func (h *hooked) enabled(collection string) bool {
+	return len(h.collections) == 0 || h.collections[collection]
+}
+
+func (h *hooked) softDelete(collection string) bool {
+	return h.hook.SoftDelete && h.enabled(collection)
+}
+
+func (h *hooked) timestamps(collection string) bool {
+	return h.hook.Timestamps && h.enabled(collection)
+}
+
+// visible restricts filter to documents that are not soft deleted.
+func (h *hooked) visible(ctx context.Context, collection string, filter interface{}) interface{} {
+	if !h.softDelete(collection) {
+		return filter
+	}
+
+	if include, _ := ctx.Value(includeDeletedKey).(bool); include {
+		return filter
+	}
+
+	notDeleted := bson.D{{Key: h.hook.DeletedAtField, Value: nil}}
+	if filter == nil {
+		return notDeleted
+	}
+
+	return bson.D{{Key: "$and", Value: bson.A{filter, notDeleted}}}
+}
+
+func (h *hooked) hardDelete(ctx context.Context, collection string) bool {
+	if !h.softDelete(collection) {
+		return true
+	}
+
+	hard, _ := ctx.Value(hardDeleteKey).(bool)
+	return hard
+}
+
+// stamp fills the timestamps of a document being inserted.
+func (h *hooked) stamp(collection string, object interface{}) (interface{}, error) {
+	if !h.timestamps(collection) {
+		return object, nil
+	}
+
+	document, err := toD(object)
+	if err != nil {
+		return nil, err
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+
+	if !hasTimestamp(document, h.hook.CreatedAtField) {
+		document = setField(document, h.hook.CreatedAtField, now)
+	}
+
+	return setField(document, h.hook.UpdatedAtField, now), nil
+}
+
+// stampUpdate adds updatedAt to $set and createdAt to $setOnInsert unless the update already sets them.
+func (h *hooked) stampUpdate(collection string, update interface{}) (interface{}, error) {
+	if !h.timestamps(collection) {
+		return update, nil
+	}
+
+	document, err := toD(update)
+	if err != nil {
+		return nil, err
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+
+	if len(document) == 0 || !strings.HasPrefix(document[0].Key, "$") {
+		// - replacement document
+		if !hasTimestamp(document, h.hook.CreatedAtField) {
+			document = setField(document, h.hook.CreatedAtField, now)
+		}
+		return setField(document, h.hook.UpdatedAtField, now), nil
+	}
+
+	if !updates(document, h.hook.UpdatedAtField) {
+		document = addOperatorField(document, "$set", h.hook.UpdatedAtField, now)
+	}
+
+	if !updates(document, h.hook.CreatedAtField) {
+		document = addOperatorField(document, "$setOnInsert", h.hook.CreatedAtField, now)
+	}
+
+	return document, nil
+}
+
+func (h *hooked) deletedUpdate() bson.D {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	set := bson.D{{Key: h.hook.DeletedAtField, Value: now}}
+
+	if h.hook.Timestamps {
+		set = append(set, bson.E{Key: h.hook.UpdatedAtField, Value: now})
+	}
+
+	return bson.D{{Key: "$set", Value: set}}
+}
+
+func toD(object interface{}) (bson.D, error) {
+	if object == nil {
+		return bson.D{}, nil
+	}
+
+	raw, err := bson.Marshal(object)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal document")
+	}
+
+	document := bson.D{}
+	if err := bson.Unmarshal(raw, &document); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal document")
+	}
+
+	return document, nil
+}
+
+func hasTimestamp(document bson.D, field string) bool {
+	for _, e := range document {
+		if e.Key != field {
+			continue
+		}
+
+		switch v := e.Value.(type) {
+		case nil:
+			return false
+		case primitive.DateTime:
+			// - zero time.Time, NewDateTimeFromTime overflows on it
+			return v != primitive.DateTime(time.Time{}.Unix()*1e3)
+		}
+		return true
+	}
+
+	return false
+}
+
+func setField(document bson.D, field string, value interface{}) bson.D {
+	for i, e := range document {
+		if e.Key == field {
+			document[i].Value = value
+			return document
+		}
+	}
+
+	return append(document, bson.E{Key: field, Value: value})
+}
+
+// updates reports whether any operator of the update document touches field.
+func updates(update bson.D, field string) bool {
+	for _, op := range update {
+		fields, ok := op.Value.(bson.D)
+		if !ok {
+			continue
+		}
+
+		for _, f := range fields {
+			if f.Key == field || strings.HasPrefix(f.Key, field+".") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func addOperatorField(update bson.D, operator, field string, value interface{}) bson.D {
+	for i, op := range update {
+		if op.Key != operator {
+			continue
+		}
+
+		fields, _ := op.Value.(bson.D)
+		update[i].Value = append(fields, bson.E{Key: field, Value: value})
+		return update
+	}
+
+	return append(update, bson.E{Key: operator, Value: bson.D{{Key: field, Value: value}}})
+}
+
+func (h *hooked) FindOneWithContext(ctx context.Context, collection string, filter, object interface{}, options ...*options.FindOneOptions) error {
+	return h.Mongo.FindOneWithContext(ctx, collection, h.visible(ctx, collection, filter), object, options...)
+}
+
+func (h *hooked) FindOne(collection string, filter interface{}, object interface{}, options ...*options.FindOneOptions) error {
+	return h.FindOneWithContext(context.Background(), collection, filter, object, options...)
+}
+
+func (h *hooked) FindAllWithContext(ctx context.Context, collection string, filter interface{}, results interface{}, options ...*options.FindOptions) error {
+	return h.Mongo.FindAllWithContext(ctx, collection, h.visible(ctx, collection, filter), results, options...)
+}
+
+func (h *hooked) FindAll(collection string, filter interface{}, results interface{}, options ...*options.FindOptions) error {
+	return h.FindAllWithContext(context.Background(), collection, filter, results, options...)
+}
+
+func (h *hooked) FindWithContext(ctx context.Context,
+	collection string, filter interface{}, callback FindCallback, options ...*options.FindOptions) error {
+	return h.Mongo.FindWithContext(ctx, collection, h.visible(ctx, collection, filter), callback, options...)
+}
+
+func (h *hooked) Find(collection string, filter interface{}, callback FindCallback, options ...*options.FindOptions) error {
+	return h.FindWithContext(context.Background(), collection, filter, callback, options...)
+}
+
+func (h *hooked) FindOneAndDeleteWithContext(ctx context.Context, collection string, filter interface{}, options ...*options.FindOneAndDeleteOptions) error {
+	if h.hardDelete(ctx, collection) {
+		return h.Mongo.FindOneAndDeleteWithContext(ctx, collection, h.visible(ctx, collection, filter), options...)
+	}
+
+	return h.Mongo.FindOneAndUpdateWithContext(ctx, collection, h.visible(ctx, collection, filter), h.deletedUpdate(), findOneAndDeleteAsUpdate(options...))
+}
+
+func (h *hooked) FindOneAndDelete(collection string, filter interface{}, options ...*options.FindOneAndDeleteOptions) error {
+	return h.FindOneAndDeleteWithContext(context.Background(), collection, filter, options...)
+}
+
+func findOneAndDeleteAsUpdate(opts ...*options.FindOneAndDeleteOptions) *options.FindOneAndUpdateOptions {
+	o := options.MergeFindOneAndDeleteOptions(opts...)
+	update := options.FindOneAndUpdate()
+
+	if o.Sort != nil {
+		update.SetSort(o.Sort)
+	}
+
+	if o.Projection != nil {
+		update.SetProjection(o.Projection)
+	}
+
+	if o.Collation != nil {
+		update.SetCollation(o.Collation)
+	}
+
+	if o.MaxTime != nil {
+		update.SetMaxTime(*o.MaxTime)
+	}
+
+	return update
+}
+
+func (h *hooked) FindOneAndUpdateWithContext(ctx context.Context, collection string, filter, object interface{}, options ...*options.FindOneAndUpdateOptions) error {
+	update, err := h.stampUpdate(collection, object)
+	if err != nil {
+		return errors.Wrap(err, "FindOneAndUpdateWithContext failed!")
+	}
+
+	return h.Mongo.FindOneAndUpdateWithContext(ctx, collection, h.visible(ctx, collection, filter), update, options...)
+}
+
+func (h *hooked) FindOneAndUpdate(collection string, filter, object interface{}, options ...*options.FindOneAndUpdateOptions) error {
+	return h.FindOneAndUpdateWithContext(context.Background(), collection, filter, object, options...)
+}
+
+func (h *hooked) InsertWithContext(ctx context.Context, collection string, object interface{}, options ...*options.InsertOneOptions) (*primitive.ObjectID, error) {
+	document, err := h.stamp(collection, object)
+	if err != nil {
+		return nil, errors.Wrap(err, "InsertOneWithContext failed!")
+	}
+
+	return h.Mongo.InsertWithContext(ctx, collection, document, options...)
+}
+
+func (h *hooked) Insert(collection string, object interface{}, options ...*options.InsertOneOptions) (*primitive.ObjectID, error) {
+	return h.InsertWithContext(context.Background(), collection, object, options...)
+}
+
+func (h *hooked) InsertManyWithContext(ctx context.Context, collection string, documents []interface{}, options ...*options.InsertManyOptions) ([]primitive.ObjectID, error) {
+	stamped := make([]interface{}, 0, len(documents))
+
+	for _, object := range documents {
+		document, err := h.stamp(collection, object)
+		if err != nil {
+			return nil, errors.Wrap(err, "InsertManyWithContext failed!")
+		}
+		stamped = append(stamped, document)
+	}
+
+	return h.Mongo.InsertManyWithContext(ctx, collection, stamped, options...)
+}
+
+func (h *hooked) InsertMany(collection string, documents []interface{}, options ...*options.InsertManyOptions) ([]primitive.ObjectID, error) {
+	return h.InsertManyWithContext(context.Background(), collection, documents, options...)
+}
+
+func (h *hooked) UpdateWithContext(ctx context.Context, collection string, filter, object interface{}, options ...*options.UpdateOptions) error {
+	update, err := h.stampUpdate(collection, object)
+	if err != nil {
+		return errors.Wrap(err, "UpdateWithContext failed!")
+	}
+
+	return h.Mongo.UpdateWithContext(ctx, collection, h.visible(ctx, collection, filter), update, options...)
+}
+
+func (h *hooked) Update(collection string, filter, object interface{}, options ...*options.UpdateOptions) error {
+	return h.UpdateWithContext(context.Background(), collection, filter, object, options...)
+}
+
+func (h *hooked) UpdateManyWithContext(ctx context.Context, collection string, filter, object interface{}, options ...*options.UpdateOptions) error {
+	update, err := h.stampUpdate(collection, object)
+	if err != nil {
+		return errors.Wrap(err, "UpdateManyWithContext failed!")
+	}
+
+	return h.Mongo.UpdateManyWithContext(ctx, collection, h.visible(ctx, collection, filter), update, options...)
+}
+
+func (h *hooked) UpdateMany(collection string, filter, object interface{}, options ...*options.UpdateOptions) error {
+	return h.UpdateManyWithContext(context.Background(), collection, filter, object, options...)
+}
+
+func (h *hooked) DeleteManyWithContext(ctx context.Context, collection string, filter interface{}, options ...*options.DeleteOptions) error {
+	if h.hardDelete(ctx, collection) {
+		return h.Mongo.DeleteManyWithContext(ctx, collection, filter, options...)
+	}
+
+	return h.Mongo.UpdateManyWithContext(ctx, collection, h.visible(ctx, collection, filter), h.deletedUpdate())
+}
+
+func (h *hooked) DeleteMany(collection string, filter interface{}, options ...*options.DeleteOptions) error {
+	return h.DeleteManyWithContext(context.Background(), collection, filter, options...)
+}
+
+func (h *hooked) DeleteWithContext(ctx context.Context, collection string, filter interface{}, options ...*options.DeleteOptions) error {
+	if h.hardDelete(ctx, collection) {
+		return h.Mongo.DeleteWithContext(ctx, collection, filter, options...)
+	}
+
+	return h.Mongo.UpdateWithContext(ctx, collection, h.visible(ctx, collection, filter), h.deletedUpdate())
+}
+
+func (h *hooked) Delete(collection string, filter interface{}, options ...*options.DeleteOptions) error {
+	return h.DeleteWithContext(context.Background(), collection, filter, options...)
+}
+
+func (h *hooked) CountWithFilterAndContext(ctx context.Context, collection string, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return h.Mongo.CountWithFilterAndContext(ctx, collection, h.visible(ctx, collection, filter), opts...)
+}
+
+func (h *hooked) CountWithFilter(collection string, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return h.CountWithFilterAndContext(context.Background(), collection, filter, opts...)
+}
+
+func (h *hooked) CountWithContext(ctx context.Context, collection string, opts ...*options.CountOptions) (int64, error) {
+	return h.CountWithFilterAndContext(ctx, collection, bson.D{}, opts...)
+}
+
+func (h *hooked) Count(collection string, opts ...*options.CountOptions) (int64, error) {
+	return h.CountWithContext(context.Background(), collection, opts...)
+}
@@ -0,0 +1,125 @@
+package mongo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/persistent/mongo"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/persistent/mongo/fake"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type (
+	voucher struct {
+		Code      string     `bson:"code"`
+		CreatedAt time.Time  `bson:"createdAt"`
+		UpdatedAt time.Time  `bson:"updatedAt"`
+		DeletedAt *time.Time `bson:"deletedAt"`
+	}
+)
+
+func newHooked(t *testing.T) mongo.Mongo {
+	db, err := mongo.WithDocumentHook(fake.New(), &mongo.DocumentHook{Timestamps: true, SoftDelete: true})
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+	return db
+}
+
+func Test_DocumentHook_Insert_fills_timestamps(t *testing.T) {
+	db := newHooked(t)
+
+	if _, err := db.Insert("vouchers", voucher{Code: "PAW"}); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	v := voucher{}
+	if err := db.FindOne("vouchers", bson.M{"code": "PAW"}, &v); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if v.CreatedAt.IsZero() || v.UpdatedAt.IsZero() {
+		t.Errorf("timestamps should be filled %+v", v)
+	}
+}
+
+func Test_DocumentHook_Update_keeps_createdAt(t *testing.T) {
+	db := newHooked(t)
+
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := db.Insert("vouchers", voucher{Code: "PAW", CreatedAt: created}); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if err := db.Update("vouchers", bson.M{"code": "PAW"}, bson.M{"$set": bson.M{"code": "MEOW"}}); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	v := voucher{}
+	if err := db.FindOne("vouchers", bson.M{"code": "MEOW"}, &v); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if !v.CreatedAt.Equal(created) || !v.UpdatedAt.After(created) {
+		t.Errorf("unexpected timestamps %+v", v)
+	}
+}
+
+func Test_DocumentHook_Delete_soft_deletes(t *testing.T) {
+	db := newHooked(t)
+
+	if _, err := db.InsertMany("vouchers", []interface{}{voucher{Code: "PAW"}, voucher{Code: "MEOW"}}); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if err := db.Delete("vouchers", bson.M{"code": "PAW"}); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if total, _ := db.Count("vouchers"); total != 1 {
+		t.Errorf("should hide soft deleted document, got %d", total)
+	}
+
+	v := voucher{}
+	if err := db.FindOneWithContext(mongo.WithDeleted(context.Background()), "vouchers", bson.M{"code": "PAW"}, &v); err != nil {
+		t.Fatalf("should find soft deleted document %s", err)
+	}
+
+	if v.DeletedAt == nil {
+		t.Error("deletedAt should be set")
+	}
+
+	if err := db.DeleteWithContext(mongo.WithHardDelete(context.Background()), "vouchers", bson.M{"code": "PAW"}); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if total, _ := db.CountWithContext(mongo.WithDeleted(context.Background()), "vouchers"); total != 1 {
+		t.Errorf("should hard delete document, got %d", total)
+	}
+}
+
+func Test_DocumentHook_Update_skips_soft_deleted(t *testing.T) {
+	db := newHooked(t)
+
+	if _, err := db.InsertMany("vouchers", []interface{}{voucher{Code: "PAW"}, voucher{Code: "MEOW"}}); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if err := db.Delete("vouchers", bson.M{"code": "PAW"}); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if err := db.UpdateMany("vouchers", bson.M{}, bson.M{"$set": bson.M{"code": "WOOF"}}); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	ctx := mongo.WithDeleted(context.Background())
+	if total, _ := db.CountWithFilterAndContext(ctx, "vouchers", bson.M{"code": "PAW"}); total != 1 {
+		t.Errorf("should not update soft deleted document, got %d", total)
+	}
+
+	if total, _ := db.CountWithFilter("vouchers", bson.M{"code": "WOOF"}); total != 1 {
+		t.Errorf("should update visible document, got %d", total)
+	}
+}
@@ -63,6 +63,12 @@ type (
 		BulkDocumentWithContext(context.Context, string, []mgo.WriteModel) error
 		BulkDocument(string, []mgo.WriteModel) error
 
+		BulkUpsertWithContext(ctx context.Context, collection string, keyFields []string, documents interface{}, option *BulkUpsertOption) (*BulkUpsertResult, error)
+		BulkUpsert(collection string, keyFields []string, documents interface{}, option *BulkUpsertOption) (*BulkUpsertResult, error)
+
+		ApplySchemaWithContext(ctx context.Context, collection string, document interface{}, option *SchemaOption) error
+		ApplySchema(collection string, document interface{}, option *SchemaOption) error
+
 		CountWithFilterAndContext(context.Context, string, interface{}, ...*options.CountOptions) (int64, error)
 		CountWithFilter(string, interface{}, ...*options.CountOptions) (int64, error)
 		CountWithContext(context.Context, string, ...*options.CountOptions) (int64, error)
@@ -77,6 +83,7 @@ type (
 
 	implementation struct {
 		client   *mgo.Client
+		name     string
 		database Database
 		logger   logs.Logger
 	}
@@ -134,7 +141,8 @@ func (i *implementation) AggregateWithContext(ctx context.Context,
 	}
 }
 
-func New(ctx context.Context, uri, name string, logger logs.Logger) (Mongo, error) {
+// New connects to the database name at uri, codecs are registered on top of the default registry, see NewRegistry.
+func New(ctx context.Context, uri, name string, logger logs.Logger, codecs ...Codec) (Mongo, error) {
 	if uri == "" {
 		return nil, errors.New("uri is required!")
 	}
@@ -149,11 +157,7 @@ func New(ctx context.Context, uri, name string, logger logs.Logger) (Mongo, erro
 
 	opts := options.Client().
 		ApplyURI(uri).
-		SetRegistry(
-			bson.NewRegistryBuilder().
-				RegisterDecoder(reflect.TypeOf(""), decoder{}).
-				Build(),
-		)
+		SetRegistry(NewRegistry(codecs...))
 
 	client, err := mgo.Connect(ctx, opts)
 
@@ -167,7 +171,7 @@ func New(ctx context.Context, uri, name string, logger logs.Logger) (Mongo, erro
 
 	database := NewDatabase(client.Database(name))
 
-	return &implementation{client, database, logger}, nil
+	return &implementation{client, name, database, logger}, nil
 }
 
 func (i *implementation) Ping() error {
@@ -449,6 +453,10 @@ func (c *cursorImplementation) Decode(val interface{}) error {
 	return c.cursor.Decode(val)
 }
 
+func (c *cursorImplementation) Err() error {
+	return c.cursor.Err()
+}
+
 type (
 	Database interface {
 		Collection(name string, opts ...*options.CollectionOptions) Collection
@@ -0,0 +1,416 @@
+package mongo
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mgo "go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	ValidationLevelOff      ValidationLevel = "off"
+	ValidationLevelStrict   ValidationLevel = "strict"
+	ValidationLevelModerate ValidationLevel = "moderate"
+
+	ValidationActionError ValidationAction = "error"
+	ValidationActionWarn  ValidationAction = "warn"
+
+	namespaceNotFound = 26
+)
+
+type (
+	// ValidationLevel decides which writes are validated, moderate skips updates to existing invalid documents.
+	ValidationLevel string
+
+	// ValidationAction decides whether an invalid write is rejected or only logged by the server.
+	ValidationAction string
+
+	// SchemaOption configures ApplySchema, zero values fall back to strict level and error action.
+	SchemaOption struct {
+		Level  ValidationLevel
+		Action ValidationAction
+	}
+)
+
+var (
+	tTime       = reflect.TypeOf(time.Time{})
+	tDateTime   = reflect.TypeOf(primitive.DateTime(0))
+	tDecimal128 = reflect.TypeOf(primitive.Decimal128{})
+	tBytes      = reflect.TypeOf([]byte(nil))
+	tD          = reflect.TypeOf(primitive.D{})
+	tRaw        = reflect.TypeOf(bson.Raw(nil))
+)
+
+func (i *implementation) ApplySchemaWithContext(ctx context.Context, collection string, document interface{}, option *SchemaOption) error {
+	command, err := SchemaCommand(collection, document, option)
+	if err != nil {
+		return err
+	}
+
+	err = i.client.Database(i.name).RunCommand(ctx, command).Err()
+	if e, ok := err.(mgo.CommandError); ok && e.Code == namespaceNotFound {
+		// - collMod only works on an existing collection
+		command[0].Key = "create"
+		err = i.client.Database(i.name).RunCommand(ctx, command).Err()
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to apply schema to collection %s!", collection)
+	}
+
+	return nil
+}
+
+func (i *implementation) ApplySchema(collection string, document interface{}, option *SchemaOption) error {
+	return i.ApplySchemaWithContext(context.Background(), collection, document, option)
+}
+
+// SchemaCommand returns the collMod command setting the $jsonSchema validator generated from document.
+func SchemaCommand(collection string, document interface{}, option *SchemaOption) (bson.D, error) {
+	schema, err := JSONSchema(document)
+	if err != nil {
+		return nil, err
+	}
+
+	opt := SchemaOption{}
+	if option != nil {
+		opt = *option
+	}
+
+	if opt.Level == "" {
+		opt.Level = ValidationLevelStrict
+	}
+
+	if opt.Action == "" {
+		opt.Action = ValidationActionError
+	}
+
+	return bson.D{
+		{Key: "collMod", Value: collection},
+		{Key: "validator", Value: bson.M{"$jsonSchema": schema}},
+		{Key: "validationLevel", Value: string(opt.Level)},
+		{Key: "validationAction", Value: string(opt.Action)},
+	}, nil
+}
+
+// JSONSchema generates a $jsonSchema from the struct document, field names follow the bson tags and
+// constraints follow the validator tags: required, min, max, len, gt, gte, lt, lte, oneof and dive.
+// Pointers, slices and maps also accept null as the driver encodes their nil value as null.
+func JSONSchema(document interface{}) (bson.M, error) {
+	t := reflect.TypeOf(document)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, errors.Errorf("document must be a struct, got %T", document)
+	}
+
+	return objectSchema(t, map[reflect.Type]bool{})
+}
+
+// objectSchema returns the schema of the struct t, visiting holds the structs being generated so a
+// recursive reference stops at a plain object.
+func objectSchema(t reflect.Type, visiting map[reflect.Type]bool) (bson.M, error) {
+	properties := bson.M{}
+	required := make([]string, 0)
+
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	if err := fieldSchemas(t, properties, &required, visiting); err != nil {
+		return nil, err
+	}
+
+	schema := bson.M{"bsonType": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema, nil
+}
+
+func fieldSchemas(t reflect.Type, properties bson.M, required *[]string, visiting map[reflect.Type]bool) error {
+	for n := 0; n < t.NumField(); n++ {
+		field := t.Field(n)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tags, err := bsoncodec.DefaultStructTagParser.ParseStructTags(field)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse tags of field %s", field.Name)
+		}
+
+		if tags.Skip {
+			continue
+		}
+
+		if tags.Inline {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() != reflect.Struct || visiting[ft] {
+				// - inline maps hold arbitrary fields
+				continue
+			}
+
+			if err := fieldSchemas(ft, properties, required, visiting); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rules := strings.Split(field.Tag.Get("validate"), ",")
+
+		schema, err := typeSchema(field.Type, rules, visiting)
+		if err != nil {
+			return errors.Wrapf(err, "field %s", field.Name)
+		}
+
+		properties[tags.Name] = schema
+
+		// - rules after dive apply to the elements, not the field
+		if contains(rules[:diveIndex(rules)], "required") {
+			*required = append(*required, tags.Name)
+		}
+	}
+
+	return nil
+}
+
+// typeSchema returns the schema of t constrained by the validator rules.
+func typeSchema(t reflect.Type, rules []string, visiting map[reflect.Type]bool) (bson.M, error) {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		nullable = true
+		t = t.Elem()
+	}
+
+	dive := diveIndex(rules)
+
+	schema := bson.M{}
+
+	switch {
+	case t == tTime || t == tTiketTime || t == tDateTime:
+		schema["bsonType"] = "date"
+	case t == tObjectID:
+		schema["bsonType"] = "objectId"
+	case t == tHex:
+		// - an empty hex is stored as null
+		schema["bsonType"] = "objectId"
+		nullable = true
+	case t == tDecimal || t == tDecimal128:
+		schema["bsonType"] = "decimal"
+	case t == tBytes:
+		schema["bsonType"] = "binData"
+	case t == tD || t == tRaw:
+		schema["bsonType"] = "object"
+	default:
+		switch t.Kind() {
+		case reflect.Bool:
+			schema["bsonType"] = "bool"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			schema["bsonType"] = bson.A{"int", "long"}
+		case reflect.Float32, reflect.Float64:
+			schema["bsonType"] = "number"
+		case reflect.String:
+			schema["bsonType"] = "string"
+		case reflect.Slice, reflect.Array:
+			items, err := typeSchema(t.Elem(), rules[minInt(dive+1, len(rules)):], visiting)
+			if err != nil {
+				return nil, err
+			}
+
+			schema["bsonType"] = "array"
+			if len(items) > 0 {
+				schema["items"] = items
+			}
+			nullable = nullable || t.Kind() == reflect.Slice
+		case reflect.Map:
+			schema["bsonType"] = "object"
+			nullable = true
+		case reflect.Struct:
+			if visiting[t] {
+				// - a recursive reference is only checked to be an object
+				schema["bsonType"] = "object"
+				break
+			}
+
+			object, err := objectSchema(t, visiting)
+			if err != nil {
+				return nil, err
+			}
+			schema = object
+		case reflect.Interface:
+			// - anything goes
+		default:
+			return nil, errors.Errorf("unsupported type %s", t)
+		}
+	}
+
+	if err := constrain(schema, t, rules[:dive]); err != nil {
+		return nil, err
+	}
+
+	if nullable {
+		if bsonType, ok := schema["bsonType"]; ok {
+			if types, ok := bsonType.(bson.A); ok {
+				schema["bsonType"] = append(types, "null")
+			} else {
+				schema["bsonType"] = bson.A{bsonType, "null"}
+			}
+		}
+	}
+
+	return schema, nil
+}
+
+// constrain applies the validator rules to schema, lengths for strings and arrays and bounds for numbers.
+func constrain(schema bson.M, t reflect.Type, rules []string) error {
+	for _, rule := range rules {
+		parts := strings.SplitN(rule, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		name, param := parts[0], parts[1]
+
+		switch kind := t.Kind(); {
+		case name == "oneof":
+			values := bson.A{}
+			for _, value := range strings.Fields(param) {
+				v, err := parseParam(kind, value)
+				if err != nil {
+					return errors.Wrapf(err, "invalid oneof value %s", value)
+				}
+				values = append(values, v)
+			}
+			schema["enum"] = values
+		case kind == reflect.String:
+			if err := bound(schema, name, param, "minLength", "maxLength"); err != nil {
+				return err
+			}
+		case kind == reflect.Slice || kind == reflect.Array:
+			if err := bound(schema, name, param, "minItems", "maxItems"); err != nil {
+				return err
+			}
+		case isNumber(kind):
+			limit, err := parseParam(kind, param)
+			if err != nil {
+				return errors.Wrapf(err, "invalid %s value %s", name, param)
+			}
+
+			switch name {
+			case "min", "gte":
+				schema["minimum"] = limit
+			case "max", "lte":
+				schema["maximum"] = limit
+			case "len", "eq":
+				schema["minimum"] = limit
+				schema["maximum"] = limit
+			case "gt":
+				schema["minimum"] = limit
+				schema["exclusiveMinimum"] = true
+			case "lt":
+				schema["maximum"] = limit
+				schema["exclusiveMaximum"] = true
+			}
+		}
+	}
+
+	return nil
+}
+
+// bound sets the length keywords of the rule name, rules that aren't about length are ignored.
+func bound(schema bson.M, name, param, min, max string) error {
+	switch name {
+	case "min", "gte", "max", "lte", "len", "gt", "lt":
+	default:
+		return nil
+	}
+
+	length, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid %s length %s", name, param)
+	}
+
+	switch name {
+	case "min", "gte":
+		schema[min] = length
+	case "max", "lte":
+		schema[max] = length
+	case "len":
+		schema[min] = length
+		schema[max] = length
+	case "gt":
+		schema[min] = length + 1
+	case "lt":
+		schema[max] = length - 1
+	}
+
+	return nil
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// parseParam parses a validator parameter as a value of the kind.
+func parseParam(kind reflect.Kind, param string) (interface{}, error) {
+	switch kind {
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(param, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseInt(param, 10, 64)
+	}
+
+	return param, nil
+}
+
+// diveIndex returns the index of the dive rule, len(rules) when there is none.
+func diveIndex(rules []string) int {
+	for n, rule := range rules {
+		if rule == "dive" {
+			return n
+		}
+	}
+
+	return len(rules)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,129 @@
+package mongo
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	room struct {
+		Name  string   `bson:"name" validate:"required,min=3"`
+		Beds  int      `bson:"beds" validate:"gte=1,lte=4"`
+		Price float64  `bson:"price" validate:"gt=0"`
+		Tags  []string `bson:"tags,omitempty" validate:"max=5,dive,oneof=smoking nonsmoking"`
+	}
+
+	audit struct {
+		CreatedBy string `bson:"createdBy" validate:"required"`
+	}
+
+	property struct {
+		ID       primitive.ObjectID `bson:"_id"`
+		Audit    audit              `bson:",inline"`
+		Rooms    []room             `bson:"rooms" validate:"required"`
+		Address  *string            `bson:"address"`
+		internal string
+		Ignored  string `bson:"-"`
+	}
+
+	category struct {
+		Name     string     `bson:"name" validate:"required"`
+		Parent   *category  `bson:"parent"`
+		Children []category `bson:"children"`
+	}
+
+	reservation struct {
+		PropertyID ObjectIDHex   `bson:"propertyId" validate:"required"`
+		RoomIDs    []ObjectIDHex `bson:"roomIds" validate:"dive,required"`
+	}
+)
+
+func Test_JSONSchema_follows_bson_and_validator_tags(t *testing.T) {
+	schema, err := JSONSchema(&property{})
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	properties := schema["properties"].(bson.M)
+	if len(properties) != 4 || properties["_id"].(bson.M)["bsonType"] != "objectId" {
+		t.Errorf("unexpected properties %+v", properties)
+	}
+
+	if !reflect.DeepEqual(schema["required"], []string{"createdBy", "rooms"}) {
+		t.Errorf("unexpected required %v", schema["required"])
+	}
+
+	if bsonType := properties["address"].(bson.M)["bsonType"]; !reflect.DeepEqual(bsonType, bson.A{"string", "null"}) {
+		t.Errorf("pointer should be nullable, got %v", bsonType)
+	}
+
+	rooms := properties["rooms"].(bson.M)["items"].(bson.M)
+	fields := rooms["properties"].(bson.M)
+
+	if fields["name"].(bson.M)["minLength"] != int64(3) || fields["beds"].(bson.M)["maximum"] != int64(4) {
+		t.Errorf("unexpected room schema %+v", fields)
+	}
+
+	if price := fields["price"].(bson.M); price["minimum"] != float64(0) || price["exclusiveMinimum"] != true {
+		t.Errorf("unexpected price schema %+v", price)
+	}
+
+	tags := fields["tags"].(bson.M)
+	if tags["maxItems"] != int64(5) || !reflect.DeepEqual(tags["items"].(bson.M)["enum"], bson.A{"smoking", "nonsmoking"}) {
+		t.Errorf("unexpected tags schema %+v", tags)
+	}
+}
+
+func Test_SchemaCommand_defaults_level_and_action(t *testing.T) {
+	command, err := SchemaCommand("properties", property{}, &SchemaOption{Action: ValidationActionWarn})
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if command.Map()["validationLevel"] != "strict" || command.Map()["validationAction"] != "warn" {
+		t.Errorf("unexpected command %+v", command)
+	}
+}
+
+func Test_JSONSchema_stops_at_recursive_types(t *testing.T) {
+	schema, err := JSONSchema(category{})
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	properties := schema["properties"].(bson.M)
+
+	if parent := properties["parent"].(bson.M); !reflect.DeepEqual(parent, bson.M{"bsonType": bson.A{"object", "null"}}) {
+		t.Errorf("unexpected parent schema %+v", parent)
+	}
+
+	children := properties["children"].(bson.M)
+	if items := children["items"]; !reflect.DeepEqual(items, bson.M{"bsonType": "object"}) {
+		t.Errorf("unexpected children schema %+v", children)
+	}
+}
+
+func Test_JSONSchema_maps_ObjectIDHex_to_nullable_objectId(t *testing.T) {
+	schema, err := JSONSchema(reservation{})
+	if err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	properties := schema["properties"].(bson.M)
+
+	if propertyID := properties["propertyId"].(bson.M); !reflect.DeepEqual(propertyID, bson.M{"bsonType": bson.A{"objectId", "null"}}) {
+		t.Errorf("unexpected propertyId schema %+v", propertyID)
+	}
+
+	roomIDs := properties["roomIds"].(bson.M)
+	if items := roomIDs["items"]; !reflect.DeepEqual(items, bson.M{"bsonType": bson.A{"objectId", "null"}}) {
+		t.Errorf("unexpected roomIds schema %+v", roomIDs)
+	}
+
+	if !reflect.DeepEqual(schema["required"], []string{"propertyId"}) {
+		t.Errorf("rules after dive should not require the slice, got %v", schema["required"])
+	}
+}
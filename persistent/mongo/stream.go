@@ -0,0 +1,154 @@
+package mongo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DefaultStreamWorkers    = 4
+	DefaultStreamBufferSize = 100
+)
+
+type (
+	// StreamOption configures the streaming functions, zero values fall back to the defaults.
+	StreamOption struct {
+		// Workers is the number of goroutines running the handler.
+		Workers int
+		// BufferSize is the number of decoded documents waiting for a worker before decoding blocks.
+		BufferSize int
+	}
+
+	// NewDocument returns a pointer the next document is decoded into, e.g. func() interface{} { return &Hotel{} }.
+	NewDocument func() interface{}
+
+	// StreamHandler processes a decoded document, it is called concurrently by the workers.
+	StreamHandler func(ctx context.Context, document interface{}) error
+)
+
+// FindStream decodes the documents matching filter and fans them out to the handler workers, see StreamCursor.
+func FindStream(ctx context.Context, m Mongo, collection string, filter interface{},
+	newDocument NewDocument, handler StreamHandler, option *StreamOption, opts ...*options.FindOptions) error {
+	return m.FindWithContext(ctx, collection, filter, func(cursor Cursor, err error) error {
+		if err != nil {
+			return err
+		}
+
+		return StreamCursor(ctx, cursor, newDocument, handler, option)
+	}, opts...)
+}
+
+// AggregateStream decodes the results of pipeline and fans them out to the handler workers, see StreamCursor.
+func AggregateStream(ctx context.Context, m Mongo, collection string, pipeline interface{},
+	newDocument NewDocument, handler StreamHandler, option *StreamOption, opts ...*options.AggregateOptions) error {
+	return m.AggregateWithContext(ctx, collection, pipeline, func(cursor Cursor, err error) error {
+		if err != nil {
+			return err
+		}
+
+		return StreamCursor(ctx, cursor, newDocument, handler, option)
+	}, opts...)
+}
+
+// StreamCursor decodes every document of cursor into a bounded channel consumed by option.Workers
+// goroutines running handler. The first decode, cursor or handler error cancels the stream and is
+// returned once every worker has stopped; cancelling ctx stops it as well. The cursor is not closed.
+func StreamCursor(ctx context.Context, cursor Cursor, newDocument NewDocument, handler StreamHandler, option *StreamOption) error {
+	if cursor == nil {
+		return errors.New("cursor is required!")
+	}
+
+	if newDocument == nil || handler == nil {
+		return errors.New("document constructor and handler are required!")
+	}
+
+	opt := StreamOption{}
+	if option != nil {
+		opt = *option
+	}
+
+	if opt.Workers <= 0 {
+		opt.Workers = DefaultStreamWorkers
+	}
+
+	if opt.BufferSize <= 0 {
+		opt.BufferSize = DefaultStreamBufferSize
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		once      sync.Once
+		failure   error
+		wg        sync.WaitGroup
+		documents = make(chan interface{}, opt.BufferSize)
+	)
+
+	fail := func(err error) {
+		once.Do(func() {
+			failure = err
+			cancel()
+		})
+	}
+
+	for n := 0; n < opt.Workers; n++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for document := range documents {
+				if ctx.Err() != nil {
+					// - drain so the producer never blocks on a cancelled stream
+					continue
+				}
+
+				if err := handler(ctx, document); err != nil {
+					fail(errors.Wrap(err, "failed to handle document"))
+				}
+			}
+		}()
+	}
+
+	produce(ctx, cursor, newDocument, documents, fail)
+	close(documents)
+
+	wg.Wait()
+
+	if failure != nil {
+		return failure
+	}
+
+	// - the parent context was cancelled
+	return ctx.Err()
+}
+
+func produce(ctx context.Context, cursor Cursor, newDocument NewDocument, documents chan<- interface{}, fail func(error)) {
+	for cursor.Next(ctx) {
+		document := newDocument()
+		if err := cursor.Decode(document); err != nil {
+			fail(errors.Wrap(err, "failed to decode document"))
+			return
+		}
+
+		select {
+		case documents <- document:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	// - Next also stops on a failed getMore, the cursors that keep the error report it through Err
+	c, ok := cursor.(interface{ Err() error })
+	if !ok {
+		return
+	}
+
+	if err := c.Err(); err != nil && ctx.Err() == nil {
+		fail(errors.Wrap(err, "cursor failed"))
+	}
+}
@@ -0,0 +1,141 @@
+package mongo_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/persistent/mongo"
+	"github.com/PAWSOME-INDONESIA/paw-utilities-go/persistent/mongo/fake"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type (
+	review struct {
+		Score int `bson:"score"`
+	}
+
+	// sliceCursor is a Cursor without Err, like the cursors written before StreamCursor existed.
+	sliceCursor struct {
+		scores []int
+		next   int
+	}
+
+	failingCursor struct {
+		sliceCursor
+		err error
+	}
+)
+
+func (c *sliceCursor) Next(context.Context) bool {
+	c.next++
+	return c.next <= len(c.scores)
+}
+
+func (c *sliceCursor) Close(context.Context) error {
+	return nil
+}
+
+func (c *sliceCursor) Decode(val interface{}) error {
+	val.(*review).Score = c.scores[c.next-1]
+	return nil
+}
+
+func (c *failingCursor) Err() error {
+	return c.err
+}
+
+func seedReviews(t *testing.T, total int) mongo.Mongo {
+	db := fake.New()
+
+	reviews := make([]interface{}, 0, total)
+	for n := 0; n < total; n++ {
+		reviews = append(reviews, review{Score: n % 5})
+	}
+
+	if _, err := db.InsertMany("reviews", reviews); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	return db
+}
+
+func newReview() interface{} {
+	return &review{}
+}
+
+func Test_FindStream_handles_every_document(t *testing.T) {
+	db := seedReviews(t, 500)
+
+	var total, sum int64
+	handler := func(ctx context.Context, document interface{}) error {
+		atomic.AddInt64(&total, 1)
+		atomic.AddInt64(&sum, int64(document.(*review).Score))
+		return nil
+	}
+
+	option := &mongo.StreamOption{Workers: 3, BufferSize: 10}
+	if err := mongo.FindStream(context.Background(), db, "reviews", bson.M{}, newReview, handler, option); err != nil {
+		t.Fatalf("should not error %s", err)
+	}
+
+	if total != 500 || sum != 1000 {
+		t.Errorf("should handle 500 reviews summing to 1000, got %d and %d", total, sum)
+	}
+}
+
+func Test_FindStream_returns_first_handler_error(t *testing.T) {
+	db := seedReviews(t, 500)
+	failure := errors.New("bad review")
+
+	var handled int64
+	handler := func(ctx context.Context, document interface{}) error {
+		if atomic.AddInt64(&handled, 1) == 10 {
+			return failure
+		}
+		return nil
+	}
+
+	err := mongo.FindStream(context.Background(), db, "reviews", bson.M{}, newReview, handler, &mongo.StreamOption{Workers: 2, BufferSize: 1})
+	if errors.Cause(err) != failure {
+		t.Errorf("should return handler error, got %v", err)
+	}
+
+	if handled >= 500 {
+		t.Errorf("should stop handling after the error, handled %d", handled)
+	}
+}
+
+func Test_AggregateStream_stops_on_cancelled_context(t *testing.T) {
+	db := seedReviews(t, 100)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	handler := func(ctx context.Context, document interface{}) error {
+		cancel()
+		return nil
+	}
+
+	pipeline := bson.A{bson.M{"$match": bson.M{"score": bson.M{"$gte": 0}}}}
+	if err := mongo.AggregateStream(ctx, db, "reviews", pipeline, newReview, handler, nil); err != context.Canceled {
+		t.Errorf("should return context.Canceled, got %v", err)
+	}
+}
+
+func Test_StreamCursor_checks_Err_only_when_the_cursor_has_it(t *testing.T) {
+	var sum int64
+	handler := func(ctx context.Context, document interface{}) error {
+		atomic.AddInt64(&sum, int64(document.(*review).Score))
+		return nil
+	}
+
+	cursor := &sliceCursor{scores: []int{1, 2, 3}}
+	if err := mongo.StreamCursor(context.Background(), cursor, newReview, handler, nil); err != nil || sum != 6 {
+		t.Errorf("should handle every document, got %d and %v", sum, err)
+	}
+
+	failing := &failingCursor{sliceCursor: sliceCursor{scores: []int{1}}, err: errors.New("getMore failed")}
+	if err := mongo.StreamCursor(context.Background(), failing, newReview, handler, nil); err == nil {
+		t.Errorf("should return the cursor error")
+	}
+}